@@ -0,0 +1,210 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	// adaptiveDecay is the weight of a new sample in the moving averages.
+	adaptiveDecay = 0.1
+	// adaptiveMinSamples is the number of samples an endpoint needs before
+	// its averages are used for routing.
+	adaptiveMinSamples = 5
+	// adaptiveMargin is how much better the backup must score to become
+	// the primary, so that endpoints of similar quality don't flap.
+	adaptiveMargin = 0.2
+	// defaultAdaptiveProbeInterval is Config.AdaptiveRoutingProbeSec when 0.
+	defaultAdaptiveProbeInterval = 10 * time.Second
+)
+
+// quality holds exponentially weighted moving averages of the latency and
+// error rate of the calls to an endpoint, and of the latency of each method
+// so that endpoints serving costlier methods aren't scored worse for it.
+type quality struct {
+	mu        sync.Mutex
+	samples   int
+	latencyMs float64 // of successful calls
+	errorRate float64
+	methods   map[string]*methodLatency
+}
+
+// methodLatency is the moving average of the successful calls to a method.
+type methodLatency struct {
+	samples int
+	ms      float64
+}
+
+func (l *methodLatency) observe(ms float64) {
+	l.samples++
+	if l.samples == 1 {
+		l.ms = ms
+	} else {
+		l.ms += adaptiveDecay * (ms - l.ms)
+	}
+}
+
+func (q *quality) observe(method string, took time.Duration, failed bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.samples++
+	e := 0.0
+	if failed {
+		e = 1
+	}
+	ms := float64(took) / float64(time.Millisecond)
+	if !failed {
+		if q.methods == nil {
+			q.methods = make(map[string]*methodLatency)
+		}
+		l := q.methods[method]
+		if l == nil {
+			l = &methodLatency{}
+			q.methods[method] = l
+		}
+		l.observe(ms)
+	}
+	if q.samples == 1 {
+		q.errorRate = e
+		if !failed {
+			q.latencyMs = ms
+		}
+		return
+	}
+	q.errorRate += adaptiveDecay * (e - q.errorRate)
+	if !failed {
+		if q.latencyMs == 0 {
+			q.latencyMs = ms
+		} else {
+			q.latencyMs += adaptiveDecay * (ms - q.latencyMs)
+		}
+	}
+}
+
+func (q *quality) averages() (latencyMs, errorRate float64, samples int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.latencyMs, q.errorRate, q.samples
+}
+
+// methodLatencies returns the latency averages of the methods with enough
+// samples, and the error rate.
+func (q *quality) methodLatencies() (map[string]float64, float64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	latencies := make(map[string]float64, len(q.methods))
+	for method, l := range q.methods {
+		if l.samples >= adaptiveMinSamples {
+			latencies[method] = l.ms
+		}
+	}
+	return latencies, q.errorRate
+}
+
+// scores estimates the cost of the same calls to both endpoints, the sum of
+// their latencies for the methods both have enough samples of, inflated by
+// the calls failing, lower is better. It is false without such methods.
+func scores(q, o *quality) (qScore, oScore float64, ok bool) {
+	qLatencies, qErrors := q.methodLatencies()
+	oLatencies, oErrors := o.methodLatencies()
+	for method, ms := range qLatencies {
+		if oms, found := oLatencies[method]; found {
+			qScore, oScore, ok = qScore+ms, oScore+oms, true
+		}
+	}
+	if !ok {
+		return 0, 0, false
+	}
+	return inflate(qScore, qErrors), inflate(oScore, oErrors), true
+}
+
+// inflate inflates latencyMs by errorRate.
+func inflate(latencyMs, errorRate float64) float64 {
+	if errorRate > 0.99 {
+		errorRate = 0.99
+	}
+	// at least a millisecond, so error rates still count for fast calls
+	if latencyMs < 1 {
+		latencyMs = 1
+	}
+	return latencyMs / (1 - errorRate)
+}
+
+// observeQuality feeds the outcome of a call attempt to the averages of e.
+// Caller cancellations and not found results say nothing of the endpoint.
+func (c *client) observeQuality(ctx context.Context, method string, e *endpoint, took time.Duration, err error) {
+	if err != nil && (ctx.Err() != nil || errors.Is(err, ethereum.NotFound)) {
+		return
+	}
+	e.quality.observe(method, took, err != nil)
+	if c.cfg.AdaptiveRouting {
+		c.adaptRoutes()
+	}
+}
+
+// adaptRoutes makes the backup endpoint the primary when it scores better
+// than the primary by adaptiveMargin.
+func (c *client) adaptRoutes() {
+	endpoints := c.endpoints()
+	primary, backup := endpoints[0], endpoints[1]
+	if backup.unavailable() {
+		return
+	}
+	p, b, ok := scores(&primary.quality, &backup.quality)
+	if !ok || b >= (1-adaptiveMargin)*p {
+		return
+	}
+	if !c.promoted.CompareAndSwap(primary == c.b, primary != c.b) {
+		return // adapted concurrently
+	}
+	c.metrics.ObservePromotion(backup.name)
+	c.observePreference()
+	c.logger.Info().Msgf("preferring rpc %s over %s, scoring %.1fms against %.1fms", backup.name, primary.name, b, p)
+}
+
+// observePreference sets the preferred endpoint gauge to the primary.
+func (c *client) observePreference() {
+	primary := c.endpoints()[0]
+	for _, e := range []*endpoint{c.m, c.b} {
+		c.metrics.SetPreferred(e.name, e == primary)
+	}
+}
+
+// probeQuality calls every endpoint each interval, so that they share a
+// method to be scored on and the averages of an endpoint don't go stale
+// while the other one gets the traffic.
+func (c *client) probeQuality(interval time.Duration) {
+	c.goBackground(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.done:
+				return
+			case <-ticker.C:
+				for _, e := range c.endpoints() {
+					if !e.unavailable() {
+						c.probeEndpoint(e, interval)
+					}
+				}
+			}
+		}
+	})
+}
+
+func (c *client) probeEndpoint(e *endpoint, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	t := time.Now()
+	err := e.call(ctx, func(ec *ethclient.Client) error {
+		_, err := ec.BlockNumber(ctx)
+		return err
+	})
+	c.metrics.Observe(promotionProbeMethod, t, e.name, err == nil)
+	c.observeQuality(ctx, promotionProbeMethod, e, time.Since(t), err)
+}
@@ -0,0 +1,110 @@
+package ethclient
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestQualityScores(t *testing.T) {
+	var q, o quality
+	for i := 0; i < adaptiveMinSamples; i++ {
+		q.observe("BlockNumber", 10*time.Millisecond, false)
+		o.observe("BalanceAt", 10*time.Millisecond, false)
+	}
+	if _, _, ok := scores(&q, &o); ok {
+		t.Fatal("scored without a method in common")
+	}
+	for i := 0; i < adaptiveMinSamples-1; i++ {
+		o.observe("BlockNumber", 20*time.Millisecond, false)
+	}
+	if _, _, ok := scores(&q, &o); ok {
+		t.Fatal("scored with too few samples")
+	}
+	o.observe("BlockNumber", 20*time.Millisecond, false)
+	if qs, os, ok := scores(&q, &o); !ok || qs != 10 || os != 20 {
+		t.Fatalf("scores = %v, %v, %v, want 10, 20", qs, os, ok)
+	}
+	q.observe("BlockNumber", time.Second, true)
+	if qs, _, _ := scores(&q, &o); qs <= 10 {
+		t.Fatalf("score = %v after an error, want above 10", qs)
+	}
+	if latencyMs, _, _ := q.averages(); latencyMs != 10 {
+		t.Fatalf("latency = %v, errors shouldn't count", latencyMs)
+	}
+}
+
+func TestAdaptiveRoutingIgnoresCallCosts(t *testing.T) {
+	c, err := New("test", "eth", testConfig(newTestNode(t, 1), newTestNode(t, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	cl := c.(*client)
+
+	// the endpoints are as fast, the primary serves the costly calls
+	for i := 0; i < adaptiveMinSamples; i++ {
+		cl.m.quality.observe("FilterLogs", 500*time.Millisecond, false)
+		cl.m.quality.observe(promotionProbeMethod, 10*time.Millisecond, false)
+		cl.b.quality.observe(promotionProbeMethod, 10*time.Millisecond, false)
+	}
+	cl.adaptRoutes()
+	if cl.endpoints()[0] != cl.m {
+		t.Fatal("backup preferred for serving cheaper calls")
+	}
+
+	// both serve them, the backup faster
+	for i := 0; i < adaptiveMinSamples; i++ {
+		cl.b.quality.observe("FilterLogs", 300*time.Millisecond, false)
+	}
+	cl.adaptRoutes()
+	if cl.endpoints()[0] != cl.b {
+		t.Fatal("faster backup not preferred")
+	}
+	// and now gets the costly calls alone, without the main taking over again
+	for i := 0; i < 10*adaptiveMinSamples; i++ {
+		cl.b.quality.observe("FilterLogs", 300*time.Millisecond, false)
+		cl.adaptRoutes()
+	}
+	if cl.endpoints()[0] != cl.b {
+		t.Fatal("routes flapped back to main")
+	}
+}
+
+func TestAdaptiveRouting(t *testing.T) {
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1, delay: 20 * time.Millisecond}),
+		newTestNodeWith(t, &testEthService{chainID: 1}),
+	)
+	cfg.AdaptiveRouting = true
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	cl := c.(*client)
+
+	for i := 0; i < adaptiveMinSamples; i++ {
+		cl.probeEndpoint(cl.m, time.Second)
+		cl.probeEndpoint(cl.b, time.Second)
+		if _, err := cl.BlockNumber(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	status := c.Status()
+	if len(status.Endpoints) != 2 || status.Endpoints[0].Name != "failover" || !status.Endpoints[0].Preferred {
+		t.Fatalf("status = %+v, want the faster failover preferred", status)
+	}
+	if main := status.Endpoints[1]; main.LatencyMs < 20 || main.Samples < adaptiveMinSamples {
+		t.Fatalf("status of main = %+v", main)
+	}
+}
+
+func TestAdaptiveRoutingExcludesPromotion(t *testing.T) {
+	cfg := testConfig("http://127.0.0.1:1", "http://127.0.0.1:2")
+	cfg.AdaptiveRouting = true
+	cfg.PromotionWindowSec = 60
+	if err := cfg.Valid(); err == nil {
+		t.Fatal("no error for both adaptive routing and promotion")
+	}
+}
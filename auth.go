@@ -0,0 +1,72 @@
+package ethclient
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// endpointAuth returns the dial options sending headers, the Authorization
+// header auth and, with a hex encoded 32 bytes jwtSecret, a JWT bearer token
+// (as for the engine API) issued for each request.
+func endpointAuth(headers map[string]string, auth, jwtSecret string) ([]rpc.ClientOption, error) {
+	var opts []rpc.ClientOption
+	if len(headers) > 0 || auth != "" {
+		h := http.Header{}
+		for k, v := range headers {
+			h.Set(k, v)
+		}
+		if auth != "" {
+			h.Set("Authorization", auth)
+		}
+		opts = append(opts, rpc.WithHeaders(h))
+	}
+	if jwtSecret != "" {
+		secret, err := parseJWTSecret(jwtSecret)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, rpc.WithHTTPAuth(jwtAuth(secret)))
+	}
+	return opts, nil
+}
+
+// parseJWTSecret decodes a hex encoded 32 bytes secret, with or without
+// the 0x prefix, as written by geth to its jwtsecret file.
+func parseJWTSecret(s string) ([32]byte, error) {
+	var secret [32]byte
+	if len(s) < 2 || s[:2] != "0x" {
+		s = "0x" + s
+	}
+	b, err := hexutil.Decode(s)
+	if err != nil || len(b) != len(secret) {
+		return secret, fmt.Errorf("not 32 hex encoded bytes")
+	}
+	copy(secret[:], b)
+	return secret, nil
+}
+
+// jwtAuth sets a HS256 token with the current time as its iat claim, the
+// only one the engine API requires, on every request.
+func jwtAuth(secret [32]byte) rpc.HTTPAuth {
+	return func(h http.Header) error {
+		h.Set("Authorization", "Bearer "+jwtToken(secret, time.Now()))
+		return nil
+	}
+}
+
+func jwtToken(secret [32]byte, now time.Time) string {
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." +
+		enc.EncodeToString([]byte(`{"iat":`+strconv.FormatInt(now.Unix(), 10)+`}`))
+	mac := hmac.New(sha256.New, secret[:])
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + enc.EncodeToString(mac.Sum(nil))
+}
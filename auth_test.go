@@ -0,0 +1,134 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newHeaderNode serves svc and records the headers of the last request.
+func newHeaderNode(t *testing.T, svc *testEthService) (string, func() http.Header) {
+	t.Helper()
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", svc); err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var last http.Header
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		last = r.Header.Clone()
+		mu.Unlock()
+		srv.ServeHTTP(w, r)
+	}))
+	t.Cleanup(func() {
+		hs.Close()
+		srv.Stop()
+	})
+	return hs.URL, func() http.Header {
+		mu.Lock()
+		defer mu.Unlock()
+		return last
+	}
+}
+
+func TestEndpointHeaders(t *testing.T) {
+	mainURL, mainHeaders := newHeaderNode(t, &testEthService{chainID: 1})
+	failoverURL, failoverHeaders := newHeaderNode(t, &testEthService{chainID: 1})
+	secret := strings.Repeat("ab", 32)
+	cfg := testConfig(mainURL, failoverURL)
+	cfg.RpcHeaders = map[string]string{"X-Api-Key": "main-key"}
+	cfg.RpcAuthHeader = "Bearer main-token"
+	cfg.FailoverRpcJwtSecret = "0x" + secret
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	h := mainHeaders()
+	if h.Get("X-Api-Key") != "main-key" || h.Get("Authorization") != "Bearer main-token" {
+		t.Fatalf("primary headers = %v", h)
+	}
+	h = failoverHeaders()
+	if h.Get("X-Api-Key") != "" {
+		t.Fatalf("primary headers sent to the failover: %v", h)
+	}
+	token := strings.TrimPrefix(h.Get("Authorization"), "Bearer ")
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("failover Authorization = %q, want a JWT", h.Get("Authorization"))
+	}
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var iat struct {
+		Iat int64 `json:"iat"`
+	}
+	if err := json.Unmarshal(claims, &iat); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(time.Unix(iat.Iat, 0)); d < -time.Second || d > time.Minute {
+		t.Fatalf("iat %d is %s away from now", iat.Iat, d)
+	}
+	s, _ := parseJWTSecret(secret)
+	if want := jwtToken(s, time.Unix(iat.Iat, 0)); token != want {
+		t.Fatalf("token = %s, want %s", token, want)
+	}
+}
+
+func TestInvalidJwtSecret(t *testing.T) {
+	cfg := testConfig("http://localhost:1", "http://localhost:2")
+	cfg.RpcJwtSecret = "0x1234"
+	if err := cfg.Valid(); err == nil {
+		t.Fatal("short jwt secret accepted")
+	}
+}
+
+// countingTransport counts the requests going through it.
+type countingTransport struct {
+	n atomic.Int64
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.n.Add(1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestEndpointHTTPClients(t *testing.T) {
+	var shared, failover countingTransport
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1}),
+		newTestNodeWith(t, &testEthService{chainID: 1}),
+	)
+	c, err := New("test", "eth", cfg,
+		WithHTTPClient(&http.Client{Transport: &shared}),
+		WithEndpointHTTPClients(nil, &http.Client{Transport: &failover}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// the chain id of both endpoints was verified
+	if shared.n.Load() == 0 || failover.n.Load() == 0 {
+		t.Fatalf("requests through the shared client %d, the failover one %d", shared.n.Load(), failover.n.Load())
+	}
+	before := failover.n.Load()
+	if _, err := c.(*client).BlockNumber(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if failover.n.Load() != before {
+		t.Fatal("primary call went through the failover http client")
+	}
+}
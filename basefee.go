@@ -0,0 +1,146 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// BlockBaseFee is the base fee of a block.
+type BlockBaseFee struct {
+	Number  uint64
+	Hash    common.Hash
+	BaseFee *big.Int
+}
+
+// baseFeeWindow keeps the base fees of the latest blocks seen by the head
+// watcher, in increasing block order.
+type baseFeeWindow struct {
+	size int
+
+	mu   sync.RWMutex
+	fees []BlockBaseFee
+}
+
+func newBaseFeeWindow(size int) *baseFeeWindow {
+	return &baseFeeWindow{size: size}
+}
+
+// add records the base fee of h. It reports false, forgetting all blocks,
+// if h follows the last block but isn't its child, which was reorged out.
+// Blocks are kept contiguous, the window starts over after a gap.
+func (w *baseFeeWindow) add(h *types.Header) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if n := len(w.fees); n > 0 {
+		last := w.fees[n-1]
+		switch {
+		case last.Number+1 != h.Number.Uint64():
+			w.fees = w.fees[:0]
+		case last.Hash != h.ParentHash:
+			w.fees = w.fees[:0]
+			return false
+		}
+	}
+	w.fees = append(w.fees, BlockBaseFee{Number: h.Number.Uint64(), Hash: h.Hash(), BaseFee: h.BaseFee})
+	if len(w.fees) > w.size {
+		w.fees = append(w.fees[:0], w.fees[len(w.fees)-w.size:]...)
+	}
+	return true
+}
+
+// at returns the base fee of block number, the latest one if number is
+// nil.
+func (w *baseFeeWindow) at(number *big.Int) (*big.Int, bool) {
+	if w == nil {
+		return nil, false
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if len(w.fees) == 0 {
+		return nil, false
+	}
+	if number == nil {
+		return copyBig(w.fees[len(w.fees)-1].BaseFee), true
+	}
+	if !number.IsUint64() {
+		return nil, false
+	}
+	first := w.fees[0].Number
+	if n := number.Uint64(); n >= first && n-first < uint64(len(w.fees)) && w.fees[n-first].Number == n {
+		return copyBig(w.fees[n-first].BaseFee), true
+	}
+	return nil, false
+}
+
+// next returns the first block to fetch to catch up with head.
+func (w *baseFeeWindow) next(head uint64) uint64 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	from := uint64(0)
+	if head >= uint64(w.size) {
+		from = head - uint64(w.size) + 1
+	}
+	if len(w.fees) > 0 && w.fees[len(w.fees)-1].Number+1 > from {
+		from = w.fees[len(w.fees)-1].Number + 1
+	}
+	return from
+}
+
+func (w *baseFeeWindow) snapshot() []BlockBaseFee {
+	if w == nil {
+		return nil
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	fees := make([]BlockBaseFee, len(w.fees))
+	for i, f := range w.fees {
+		fees[i] = BlockBaseFee{Number: f.Number, Hash: f.Hash, BaseFee: copyBig(f.BaseFee)}
+	}
+	return fees
+}
+
+// trackBaseFees fetches the headers of the blocks up to head the window
+// is missing, starting over once after a reorg.
+func (c *client) trackBaseFees(ctx context.Context, head uint64) {
+	for retry := true; ; retry = false {
+		n := c.baseFees.next(head)
+		for ; n <= head; n++ {
+			h, err := c.headerByNumber(ctx, new(big.Int).SetUint64(n))
+			if err != nil {
+				c.logger.Debug().Msgf("failed to get base fee of block %d: %s", n, err)
+				return
+			}
+			if !c.baseFees.add(h) {
+				c.logger.Debug().Msgf("block %d reorged, refetching base fees", n-1)
+				break
+			}
+		}
+		if n > head || !retry {
+			return
+		}
+	}
+}
+
+// BaseFeeAt returns the base fee of block number, the latest block if nil,
+// or nil before London. Recent blocks are served from the window kept by
+// the head watcher (Config.BaseFeeWindowBlocks) without any RPC call.
+func (c *client) BaseFeeAt(ctx context.Context, number *big.Int) (*big.Int, error) {
+	if fee, ok := c.baseFees.at(number); ok {
+		return fee, nil
+	}
+	h, err := c.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return h.BaseFee, nil
+}
+
+// RecentBaseFees returns the base fees of the latest blocks seen by the
+// head watcher, oldest first, or nil when Config.BaseFeeWindowBlocks is 0.
+func (c *client) RecentBaseFees() []BlockBaseFee {
+	return c.baseFees.snapshot()
+}
@@ -0,0 +1,111 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// testChain serves headers 0 to len(headers)-1 with base fees of 10 per
+// block number.
+type testChain struct {
+	headers []*types.Header
+	calls   *atomic.Int64
+}
+
+func newTestChain(n int) testChain {
+	c := testChain{calls: new(atomic.Int64)}
+	for i := 0; i < n; i++ {
+		h := &types.Header{Number: big.NewInt(int64(i)), Difficulty: big.NewInt(0), BaseFee: big.NewInt(int64(10 * i))}
+		if i > 0 {
+			h.ParentHash = c.headers[i-1].Hash()
+		}
+		c.headers = append(c.headers, h)
+	}
+	return c
+}
+
+func (c testChain) GetBlockByNumber(number string, _ bool) *types.Header {
+	c.calls.Add(1)
+	n, err := hexutil.DecodeUint64(number)
+	if err != nil || n >= uint64(len(c.headers)) {
+		return c.headers[len(c.headers)-1]
+	}
+	return c.headers[n]
+}
+
+func TestBaseFeeWindow(t *testing.T) {
+	chain := newTestChain(10)
+	w := newBaseFeeWindow(3)
+	for _, h := range chain.headers[2:6] {
+		if !w.add(h) {
+			t.Fatalf("block %d refused", h.Number)
+		}
+	}
+	if fees := w.snapshot(); len(fees) != 3 || fees[0].Number != 3 || fees[2].Number != 5 {
+		t.Fatalf("window = %+v, want blocks 3 to 5", fees)
+	}
+	if fee, ok := w.at(big.NewInt(4)); !ok || fee.Int64() != 40 {
+		t.Fatalf("base fee of block 4 = %v, %v", fee, ok)
+	}
+	if fee, ok := w.at(nil); !ok || fee.Int64() != 50 {
+		t.Fatalf("latest base fee = %v, %v", fee, ok)
+	}
+	if _, ok := w.at(big.NewInt(2)); ok {
+		t.Fatal("base fee of a block out of the window")
+	}
+	if n := w.next(9); n != 7 {
+		t.Fatalf("next = %d, want 7 for a head past the window", n)
+	}
+
+	reorged := *chain.headers[6]
+	reorged.ParentHash = chain.headers[1].Hash()
+	if w.add(&reorged) || len(w.snapshot()) != 0 {
+		t.Fatal("reorged block accepted")
+	}
+	w.add(chain.headers[6])
+	if !w.add(chain.headers[8]) || len(w.snapshot()) != 1 {
+		t.Fatalf("window after a gap = %+v, want block 8 only", w.snapshot())
+	}
+}
+
+func TestBaseFeeAt(t *testing.T) {
+	chain := newTestChain(6)
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 5}, chain),
+		newTestNode(t, 1),
+	)
+	cfg.HeadPollIntervalMs = 20
+	cfg.BaseFeeWindowBlocks = 4
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for len(c.RecentBaseFees()) < 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("recent base fees = %+v", c.RecentBaseFees())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	calls := chain.calls.Load()
+	for n := int64(2); n <= 5; n++ {
+		fee, err := c.BaseFeeAt(context.Background(), big.NewInt(n))
+		if err != nil || fee.Int64() != 10*n {
+			t.Fatalf("BaseFeeAt(%d) = %v, %v", n, fee, err)
+		}
+	}
+	if got := chain.calls.Load(); got != calls {
+		t.Fatalf("%d header calls for base fees in the window", got-calls)
+	}
+	if fee, err := c.BaseFeeAt(context.Background(), big.NewInt(1)); err != nil || fee.Int64() != 10 {
+		t.Fatalf("BaseFeeAt(1) = %v, %v", fee, err)
+	}
+}
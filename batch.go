@@ -0,0 +1,162 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const defaultBatchSize = 100
+
+// errBatchPartial is returned by a batch attempt in which some elements
+// failed, for them to be sent again to the next endpoint.
+var errBatchPartial = errors.New("batch elements failed")
+
+// BatchCall sends elems in batches of Config.BatchSize, with the retries
+// and failover of single calls. Like rpc.Client.BatchCallContext, the
+// errors of individual elements are set in their Error field, the returned
+// error is about the batch as a whole. Elements that failed on an endpoint
+// are sent again to the next one.
+func (c *client) BatchCall(ctx context.Context, elems []rpc.BatchElem) error {
+	size := c.batchSize()
+	for start := 0; start < len(elems); start += size {
+		end := start + size
+		if end > len(elems) {
+			end = len(elems)
+		}
+		if err := c.batchCall(ctx, elems[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// batchSize returns the size of batch requests from Config, or else the
+// smallest cap of the providers of the endpoints.
+func (c *client) batchSize() int {
+	if c.cfg.BatchSize > 0 {
+		return c.cfg.BatchSize
+	}
+	size := defaultBatchSize
+	for _, e := range c.endpoints() {
+		if e.preset != nil && e.preset.MaxBatchSize > 0 && e.preset.MaxBatchSize < size {
+			size = e.preset.MaxBatchSize
+		}
+	}
+	return size
+}
+
+func (c *client) batchCall(ctx context.Context, elems []rpc.BatchElem) error {
+	pending := make([]int, len(elems)) // indexes of the elements left to send
+	for i := range pending {
+		pending[i] = i
+	}
+	err := c.do(ctx, "BatchCall", func(ctx context.Context, _ *ethclient.Client) error {
+		batch := make([]rpc.BatchElem, len(pending))
+		for j, i := range pending {
+			batch[j] = elems[i]
+			batch[j].Error = nil
+		}
+		if err := rpcClientFromContext(ctx).BatchCallContext(ctx, batch); err != nil {
+			return err
+		}
+		var failed []int
+		for j, i := range pending {
+			if elems[i].Error = batch[j].Error; elems[i].Error != nil {
+				failed = append(failed, i)
+			}
+		}
+		pending = failed
+		if len(failed) > 0 {
+			return fmt.Errorf("%w: %d of %d", errBatchPartial, len(failed), len(batch))
+		}
+		return nil
+	})
+	if errors.Is(err, errBatchPartial) {
+		// reported in the elements
+		return nil
+	}
+	return err
+}
+
+// BatchBalanceAt returns the balances of accounts at blockNumber, the
+// latest block when nil, fetched with BatchCall. Balances that couldn't be
+// fetched are nil, and their errors joined in the returned error.
+func (c *client) BatchBalanceAt(ctx context.Context, accounts []common.Address, blockNumber *big.Int) ([]*big.Int, error) {
+	results := make([]hexutil.Big, len(accounts))
+	elems := make([]rpc.BatchElem, len(accounts))
+	for i, account := range accounts {
+		elems[i] = rpc.BatchElem{
+			Method: "eth_getBalance",
+			Args:   []interface{}{account, blockNumberArg(blockNumber)},
+			Result: &results[i],
+		}
+	}
+	if err := c.BatchCall(ctx, elems); err != nil {
+		return nil, err
+	}
+	balances := make([]*big.Int, len(accounts))
+	var errs []error
+	for i, elem := range elems {
+		if elem.Error != nil {
+			errs = append(errs, fmt.Errorf("balance of %s: %w", accounts[i], elem.Error))
+			continue
+		}
+		balances[i] = results[i].ToInt()
+	}
+	return balances, errors.Join(errs...)
+}
+
+// BatchTransactionReceipt returns the receipts of txHashes, fetched with
+// BatchCall. Receipts that couldn't be fetched are nil, and their errors,
+// ethereum.NotFound for unknown transactions, joined in the returned error.
+func (c *client) BatchTransactionReceipt(ctx context.Context, txHashes []common.Hash) ([]*types.Receipt, error) {
+	receipts := make([]*types.Receipt, len(txHashes))
+	elems := make([]rpc.BatchElem, len(txHashes))
+	for i, hash := range txHashes {
+		elems[i] = rpc.BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Args:   []interface{}{hash},
+			Result: &receipts[i],
+		}
+	}
+	if err := c.BatchCall(ctx, elems); err != nil {
+		return nil, err
+	}
+	var errs []error
+	for i, elem := range elems {
+		err := elem.Error
+		if err == nil && receipts[i] == nil {
+			err = ethereum.NotFound
+		}
+		if err != nil {
+			receipts[i] = nil
+			errs = append(errs, fmt.Errorf("receipt of %s: %w", txHashes[i], err))
+		}
+	}
+	return receipts, errors.Join(errs...)
+}
+
+// blockNumberArg encodes a block number argument like go-ethereum does,
+// where -1 is the pending block.
+func blockNumberArg(number *big.Int) string {
+	switch {
+	case number == nil:
+		return "latest"
+	case number.Cmp(big.NewInt(-1)) == 0:
+		return "pending"
+	case number.Cmp(big.NewInt(int64(rpc.FinalizedBlockNumber))) == 0:
+		return "finalized"
+	case number.Cmp(big.NewInt(int64(rpc.SafeBlockNumber))) == 0:
+		return "safe"
+	}
+	return hexutil.EncodeBig(number)
+}
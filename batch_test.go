@@ -0,0 +1,93 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// testFlakyBalances fails balance reads of the accounts in fail.
+type testFlakyBalances struct {
+	fail map[common.Address]bool
+}
+
+func (s testFlakyBalances) GetBalance(account common.Address, _ string) (*hexutil.Big, error) {
+	if s.fail[account] {
+		return nil, errors.New("internal error")
+	}
+	return (*hexutil.Big)(big.NewInt(1)), nil
+}
+
+func (testFlakyBalances) GetTransactionReceipt(_ common.Hash) *types.Receipt {
+	return nil
+}
+
+func TestBatchBalanceAt(t *testing.T) {
+	accounts := []common.Address{{1}, {2}, {3}, {4}, {5}}
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1}, testFlakyBalances{fail: map[common.Address]bool{{4}: true}}),
+		newTestNodeWith(t, &testEthService{chainID: 1, balance: 2}, testFlakyBalances{fail: map[common.Address]bool{{5}: true}}),
+	)
+	cfg.BatchSize = 2
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	balances, err := c.BatchBalanceAt(context.Background(), accounts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []int64{1, 1, 1, 1, 1} {
+		if balances[i] == nil || balances[i].Int64() != want {
+			t.Errorf("balance of %s = %v, want %d", accounts[i], balances[i], want)
+		}
+	}
+}
+
+func TestBatchPartialFailure(t *testing.T) {
+	failing := map[common.Address]bool{{2}: true}
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1}, testFlakyBalances{fail: failing}),
+		newTestNodeWith(t, &testEthService{chainID: 1}, testFlakyBalances{fail: failing}),
+	)
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	balances, err := c.BatchBalanceAt(context.Background(), []common.Address{{1}, {2}}, big.NewInt(5))
+	if err == nil || balances[0] == nil || balances[1] != nil {
+		t.Fatalf("BatchBalanceAt = %v, %v, want the second balance to fail", balances, err)
+	}
+
+	receipts, err := c.BatchTransactionReceipt(context.Background(), []common.Hash{{1}})
+	if !errors.Is(err, ethereum.NotFound) || receipts[0] != nil {
+		t.Fatalf("BatchTransactionReceipt = %v, %v, want NotFound", receipts, err)
+	}
+}
+
+func TestBlockNumberArg(t *testing.T) {
+	for _, tt := range []struct {
+		number *big.Int
+		want   string
+	}{
+		{nil, "latest"},
+		{big.NewInt(-1), "pending"},
+		{big.NewInt(-3), "finalized"},
+		{big.NewInt(-4), "safe"},
+		{big.NewInt(16), "0x10"},
+	} {
+		if got := blockNumberArg(tt.number); got != tt.want {
+			t.Errorf("blockNumberArg(%v) = %s, want %s", tt.number, got, tt.want)
+		}
+	}
+}
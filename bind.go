@@ -0,0 +1,30 @@
+package ethclient
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// BlockHashContractCaller mirrors the interface of the same name of newer
+// go-ethereum bind packages, for bindings reading contract state at a block
+// hash. It isn't part of the go-ethereum version this module builds with.
+type BlockHashContractCaller interface {
+	CodeAtHash(ctx context.Context, contract common.Address, blockHash common.Hash) ([]byte, error)
+	CallContractAtHash(ctx context.Context, call ethereum.CallMsg, blockHash common.Hash) ([]byte, error)
+}
+
+// CodeAtHash returns the code of contract at the block with blockHash.
+func (c *client) CodeAtHash(ctx context.Context, contract common.Address, blockHash common.Hash) ([]byte, error) {
+	return do(ctx, c, "CodeAtHash", func(ctx context.Context, _ *ethclient.Client) ([]byte, error) {
+		var code hexutil.Bytes
+		if err := rpcClientFromContext(ctx).CallContext(ctx, &code, "eth_getCode", contract, rpc.BlockNumberOrHashWithHash(blockHash, false)); err != nil {
+			return nil, err
+		}
+		return code, nil
+	})
+}
@@ -0,0 +1,21 @@
+package ethclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestCodeAtHash(t *testing.T) {
+	cfg := testConfig(newTestNodeWith(t, &testEthService{chainID: 1}, &testCodeService{}), newTestNode(t, 1))
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	code, err := c.CodeAtHash(context.Background(), common.Address{}, common.HexToHash("0x01"))
+	if err != nil || len(code) != 2 {
+		t.Fatalf("CodeAtHash = %x, %v", code, err)
+	}
+}
@@ -0,0 +1,99 @@
+package ethclient
+
+import (
+	"container/list"
+	"context"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// responseCache is an LRU cache of immutable responses, with an optional
+// TTL.
+type responseCache struct {
+	size int
+	ttl  time.Duration
+
+	mu    sync.Mutex
+	ll    *list.List // most recently used first
+	items map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key     string
+	value   any
+	expires time.Time // zero without a TTL
+}
+
+func newResponseCache(size int, ttl time.Duration) *responseCache {
+	return &responseCache{size: size, ttl: ttl, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (c *responseCache) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*cacheEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		c.ll.Remove(el)
+		delete(c.items, key)
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+func (c *responseCache) add(key string, value any) {
+	var expires time.Time
+	if c.ttl > 0 {
+		expires = time.Now().Add(c.ttl)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value = &cacheEntry{key: key, value: value, expires: expires}
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&cacheEntry{key: key, value: value, expires: expires})
+	for c.ll.Len() > c.size {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// cached serves the call of method identified by key from the cache, or
+// else calls fetch and caches its result if cacheable (always when nil).
+// Cached values are shared between callers. Cacheable results also back
+// the failsafe cache when fetch fails.
+func cached[T any](ctx context.Context, c *client, method, key string, fetch func() (T, error), cacheable func(T) bool) (T, error) {
+	if c.cache != nil {
+		if v, ok := c.cache.get(key); ok {
+			c.metrics.ObserveCache(method, true)
+			return v.(T), nil
+		}
+		c.metrics.ObserveCache(method, false)
+	}
+	r, err := fetch()
+	if err != nil {
+		return failsafe[T](ctx, c, method, key, err)
+	}
+	if cacheable == nil || cacheable(r) {
+		if c.cache != nil {
+			c.cache.add(key, r)
+		}
+		c.remember(key, r)
+	}
+	return r, nil
+}
+
+// finalized reports whether block is deep enough below the head watcher's
+// head to be considered final. Nothing is final while the head is unknown.
+func (c *client) finalized(block *big.Int) bool {
+	head := c.ApproxHead()
+	return block != nil && block.IsUint64() && head > 0 && block.Uint64()+c.cfg.CacheFinalityDepth <= head
+}
@@ -0,0 +1,102 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestResponseCacheEviction(t *testing.T) {
+	c := newResponseCache(2, 0)
+	c.add("a", 1)
+	c.add("b", 2)
+	c.get("a")
+	c.add("c", 3) // evicts b, the least recently used
+	if _, ok := c.get("b"); ok {
+		t.Fatal("least recently used entry not evicted")
+	}
+	for _, k := range []string{"a", "c"} {
+		if _, ok := c.get(k); !ok {
+			t.Fatalf("entry %s evicted", k)
+		}
+	}
+}
+
+func TestResponseCacheTTL(t *testing.T) {
+	c := newResponseCache(10, 10*time.Millisecond)
+	c.add("a", 1)
+	if _, ok := c.get("a"); !ok {
+		t.Fatal("entry missing")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := c.get("a"); ok {
+		t.Fatal("entry did not expire")
+	}
+}
+
+type testCodeService struct {
+	calls atomic.Int64
+}
+
+func (s *testCodeService) GetCode(_ common.Address, _ rpc.BlockNumberOrHash) hexutil.Bytes {
+	s.calls.Add(1)
+	return hexutil.Bytes{0x60, 0x80}
+}
+
+func TestCachedCodeAt(t *testing.T) {
+	code := &testCodeService{}
+	reg := prometheus.NewRegistry()
+	cfg := testConfig(newTestNodeWith(t, &testEthService{chainID: 1}, code), newTestNode(t, 1))
+	cfg.EnablePrometheus = true
+	cfg.PrometheusRegisterer = reg
+	cfg.CacheSize = 10
+	cfg.CacheFinalityDepth = 64
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.(*client).head.maxAge = time.Minute
+	c.(*client).head.observe(1000, time.Now())
+	ctx := context.Background()
+
+	for _, block := range []*big.Int{big.NewInt(10), big.NewInt(10), nil, nil, big.NewInt(990), big.NewInt(990)} {
+		r, err := c.CodeAt(ctx, common.Address{}, block)
+		if err != nil || len(r) != 2 {
+			t.Fatalf("CodeAt(%v) = %x, %v", block, r, err)
+		}
+		r[0] = 0 // callers may modify the result
+	}
+	// once at block 10, and every time at the unfinalized blocks
+	if n := code.calls.Load(); n != 5 {
+		t.Fatalf("GetCode called %d times, want 5", n)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hits := map[string]float64{}
+	for _, f := range families {
+		if f.GetName() != "rpc_cache_requests_total" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == labelResult {
+					hits[l.GetValue()] += m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	if hits["hit"] != 1 || hits["miss"] != 1 {
+		t.Fatalf("cache lookups = %v, want 1 hit and 1 miss", hits)
+	}
+}
@@ -0,0 +1,40 @@
+package ethclient
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// logCall logs an attempt of method on e that took d, if it's slower than
+// LogSlowCallMs or sampled by LogCallSampleRate or LogErrorSampleRate.
+func (c *client) logCall(method string, e *endpoint, attempt int, failover bool, d time.Duration, err error) {
+	slow := c.cfg.LogSlowCallMs > 0 && d >= time.Duration(c.cfg.LogSlowCallMs)*time.Millisecond
+	rate := c.cfg.LogCallSampleRate
+	if err != nil {
+		rate = c.cfg.LogErrorSampleRate
+	}
+	if !slow && (rate <= 0 || rand.Float64() >= rate) {
+		return
+	}
+	var ev *zerolog.Event
+	switch {
+	case err != nil:
+		ev = c.logger.Warn().Err(err)
+	case slow:
+		ev = c.logger.Warn()
+	default:
+		ev = c.logger.Info()
+	}
+	ev = ev.Str("method", method).
+		Str("endpoint", e.name).
+		Int("attempt", attempt).
+		Bool("failover", failover).
+		Dur("duration", d)
+	if slow {
+		ev.Msg("slow rpc call")
+		return
+	}
+	ev.Msg("rpc call")
+}
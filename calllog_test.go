@@ -0,0 +1,124 @@
+package ethclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// syncBuffer is a buffer safe for concurrent writes.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// lines returns the logged lines whose message is msg.
+func (b *syncBuffer) lines(t *testing.T, msg string) []map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var lines []map[string]interface{}
+	for _, raw := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		var line map[string]interface{}
+		if err := json.Unmarshal([]byte(raw), &line); err != nil {
+			t.Fatal(err)
+		}
+		if line["message"] == msg {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestLogCall(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		cfg    Config
+		d      time.Duration
+		err    error
+		level  string
+		logged bool
+	}{
+		{"disabled", Config{}, time.Second, nil, "", false},
+		{"sampled", Config{LogCallSampleRate: 1}, time.Millisecond, nil, "info", true},
+		{"error not sampled", Config{LogCallSampleRate: 1}, time.Millisecond, errors.New("boom"), "", false},
+		{"error sampled", Config{LogErrorSampleRate: 1}, time.Millisecond, errors.New("boom"), "warn", true},
+		{"fast", Config{LogSlowCallMs: 100}, time.Millisecond, nil, "", false},
+		{"slow", Config{LogSlowCallMs: 100}, time.Second, nil, "warn", true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf syncBuffer
+			logger := zerolog.New(&buf)
+			c := &client{cfg: &tt.cfg, logger: &logger}
+			c.logCall("BlockNumber", &endpoint{name: "main"}, 2, true, tt.d, tt.err)
+			if !tt.logged {
+				if buf.buf.Len() > 0 {
+					t.Fatalf("logged %s", buf.buf.String())
+				}
+				return
+			}
+			var line map[string]interface{}
+			if err := json.Unmarshal(buf.buf.Bytes(), &line); err != nil {
+				t.Fatal(err)
+			}
+			if line["level"] != tt.level || line["method"] != "BlockNumber" || line["endpoint"] != "main" || line["attempt"] != 2.0 || line["failover"] != true || line["duration"] != float64(tt.d.Milliseconds()) {
+				t.Fatalf("logged %v", line)
+			}
+			if tt.err != nil && line["error"] != tt.err.Error() {
+				t.Fatalf("logged error %v, want %v", line["error"], tt.err)
+			}
+		})
+	}
+}
+
+func TestCallLogging(t *testing.T) {
+	var buf syncBuffer
+	logger := zerolog.New(&buf)
+	var down atomic.Bool
+	down.Store(true)
+	cfg := testConfig(newOutageNode(t, &testEthService{chainID: 1}, &down), newTestNode(t, 1))
+	cfg.VerifyChainID = false
+	cfg.LogCallSampleRate, cfg.LogErrorSampleRate = 1, 1
+	c, err := New("test", "eth", cfg, WithLogger(&logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.(*client).BlockNumber(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	var failed, failedOver int
+	for _, line := range buf.lines(t, "rpc call") {
+		if line["error"] != nil && line["endpoint"] == "main" {
+			failed++
+		}
+		if line["error"] == nil && line["failover"] == true {
+			failedOver++
+		}
+	}
+	if failed == 0 || failedOver == 0 {
+		t.Fatalf("logged %d failed attempts and %d failovers, want both", failed, failedOver)
+	}
+}
@@ -0,0 +1,220 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+const (
+	// canonicalHistory is the number of blocks kept below the confirmed
+	// ones to find where a reorg forked.
+	canonicalHistory = 128
+	// maxCanonicalBackfill bounds the blocks fetched to connect a head to
+	// the known chain, beyond it the chain starts over from the head.
+	maxCanonicalBackfill = 1024
+	// canonicalResubscribeDelay is the delay between attempts to subscribe
+	// again once the head subscription failed.
+	canonicalResubscribeDelay = time.Second
+)
+
+// HeadEvent is a change of the canonical chain: a new block or, with
+// Removed set, a block orphaned by a reorg.
+type HeadEvent struct {
+	Header  *types.Header
+	Removed bool
+}
+
+// canonicalChain holds the latest canonical headers, in increasing block
+// order without gaps.
+type canonicalChain struct {
+	headers       []*types.Header
+	confirmations uint64
+	emitted       uint64 // highest block sent, if sent
+	sent          bool
+}
+
+func (c *canonicalChain) tip() *types.Header {
+	if len(c.headers) == 0 {
+		return nil
+	}
+	return c.headers[len(c.headers)-1]
+}
+
+// at returns the header of block number, if known.
+func (c *canonicalChain) at(number uint64) *types.Header {
+	if len(c.headers) == 0 {
+		return nil
+	}
+	first := c.headers[0].Number.Uint64()
+	if number < first || number-first >= uint64(len(c.headers)) {
+		return nil
+	}
+	return c.headers[number-first]
+}
+
+// update links head to the chain, fetching the blocks in between with
+// parent, and returns the events it makes due, removals first.
+func (c *canonicalChain) update(head *types.Header, parent func(*types.Header) (*types.Header, error)) ([]HeadEvent, error) {
+	if known := c.at(head.Number.Uint64()); known != nil && known.Hash() == head.Hash() {
+		return nil, nil
+	}
+	branch := []*types.Header{head}
+	var fork *types.Header // last known block kept
+	for len(c.headers) > 0 {
+		low := branch[0]
+		n := low.Number.Uint64()
+		if n == 0 {
+			break
+		}
+		if known := c.at(n - 1); known != nil && known.Hash() == low.ParentHash {
+			fork = known
+			break
+		}
+		if n-1 < c.headers[0].Number.Uint64() || len(branch) > maxCanonicalBackfill {
+			// forked below the blocks kept, or too far ahead to backfill
+			break
+		}
+		p, err := parent(low)
+		if err != nil {
+			return nil, err
+		}
+		branch = append([]*types.Header{p}, branch...)
+	}
+
+	var events []HeadEvent
+	if fork == nil {
+		// start over from the branch, the blocks it replaces are orphaned
+		low := branch[0].Number.Uint64()
+		for i := len(c.headers) - 1; i >= 0; i-- {
+			if n := c.headers[i].Number.Uint64(); n >= low && c.sent && n <= c.emitted {
+				events = append(events, HeadEvent{Header: c.headers[i], Removed: true})
+			}
+		}
+		if c.sent && c.emitted >= low {
+			c.emitted, c.sent = low-1, low > 0
+		}
+		c.headers = branch
+	} else {
+		keep := fork.Number.Uint64() - c.headers[0].Number.Uint64() + 1
+		for i := len(c.headers) - 1; i >= int(keep); i-- {
+			if h := c.headers[i]; c.sent && h.Number.Uint64() <= c.emitted {
+				events = append(events, HeadEvent{Header: h, Removed: true})
+			}
+		}
+		c.headers = append(c.headers[:keep], branch...)
+		if c.sent && c.emitted > fork.Number.Uint64() {
+			c.emitted = fork.Number.Uint64()
+		}
+	}
+
+	tip := c.tip().Number.Uint64()
+	for _, h := range c.headers {
+		n := h.Number.Uint64()
+		if (c.sent && n <= c.emitted) || n+c.confirmations > tip {
+			continue
+		}
+		events = append(events, HeadEvent{Header: h})
+		c.emitted, c.sent = n, true
+	}
+	if max := int(c.confirmations) + canonicalHistory; len(c.headers) > max {
+		c.headers = append(c.headers[:0], c.headers[len(c.headers)-max:]...)
+	}
+	return events, nil
+}
+
+// SubscribeCanonicalHeads sends the blocks of the canonical chain to ch in
+// order, once confirmations blocks are mined on top of them. It follows the
+// new heads of the endpoints, subscribing again after a failure, possibly
+// to the other endpoint, and fetches the blocks missed meanwhile. When a
+// reorg orphans blocks already sent, they are sent again with Removed set,
+// highest first, before the blocks of the new branch.
+func (c *client) SubscribeCanonicalHeads(ctx context.Context, ch chan<- HeadEvent, confirmations uint64) (ethereum.Subscription, error) {
+	heads := make(chan *types.Header, 16)
+	sub, err := c.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		return nil, err
+	}
+	// ctx only bounds the setup, see trackedSubscription
+	lctx := c.subscriptionContext(ctx)
+	chain := &canonicalChain{confirmations: confirmations}
+	parent := func(h *types.Header) (*types.Header, error) {
+		return c.HeaderByHash(lctx, h.ParentHash)
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer func() { sub.Unsubscribe() }()
+		// send links h to the chain and sends the resulting events, it is
+		// false once the subscription is done
+		send := func(h *types.Header) bool {
+			events, err := chain.update(h, parent)
+			if err != nil {
+				c.logger.Warn().Msgf("failed to link block %d to the canonical chain: %s", h.Number, err)
+				return true
+			}
+			for _, ev := range events {
+				select {
+				case ch <- ev:
+				case <-quit:
+					return false
+				case <-c.done:
+					return false
+				}
+			}
+			return true
+		}
+		for {
+			select {
+			case <-quit:
+				return nil
+			case <-c.done:
+				return nil
+			case err, ok := <-sub.Err():
+				if !ok {
+					err = errors.New("subscription closed")
+				}
+				c.logger.Warn().Msgf("new head subscription failed, subscribing again: %s", err)
+				if sub = c.resubscribeHeads(lctx, heads, quit); sub == nil {
+					return lctx.Err()
+				}
+				// catch up with the blocks missed meanwhile
+				h, err := c.HeaderByNumber(lctx, nil)
+				if err != nil {
+					c.logger.Warn().Msgf("failed to get head after subscribing again: %s", err)
+					continue
+				}
+				if !send(h) {
+					return nil
+				}
+			case h := <-heads:
+				if !send(h) {
+					return nil
+				}
+			}
+		}
+	}), nil
+}
+
+// resubscribeHeads subscribes to new heads until it succeeds, or returns
+// nil once quit, ctx or the client is done.
+func (c *client) resubscribeHeads(ctx context.Context, heads chan *types.Header, quit <-chan struct{}) ethereum.Subscription {
+	for {
+		sub, err := c.SubscribeNewHead(ctx, heads)
+		if err == nil {
+			return sub
+		}
+		c.logger.Debug().Msgf("failed to subscribe to new heads: %s", err)
+		select {
+		case <-time.After(canonicalResubscribeDelay):
+		case <-quit:
+			return nil
+		case <-ctx.Done():
+			return nil
+		case <-c.done:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testBranch builds headers from..to on top of parent, tagged with fork to
+// tell branches apart.
+func testBranch(parent *types.Header, from, to int64, fork uint64) []*types.Header {
+	var headers []*types.Header
+	for n := from; n <= to; n++ {
+		h := &types.Header{Number: big.NewInt(n), Difficulty: big.NewInt(0), Time: fork}
+		if parent != nil {
+			h.ParentHash = parent.Hash()
+		}
+		headers = append(headers, h)
+		parent = h
+	}
+	return headers
+}
+
+func describeEvents(events []HeadEvent) string {
+	s := ""
+	for _, ev := range events {
+		if ev.Removed {
+			s += "-"
+		}
+		s += fmt.Sprintf("%d/%d ", ev.Header.Number, ev.Header.Time)
+	}
+	return s
+}
+
+func TestCanonicalChainUpdate(t *testing.T) {
+	byHash := make(map[common.Hash]*types.Header)
+	parent := func(h *types.Header) (*types.Header, error) {
+		if p, ok := byHash[h.ParentHash]; ok {
+			return p, nil
+		}
+		return nil, fmt.Errorf("unknown block %s", h.ParentHash)
+	}
+	a := testBranch(nil, 0, 6, 0)
+	b := testBranch(a[3], 4, 7, 1)
+	for _, h := range append(append([]*types.Header{}, a...), b...) {
+		byHash[h.Hash()] = h
+	}
+
+	for _, tt := range []struct {
+		name          string
+		confirmations uint64
+		heads         []*types.Header
+		want          string
+	}{
+		{"in order", 0, a[:3], "0/0 1/0 2/0 "},
+		{"duplicate head", 0, []*types.Header{a[0], a[1], a[1]}, "0/0 1/0 "},
+		{"backfill", 0, []*types.Header{a[0], a[3]}, "0/0 1/0 2/0 3/0 "},
+		{"reorg", 0, []*types.Header{a[0], a[5], b[1]}, "0/0 1/0 2/0 3/0 4/0 5/0 -5/0 -4/0 4/1 5/1 "},
+		{"confirmations", 2, []*types.Header{a[0], a[2], a[3]}, "0/0 1/0 "},
+		{"reorg of unconfirmed blocks", 2, []*types.Header{a[0], a[5], b[2]}, "0/0 1/0 2/0 3/0 4/1 "},
+	} {
+		chain := &canonicalChain{confirmations: tt.confirmations}
+		var events []HeadEvent
+		for _, h := range tt.heads {
+			evs, err := chain.update(h, parent)
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			events = append(events, evs...)
+		}
+		if got := describeEvents(events); got != tt.want {
+			t.Errorf("%s: events %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// testHeadFeed notifies new heads subscribers of the headers sent to heads
+// and serves them by hash.
+type testHeadFeed struct {
+	heads chan *types.Header
+
+	mu     sync.Mutex
+	byHash map[common.Hash]*types.Header
+}
+
+func (f *testHeadFeed) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	go func() {
+		for {
+			select {
+			case h := <-f.heads:
+				f.mu.Lock()
+				f.byHash[h.Hash()] = h
+				f.mu.Unlock()
+				notifier.Notify(sub.ID, h)
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+func (f *testHeadFeed) GetBlockByHash(hash common.Hash, _ bool) *types.Header {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.byHash[hash]
+}
+
+func TestSubscribeCanonicalHeads(t *testing.T) {
+	feed := &testHeadFeed{heads: make(chan *types.Header), byHash: make(map[common.Hash]*types.Header)}
+	srv := rpc.NewServer()
+	for _, rcvr := range []interface{}{&testEthService{chainID: 1}, feed} {
+		if err := srv.RegisterName("eth", rcvr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer srv.Stop()
+	c, err := New("test", "eth", testConfig("", ""), WithRPCClients(
+		rpc.DialInProc(srv),
+		newInProcClient(t, &testEthService{chainID: 1}),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ch := make(chan HeadEvent, 16)
+	sub, err := c.SubscribeCanonicalHeads(context.Background(), ch, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	a := testBranch(nil, 0, 3, 0)
+	b := testBranch(a[1], 2, 3, 1)
+	// the feed learns the orphaned blocks, and serves them, as it sends them
+	feed.mu.Lock()
+	for _, h := range a {
+		feed.byHash[h.Hash()] = h
+	}
+	feed.mu.Unlock()
+	for _, h := range []*types.Header{a[0], a[1], a[2], b[0], b[1]} {
+		feed.heads <- h
+	}
+	want := "0/0 1/0 2/0 -2/0 2/1 3/1 "
+	var events []HeadEvent
+	timeout := time.After(5 * time.Second)
+	for describeEvents(events) != want {
+		select {
+		case ev := <-ch:
+			events = append(events, ev)
+		case err := <-sub.Err():
+			t.Fatal(err)
+		case <-timeout:
+			t.Fatalf("events %q, want %q", describeEvents(events), want)
+		}
+	}
+}
+
+func TestSubscribeCanonicalHeadsAfterSetupContext(t *testing.T) {
+	feed := &testHeadFeed{heads: make(chan *types.Header), byHash: make(map[common.Hash]*types.Header)}
+	srv := rpc.NewServer()
+	for _, rcvr := range []interface{}{&testEthService{chainID: 1}, feed} {
+		if err := srv.RegisterName("eth", rcvr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer srv.Stop()
+	c, err := New("test", "eth", testConfig("", ""), WithRPCClients(
+		rpc.DialInProc(srv),
+		newInProcClient(t, &testEthService{chainID: 1}),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan HeadEvent, 16)
+	sub, err := c.SubscribeCanonicalHeads(ctx, ch, 0)
+	cancel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	// the missed block is fetched although the setup context is done
+	a := testBranch(nil, 0, 2, 0)
+	feed.mu.Lock()
+	feed.byHash[a[1].Hash()] = a[1]
+	feed.mu.Unlock()
+	feed.heads <- a[0]
+	feed.heads <- a[2]
+	want := "0/0 1/0 2/0 "
+	var events []HeadEvent
+	timeout := time.After(5 * time.Second)
+	for describeEvents(events) != want {
+		select {
+		case ev := <-ch:
+			events = append(events, ev)
+		case err := <-sub.Err():
+			t.Fatal(err)
+		case <-timeout:
+			t.Fatalf("events %q, want %q", describeEvents(events), want)
+		}
+	}
+}
+
+func TestSubscriptionsUseWebsocketEndpoints(t *testing.T) {
+	feed := &testHeadFeed{heads: make(chan *types.Header), byHash: make(map[common.Hash]*types.Header)}
+	cfg := testConfig(newTestNode(t, 1), newTestNode(t, 1))
+	cfg.WsUrl = "ws://127.0.0.1:1"
+	cfg.FailoverWsUrl = newTestWSNode(t, &testEthService{chainID: 1}, feed)
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// the http endpoints don't support subscriptions, the main websocket
+	// endpoint is down
+	ch := make(chan *types.Header)
+	sub, err := c.SubscribeNewHead(context.Background(), ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	if ws := c.(*client).ws; len(ws) != 2 || ws[1].subs.Load() != 1 {
+		t.Fatal("subscription not served by the failover websocket endpoint")
+	}
+	h := testBranch(nil, 1, 1, 0)[0]
+	feed.heads <- h
+	select {
+	case got := <-ch:
+		if got.Hash() != h.Hash() {
+			t.Fatalf("got head %s, want %s", got.Hash(), h.Hash())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no head received")
+	}
+}
@@ -0,0 +1,76 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+)
+
+// callGroup tracks the calls in flight by key, for identical concurrent
+// calls to share one upstream call.
+type callGroup struct {
+	mu    sync.Mutex
+	calls map[string]*groupCall
+}
+
+type groupCall struct {
+	done chan struct{}
+	val  any
+	err  error
+}
+
+// coalesce runs fn for the call of method identified by key, unless an
+// identical call is already in flight, in which case its result is shared.
+// A caller whose shared call was canceled by the caller that made it, or
+// whose call is steered by routing hints, runs fn on its own.
+func coalesce[T any](ctx context.Context, c *client, method, key string, fn func(context.Context) (T, error)) (T, error) {
+	g := c.calls
+	if g == nil || hasRoutingHint(ctx) {
+		return fn(ctx)
+	}
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.metrics.ObserveCoalesced(method)
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+		if ctx.Err() == nil && (errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded)) {
+			return fn(ctx)
+		}
+		r, _ := call.val.(T)
+		return r, call.err
+	}
+	call := &groupCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	r, err := fn(ctx)
+	call.val, call.err = r, err
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(call.done)
+	return r, err
+}
+
+// blockKey identifies a block number argument in a coalescing key.
+func blockKey(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	return number.String()
+}
+
+// copyBig keeps callers sharing a coalesced result from modifying each
+// other's.
+func copyBig(x *big.Int) *big.Int {
+	if x == nil {
+		return nil
+	}
+	return new(big.Int).Set(x)
+}
@@ -0,0 +1,79 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+type testSlowGasPrice struct {
+	calls atomic.Int64
+}
+
+func (s *testSlowGasPrice) GasPrice() *hexutil.Big {
+	s.calls.Add(1)
+	time.Sleep(100 * time.Millisecond)
+	return (*hexutil.Big)(big.NewInt(30))
+}
+
+func TestCoalesceCalls(t *testing.T) {
+	svc := &testSlowGasPrice{}
+	cfg := testConfig(newTestNodeWith(t, &testEthService{chainID: 1}, svc), newTestNode(t, 1))
+	cfg.CoalesceCalls = true
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	prices := make([]*big.Int, 10)
+	for i := range prices {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p, err := c.SuggestGasPrice(context.Background())
+			if err != nil {
+				t.Error(err)
+			}
+			prices[i] = p
+		}(i)
+	}
+	wg.Wait()
+	if n := svc.calls.Load(); n != 1 {
+		t.Fatalf("upstream called %d times, want once", n)
+	}
+	prices[0].SetInt64(0)
+	for _, p := range prices[1:] {
+		if p.Int64() != 30 {
+			t.Fatalf("gas price = %s, want 30 unaffected by other callers", p)
+		}
+	}
+}
+
+func TestCoalesceCanceledLeader(t *testing.T) {
+	c := &client{calls: &callGroup{calls: make(map[string]*groupCall)}}
+	started := make(chan struct{})
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	go coalesce(leaderCtx, c, "BlockNumber", "BlockNumber", func(ctx context.Context) (uint64, error) {
+		close(started)
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	<-started
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	n, err := coalesce(context.Background(), c, "BlockNumber", "BlockNumber", func(ctx context.Context) (uint64, error) {
+		return 7, nil
+	})
+	if err != nil || n != 7 {
+		t.Fatalf("follower of a canceled call = %d, %v, want its own result", n, err)
+	}
+}
@@ -0,0 +1,214 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxFeeHistoryBlocks caps the blocks of a synthesized fee history, like
+// geth caps eth_feeHistory.
+const maxFeeHistoryBlocks = 1024
+
+// lacks reports whether err shows that the endpoint e lacks rpcMethod, the
+// call behind method. It is then remembered for a while, for the calls of
+// method to go straight to a shim on e.
+func (c *client) lacks(e *endpoint, method, rpcMethod string, err error) bool {
+	if !isMethodNotFound(err) {
+		return false
+	}
+	c.logger.Info().Msgf("rpc %s doesn't support %s, emulating %s for %s", e.name, rpcMethod, method, capabilityRecheckInterval)
+	e.markUnsupported(method)
+	return true
+}
+
+// suggestGasTipCap returns the tip suggested by eth_maxPriorityFeePerGas,
+// or on endpoints lacking it, the gas price less the base fee of the
+// latest block.
+func (c *client) suggestGasTipCap(ctx context.Context, ec *ethclient.Client) (*big.Int, error) {
+	e := endpointFromContext(ctx)
+	if e.supports("SuggestGasTipCap") {
+		tip, err := ec.SuggestGasTipCap(ctx)
+		if !c.lacks(e, "SuggestGasTipCap", "eth_maxPriorityFeePerGas", err) {
+			return tip, err
+		}
+	}
+	gasPrice, err := ec.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	head, err := ec.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if head.BaseFee == nil {
+		// before London the whole gas price goes to the miner
+		return gasPrice, nil
+	}
+	tip := new(big.Int).Sub(gasPrice, head.BaseFee)
+	if tip.Sign() < 0 {
+		tip.SetInt64(0)
+	}
+	return tip, nil
+}
+
+// feeHistory returns the fee history of eth_feeHistory, or on endpoints
+// lacking it, one synthesized from the blocks.
+func (c *client) feeHistory(ctx context.Context, ec *ethclient.Client, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	e := endpointFromContext(ctx)
+	if e.supports("FeeHistory") {
+		r, err := ec.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+		if !c.lacks(e, "FeeHistory", "eth_feeHistory", err) {
+			return r, err
+		}
+	}
+	return synthesizeFeeHistory(ctx, rpcClientFromContext(ctx), ec, blockCount, lastBlock, rewardPercentiles)
+}
+
+// synthesizeFeeHistory computes a fee history from the blocks read from rc
+// in a batch. Rewards are the effective tips at the percentiles of the
+// gas limits of the transactions, an approximation of the gas they used
+// which would take their receipts.
+func synthesizeFeeHistory(ctx context.Context, rc *rpc.Client, ec *ethclient.Client, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	if lastBlock == nil || lastBlock.Sign() < 0 {
+		head, err := ec.HeaderByNumber(ctx, lastBlock)
+		if err != nil {
+			return nil, err
+		}
+		lastBlock = head.Number
+	}
+	last := lastBlock.Uint64()
+	if blockCount > last+1 {
+		blockCount = last + 1
+	}
+	if blockCount > maxFeeHistoryBlocks {
+		blockCount = maxFeeHistoryBlocks
+	}
+	if blockCount == 0 {
+		return &ethereum.FeeHistory{OldestBlock: new(big.Int).Set(lastBlock)}, nil
+	}
+	oldest := last + 1 - blockCount
+	raw := make([]json.RawMessage, blockCount)
+	elems := make([]rpc.BatchElem, blockCount)
+	for i := range elems {
+		elems[i] = rpc.BatchElem{
+			Method: "eth_getBlockByNumber",
+			Args:   []interface{}{rpc.BlockNumber(oldest + uint64(i)), len(rewardPercentiles) > 0},
+			Result: &raw[i],
+		}
+	}
+	if err := rc.BatchCallContext(ctx, elems); err != nil {
+		return nil, err
+	}
+	r := &ethereum.FeeHistory{OldestBlock: new(big.Int).SetUint64(oldest)}
+	var head *types.Header
+	for i, elem := range elems {
+		if elem.Error != nil {
+			return nil, elem.Error
+		}
+		if len(raw[i]) == 0 || string(raw[i]) == "null" {
+			return nil, fmt.Errorf("block %d: %w", oldest+uint64(i), ethereum.NotFound)
+		}
+		head = new(types.Header)
+		if err := json.Unmarshal(raw[i], head); err != nil {
+			return nil, err
+		}
+		baseFee := head.BaseFee
+		if baseFee == nil {
+			baseFee = new(big.Int)
+		}
+		r.BaseFee = append(r.BaseFee, baseFee)
+		ratio := 0.0
+		if head.GasLimit > 0 {
+			ratio = float64(head.GasUsed) / float64(head.GasLimit)
+		}
+		r.GasUsedRatio = append(r.GasUsedRatio, ratio)
+		if len(rewardPercentiles) > 0 {
+			var body struct {
+				Transactions []*types.Transaction `json:"transactions"`
+			}
+			if err := json.Unmarshal(raw[i], &body); err != nil {
+				return nil, err
+			}
+			r.Reward = append(r.Reward, rewards(body.Transactions, baseFee, rewardPercentiles))
+		}
+	}
+	r.BaseFee = append(r.BaseFee, nextBaseFee(head))
+	return r, nil
+}
+
+// rewards returns the effective tips of txs at percentiles, weighted by
+// the gas limits of txs.
+func rewards(txs []*types.Transaction, baseFee *big.Int, percentiles []float64) []*big.Int {
+	r := make([]*big.Int, len(percentiles))
+	if len(txs) == 0 {
+		for i := range r {
+			r[i] = new(big.Int)
+		}
+		return r
+	}
+	type txTip struct {
+		tip *big.Int
+		gas uint64
+	}
+	tips := make([]txTip, len(txs))
+	var total uint64
+	for i, tx := range txs {
+		tip, err := tx.EffectiveGasTip(baseFee)
+		if err != nil {
+			tip = new(big.Int)
+		}
+		tips[i] = txTip{tip, tx.Gas()}
+		total += tx.Gas()
+	}
+	sort.Slice(tips, func(i, j int) bool { return tips[i].tip.Cmp(tips[j].tip) < 0 })
+	var j int
+	sum := tips[0].gas
+	for i, p := range percentiles {
+		threshold := uint64(float64(total) * p / 100)
+		for sum < threshold && j < len(tips)-1 {
+			j++
+			sum += tips[j].gas
+		}
+		r[i] = tips[j].tip
+	}
+	return r
+}
+
+// nextBaseFee returns the base fee of the block after h, per EIP-1559.
+func nextBaseFee(h *types.Header) *big.Int {
+	if h.BaseFee == nil {
+		return new(big.Int)
+	}
+	target := h.GasLimit / params.DefaultElasticityMultiplier
+	if target == 0 || h.GasUsed == target {
+		return new(big.Int).Set(h.BaseFee)
+	}
+	if h.GasUsed > target {
+		delta := new(big.Int).SetUint64(h.GasUsed - target)
+		delta.Mul(delta, h.BaseFee)
+		delta.Div(delta, new(big.Int).SetUint64(target))
+		delta.Div(delta, big.NewInt(params.DefaultBaseFeeChangeDenominator))
+		if delta.Sign() == 0 {
+			delta.SetInt64(1)
+		}
+		return delta.Add(delta, h.BaseFee)
+	}
+	delta := new(big.Int).SetUint64(target - h.GasUsed)
+	delta.Mul(delta, h.BaseFee)
+	delta.Div(delta, new(big.Int).SetUint64(target))
+	delta.Div(delta, big.NewInt(params.DefaultBaseFeeChangeDenominator))
+	next := delta.Sub(h.BaseFee, delta)
+	if next.Sign() < 0 {
+		next.SetInt64(0)
+	}
+	return next
+}
@@ -0,0 +1,111 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testLegacyNode serves blocks 0 to 2 with a base fee of 10 and one
+// transaction tipping 1 (block 1) or 3 (block 2), but neither
+// eth_maxPriorityFeePerGas nor eth_feeHistory.
+type testLegacyNode struct {
+	blocks []map[string]interface{}
+}
+
+func newTestLegacyNode(t *testing.T) *testLegacyNode {
+	key, _ := crypto.GenerateKey()
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	n := &testLegacyNode{}
+	for i := int64(0); i < 3; i++ {
+		h := &types.Header{Number: big.NewInt(i), Difficulty: big.NewInt(0), GasLimit: 100, GasUsed: 50 + 25*uint64(i), BaseFee: big.NewInt(10)}
+		var txs []*types.Transaction
+		if i > 0 {
+			txs = append(txs, types.MustSignNewTx(key, signer, &types.DynamicFeeTx{ChainID: big.NewInt(1), Gas: 21000, GasFeeCap: big.NewInt(100), GasTipCap: big.NewInt(2*i - 1)}))
+		}
+		raw, err := json.Marshal(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var block map[string]interface{}
+		if err := json.Unmarshal(raw, &block); err != nil {
+			t.Fatal(err)
+		}
+		block["transactions"] = txs
+		n.blocks = append(n.blocks, block)
+	}
+	return n
+}
+
+func (s *testLegacyNode) GasPrice() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(14))
+}
+
+func (s *testLegacyNode) GetBlockByNumber(number rpc.BlockNumber, _ bool) map[string]interface{} {
+	if number < 0 {
+		number = rpc.BlockNumber(len(s.blocks) - 1)
+	}
+	if int(number) >= len(s.blocks) {
+		return nil
+	}
+	return s.blocks[number]
+}
+
+func TestLegacyNodeShims(t *testing.T) {
+	cfg := testConfig(newTestNodeWith(t, &testEthService{chainID: 1}, newTestLegacyNode(t)), newTestNode(t, 1))
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 2; i++ {
+		tip, err := c.SuggestGasTipCap(context.Background())
+		if err != nil || tip.Int64() != 4 {
+			t.Fatalf("SuggestGasTipCap = %v, %v, want the gas price less the base fee", tip, err)
+		}
+	}
+	if c.(*client).m.supports("SuggestGasTipCap") {
+		t.Fatal("missing eth_maxPriorityFeePerGas not remembered")
+	}
+
+	h, err := c.FeeHistory(context.Background(), 2, nil, []float64{50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.OldestBlock.Int64() != 1 || len(h.BaseFee) != 3 || len(h.GasUsedRatio) != 2 || len(h.Reward) != 2 {
+		t.Fatalf("FeeHistory = %+v, want blocks 1 and 2", h)
+	}
+	if h.GasUsedRatio[0] != 0.75 || h.GasUsedRatio[1] != 1 {
+		t.Fatalf("gas used ratios = %v, want 0.75 and 1", h.GasUsedRatio)
+	}
+	if h.Reward[0][0].Int64() != 1 || h.Reward[1][0].Int64() != 3 {
+		t.Fatalf("rewards = %v, want 1 and 3", h.Reward)
+	}
+	// block 2 is full, the base fee rises by 1/8
+	if h.BaseFee[1].Int64() != 10 || h.BaseFee[2].Int64() != 11 {
+		t.Fatalf("base fees = %v, want 10, 10, 11", h.BaseFee)
+	}
+}
+
+func TestNextBaseFee(t *testing.T) {
+	for _, tt := range []struct {
+		used uint64
+		want int64
+	}{
+		{50, 1000},  // at target
+		{100, 1125}, // full
+		{0, 875},    // empty
+	} {
+		h := &types.Header{GasLimit: 100, GasUsed: tt.used, BaseFee: big.NewInt(1000)}
+		if got := nextBaseFee(h); got.Int64() != tt.want {
+			t.Errorf("nextBaseFee with %d gas used = %s, want %d", tt.used, got, tt.want)
+		}
+	}
+}
@@ -2,9 +2,13 @@ package ethclient
 
 import (
 	"fmt"
+	"io"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/kelseyhightower/envconfig"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -18,15 +22,394 @@ type Config struct {
 	RpcName          string `required:"true"`
 	FailoverRpcUrl   string `required:"true"`
 	FailoverRpcName  string `required:"true"`
+
+	// websocket urls of the providers serving the subscriptions, with
+	// their own failover, when the rpc urls are http ones
+	WsUrl         string
+	FailoverWsUrl string
+
+	// attempts in a row to subscribe again to new heads after the
+	// subscription failed, before reporting the error (5 when 0), and the
+	// delay between them (1s when 0)
+	ResubscribeMaxAttempts int
+	ResubscribeDelayMs     int
+
+	// headers sent to each endpoint (e.g. X-Api-Key:secret), the value of
+	// its Authorization header (e.g. Bearer <token>) and the hex encoded
+	// secret of JWT auth as used by the engine API, a token being issued for
+	// every request. They also apply when dialing the websocket urls.
+	RpcHeaders            map[string]string
+	FailoverRpcHeaders    map[string]string
+	RpcAuthHeader         string
+	FailoverRpcAuthHeader string
+	RpcJwtSecret          string
+	FailoverRpcJwtSecret  string
+
+	// log a share of the successful and of the failed call attempts, from
+	// 0 to 1, along with every attempt slower than LogSlowCallMs, with
+	// their method, endpoint, attempt, duration and error. 0 disables them.
+	LogCallSampleRate  float64
+	LogErrorSampleRate float64
+	LogSlowCallMs      int
+
+	// upper bounds of the rpc_latency_milliseconds buckets, in increasing
+	// order, defaults to powers of 2 from 2ms to 2s
+	LatencyBucketsMs []float64
+	// PrometheusRegisterer receives the metrics instead of the default
+	// registry. It can only be set from code.
+	PrometheusRegisterer prometheus.Registerer `ignored:"true"`
+
+	// export per method and endpoint request statistics every interval to
+	// StatsHandler and, as JSON lines, to StatsWriter, 0 disables it. The
+	// handler and writer can only be set from code.
+	StatsIntervalSec int
+	StatsHandler     func([]StatsRecord) `ignored:"true"`
+	StatsWriter      io.Writer           `ignored:"true"`
+
+	// check that all endpoints serve the same chain on New and, if the
+	// interval is set, periodically afterwards
+	VerifyChainID            bool `default:"true"`
+	VerifyChainIDIntervalSec int
+
+	// poll the block height of every endpoint, backs ApproxHead
+	HeadPollIntervalMs int
+	// keep reads of the latest state and block (BalanceAt, CallContract,
+	// HeaderByNumber... with a nil block) from endpoints polled more than
+	// that many blocks behind the highest height, they fail with
+	// ErrStaleEndpoint when every endpoint lags. 0 disables it, it needs
+	// HeadPollIntervalMs.
+	MaxHeadLagBlocks uint64
+	// poll the finalized block of every endpoint along with the heights and
+	// report finalized blocks differing between endpoints
+	CheckFinality bool
+
+	// keep the base fees of that many latest blocks, fetched along with the
+	// heads by the head watcher, for BaseFeeAt and RecentBaseFees
+	BaseFeeWindowBlocks int
+
+	// query all endpoints for these methods and compare the results, a
+	// disagreement on latest state is tolerated while endpoints are at
+	// most ConsensusBlockTolerance blocks apart
+	ConsensusMethods        []string
+	ConsensusBlockTolerance uint64 `default:"2"`
+	ConsensusFailOnMismatch bool   `default:"false"`
+
+	// tie the lifetime of subscriptions to the context they were created
+	// with, by default it only bounds establishing them
+	SubscriptionFollowsContext bool `default:"false"`
+
+	// close websocket connections unused for that long, 0 keeps them open
+	IdleConnTimeoutSec int
+
+	// after that many consecutive calls the other endpoint had to serve,
+	// only use an endpoint as a last resort for the cooldown, and until it
+	// answers a probe, 0 disables it
+	StickyFailoverThreshold   int
+	StickyFailoverCooldownSec int `default:"30"`
+
+	// don't connect in New but on first use, endpoints that fail to dial
+	// are dialed again in the background while the other one serves
+	LazyDial bool `default:"false"`
+
+	// after SendTransaction, serve TransactionByHash, TransactionReceipt and
+	// PendingNonceAt of the transaction and its sender from the endpoint
+	// that accepted it for that long, 0 disables it
+	ReadAfterWriteGraceSec int
+
+	// strict mode: SendTransaction and StrictFailoverMethods only fail over
+	// for calls whose context went through AllowFailover
+	StrictFailover        bool `default:"false"`
+	StrictFailoverMethods []string
+
+	// promote the backup endpoint to primary when, over a whole window, it
+	// had fewer errors or a latency below PromotionLatencyRatio times the
+	// primary's, 0 disables it
+	PromotionWindowSec    int
+	PromotionLatencyRatio float64 `default:"0.8"`
+
+	// make the backup endpoint the primary as soon as moving averages of
+	// the latency of the methods both serve and the error rate of calls
+	// score it clearly better, probing every endpoint each
+	// AdaptiveRoutingProbeSec (10 when 0). It replaces PromotionWindowSec.
+	AdaptiveRouting         bool `default:"false"`
+	AdaptiveRoutingProbeSec int  `default:"10"`
+
+	// number of recent blocks an endpoint keeps the state of, e.g. 128 for
+	// a pruned geth full node, 0 for an archive node. State reads at older
+	// blocks skip the endpoint, which requires the head watcher.
+	RpcStateHistoryBlocks         uint64
+	FailoverRpcStateHistoryBlocks uint64
+
+	// the endpoint offers the debug trace methods of TraceClient, as do
+	// the providers whose preset says so. Trace calls only go to these
+	// endpoints, or to any endpoint when none is known to offer them.
+	RpcTrace         bool
+	FailoverRpcTrace bool
+
+	// methods sent to each endpoint, and its websocket endpoint, e.g.
+	// those a cheaper provider forbids. Methods are named as in metrics
+	// (FilterLogs, TraceTransaction, optimism_rollupConfig), a trailing *
+	// matches any suffix. Calls only go to the endpoints allowing them, any
+	// method when the allow list is empty, or else fail with
+	// ErrMethodNotSupported.
+	RpcAllowMethods         []string
+	RpcDenyMethods          []string
+	FailoverRpcAllowMethods []string
+	FailoverRpcDenyMethods  []string
+
+	// client side rate limit of each endpoint in calls per second, with
+	// bursts of up to RateBurst calls (the rate by default), 0 disables it.
+	// Calls beyond the limit of an endpoint fail over to the other one.
+	RpcRateLimit         float64
+	RpcRateBurst         int
+	FailoverRpcRateLimit float64
+	FailoverRpcRateBurst int
+
+	// query all endpoints for the latest block or state with these methods
+	// and return the result of the one furthest ahead, rather than the
+	// primary's
+	FreshestMethods []string
+
+	// check structural invariants of receipts, blocks, headers and logs
+	// (e.g. a receipt of the transaction asked for, logs in the queried
+	// range and in order), responses breaking them fail over as
+	// ErrInvalidResponse
+	ValidateResponses bool
+
+	// substrings of provider errors meaning a block, transaction or receipt
+	// doesn't exist, returned as ethereum.NotFound like null results
+	NotFoundErrors []string
+
+	// count NotFound as a success in metrics and request statistics for
+	// these methods, e.g. TransactionReceipt polled for pending transactions.
+	// SuccessClassifier, which can only be set from code, gets the outcome
+	// of every attempt along with that classification and decides.
+	NotFoundSuccessMethods []string
+	SuccessClassifier      func(method string, err error, successful bool) bool `ignored:"true"`
+
+	// share one upstream call between identical concurrent calls to
+	// BlockNumber, BlockByNumber, HeaderByNumber, BalanceAt and the gas
+	// price suggestions
+	CoalesceCalls bool `default:"false"`
+
+	// cache up to CacheSize immutable responses (blocks and headers by hash,
+	// chain id, receipts and code at blocks CacheFinalityDepth below the
+	// head watcher's head) for CacheTTLSec, 0 keeps them until evicted.
+	// A size of 0 disables it.
+	CacheSize          int
+	CacheTTLSec        int
+	CacheFinalityDepth uint64 `default:"64"`
+
+	// methods still served during Shutdown along with receipt polls, e.g.
+	// BlockNumber to count confirmations
+	CriticalMethods []string
+
+	// answer ChainID, NetworkID and the immutable calls cached above from
+	// their last successful answer, kept for up to FailsafeCacheSize calls
+	// (1024 by default), when every endpoint fails, see WithStalenessReport
+	FailsafeCache     bool
+	FailsafeCacheSize int
+
+	// recurring maintenance windows during which an endpoint is drained,
+	// ';' separated "<cron spec> <duration>" in UTC, e.g. "0 3 * * 0 2h"
+	RpcMaintenanceWindows         string
+	FailoverRpcMaintenanceWindows string
+
+	// bound every attempt so a slow endpoint fails over promptly even
+	// without a caller deadline, per method overrides take precedence, 0
+	// disables it
+	MethodTimeoutMs  int
+	MethodTimeoutsMs map[string]int
+
+	// fill the rate limits, batch sizes and state history left unset from
+	// the presets of well-known providers, matched by endpoint url
+	ProviderPresets bool `default:"false"`
+
+	// maximum number of elements per batch request of BatchCall, the
+	// smallest cap of the provider presets or 100 when 0
+	BatchSize int `default:"0"`
+
+	// Multicall3 contract packing the calls of MulticallContract, at its
+	// usual address when empty, and maximum number of calls per aggregate3
+	// call, 500 when 0
+	MulticallAddress string
+	MulticallSize    int `default:"0"`
+
+	// block range of the queries of BackfillLogs, capped by the provider
+	// presets, 2000 when 0
+	LogChunkBlocks uint64 `default:"2000"`
+
+	// split the block range of FilterLogs queries a provider rejects for
+	// their range or result count in halves, down to single blocks,
+	// rather than failing over, with up to LogSplitConcurrency (4 when 0)
+	// queries at once
+	SplitLogQueries     bool `default:"false"`
+	LogSplitConcurrency int  `default:"0"`
+
+	// assign the nonces of NextNonce and SendTransactionWithNonce locally,
+	// from the highest pending nonce of the endpoints, rather than asking
+	// the primary for every transaction
+	ManageNonces bool `default:"false"`
+
+	// added to estimated gas limits by FillTransactOpts
+	GasLimitMarginPercent int `default:"20"`
+
+	// retries against the same endpoint before failing over
+	RetryMaxAttempts       int `default:"1"`
+	RetryInitialBackoffMs  int `default:"100"`
+	RetryMaxBackoffMs      int `default:"2000"`
+	RetryMethodMaxAttempts map[string]int
+	// RetryPolicies overrides the retry policy of individual methods, it can
+	// only be set from code.
+	RetryPolicies map[string]*RetryPolicy `ignored:"true"`
+
+	// Interceptors are applied around every RPC method, the first one being
+	// the outermost. They can only be set from code.
+	Interceptors []Interceptor `ignored:"true"`
+	// Tracer is the OpenTelemetry tracer receiving a span per method call
+	// and per attempt, tracing is disabled when nil. It can only be set from code.
+	Tracer trace.Tracer `ignored:"true"`
+	// send the W3C traceparent header of the attempt span with http
+	// requests, so providers logging it can be correlated with our traces
+	PropagateTraceContext bool `default:"false"`
 }
 
 func (c *Config) Valid() error {
+	for i := 1; i < len(c.LatencyBucketsMs); i++ {
+		if c.LatencyBucketsMs[i] <= c.LatencyBucketsMs[i-1] {
+			return fmt.Errorf("invalid LatencyBucketsMs: %v", c.LatencyBucketsMs)
+		}
+	}
 	if len(c.RpcName) >= nameLenLimit {
 		return fmt.Errorf("invalid RpcName: %s", c.RpcName)
 	}
+	for _, url := range []string{c.WsUrl, c.FailoverWsUrl} {
+		if url != "" && !isWebsocketURL(url) {
+			return fmt.Errorf("invalid websocket url: %s", url)
+		}
+	}
 	if len(c.FailoverRpcName) >= nameLenLimit {
 		return fmt.Errorf("invalid RpcFailoverName: %s", c.FailoverRpcName)
 	}
+	if _, err := endpointAuth(c.RpcHeaders, c.RpcAuthHeader, c.RpcJwtSecret); err != nil {
+		return fmt.Errorf("invalid RpcJwtSecret: %w", err)
+	}
+	if _, err := endpointAuth(c.FailoverRpcHeaders, c.FailoverRpcAuthHeader, c.FailoverRpcJwtSecret); err != nil {
+		return fmt.Errorf("invalid FailoverRpcJwtSecret: %w", err)
+	}
+	if _, err := parseMaintenanceWindows(c.RpcMaintenanceWindows); err != nil {
+		return err
+	}
+	if _, err := parseMaintenanceWindows(c.FailoverRpcMaintenanceWindows); err != nil {
+		return err
+	}
+	if c.VerifyChainIDIntervalSec < 0 {
+		return fmt.Errorf("invalid VerifyChainIDIntervalSec: %d", c.VerifyChainIDIntervalSec)
+	}
+	if c.ResubscribeMaxAttempts < 0 || c.ResubscribeDelayMs < 0 {
+		return fmt.Errorf("invalid ResubscribeMaxAttempts: %d, delay %dms", c.ResubscribeMaxAttempts, c.ResubscribeDelayMs)
+	}
+	if c.LogCallSampleRate < 0 || c.LogCallSampleRate > 1 {
+		return fmt.Errorf("invalid LogCallSampleRate: %v", c.LogCallSampleRate)
+	}
+	if c.LogErrorSampleRate < 0 || c.LogErrorSampleRate > 1 {
+		return fmt.Errorf("invalid LogErrorSampleRate: %v", c.LogErrorSampleRate)
+	}
+	if c.LogSlowCallMs < 0 {
+		return fmt.Errorf("invalid LogSlowCallMs: %d", c.LogSlowCallMs)
+	}
+	if c.HeadPollIntervalMs < 0 {
+		return fmt.Errorf("invalid HeadPollIntervalMs: %d", c.HeadPollIntervalMs)
+	}
+	if c.MaxHeadLagBlocks > 0 && c.HeadPollIntervalMs == 0 {
+		return fmt.Errorf("MaxHeadLagBlocks needs HeadPollIntervalMs")
+	}
+	for _, m := range c.FreshestMethods {
+		if !freshestMethods[m] {
+			return fmt.Errorf("invalid FreshestMethods: %s doesn't support freshest reads", m)
+		}
+	}
+	for _, m := range c.ConsensusMethods {
+		if !consensusMethods[m] {
+			return fmt.Errorf("invalid ConsensusMethods: %s doesn't support consensus reads", m)
+		}
+	}
+	if c.IdleConnTimeoutSec < 0 {
+		return fmt.Errorf("invalid IdleConnTimeoutSec: %d", c.IdleConnTimeoutSec)
+	}
+	if c.StickyFailoverThreshold < 0 {
+		return fmt.Errorf("invalid StickyFailoverThreshold: %d", c.StickyFailoverThreshold)
+	}
+	if c.StickyFailoverThreshold > 0 && c.StickyFailoverCooldownSec <= 0 {
+		return fmt.Errorf("invalid StickyFailoverCooldownSec: %d", c.StickyFailoverCooldownSec)
+	}
+	if c.StatsIntervalSec < 0 {
+		return fmt.Errorf("invalid StatsIntervalSec: %d", c.StatsIntervalSec)
+	}
+	if c.RpcRateLimit < 0 || c.RpcRateBurst < 0 {
+		return fmt.Errorf("invalid RpcRateLimit: %v, burst %d", c.RpcRateLimit, c.RpcRateBurst)
+	}
+	if c.FailoverRpcRateLimit < 0 || c.FailoverRpcRateBurst < 0 {
+		return fmt.Errorf("invalid FailoverRpcRateLimit: %v, burst %d", c.FailoverRpcRateLimit, c.FailoverRpcRateBurst)
+	}
+	if c.BaseFeeWindowBlocks < 0 {
+		return fmt.Errorf("invalid BaseFeeWindowBlocks: %d", c.BaseFeeWindowBlocks)
+	}
+	if c.FailsafeCacheSize < 0 {
+		return fmt.Errorf("invalid FailsafeCacheSize: %d", c.FailsafeCacheSize)
+	}
+	if c.CacheSize < 0 || c.CacheTTLSec < 0 {
+		return fmt.Errorf("invalid CacheSize: %d, ttl %ds", c.CacheSize, c.CacheTTLSec)
+	}
+	if c.ReadAfterWriteGraceSec < 0 {
+		return fmt.Errorf("invalid ReadAfterWriteGraceSec: %d", c.ReadAfterWriteGraceSec)
+	}
+	if c.PromotionWindowSec < 0 {
+		return fmt.Errorf("invalid PromotionWindowSec: %d", c.PromotionWindowSec)
+	}
+	if c.PromotionWindowSec > 0 && (c.PromotionLatencyRatio <= 0 || c.PromotionLatencyRatio > 1) {
+		return fmt.Errorf("invalid PromotionLatencyRatio: %v", c.PromotionLatencyRatio)
+	}
+	if c.AdaptiveRouting && c.PromotionWindowSec > 0 {
+		return fmt.Errorf("AdaptiveRouting and PromotionWindowSec %d are exclusive", c.PromotionWindowSec)
+	}
+	if c.AdaptiveRoutingProbeSec < 0 {
+		return fmt.Errorf("invalid AdaptiveRoutingProbeSec: %d", c.AdaptiveRoutingProbeSec)
+	}
+	if c.RetryMaxAttempts < 0 {
+		return fmt.Errorf("invalid RetryMaxAttempts: %d", c.RetryMaxAttempts)
+	}
+	if c.RetryInitialBackoffMs < 0 || c.RetryMaxBackoffMs < 0 {
+		return fmt.Errorf("invalid retry backoff: %dms-%dms", c.RetryInitialBackoffMs, c.RetryMaxBackoffMs)
+	}
+	if c.BatchSize < 0 {
+		return fmt.Errorf("invalid BatchSize: %d", c.BatchSize)
+	}
+	if c.MulticallAddress != "" && !common.IsHexAddress(c.MulticallAddress) {
+		return fmt.Errorf("invalid MulticallAddress: %s", c.MulticallAddress)
+	}
+	if c.LogSplitConcurrency < 0 {
+		return fmt.Errorf("invalid LogSplitConcurrency: %d", c.LogSplitConcurrency)
+	}
+	if c.MulticallSize < 0 {
+		return fmt.Errorf("invalid MulticallSize: %d", c.MulticallSize)
+	}
+	if c.GasLimitMarginPercent < 0 {
+		return fmt.Errorf("invalid GasLimitMarginPercent: %d", c.GasLimitMarginPercent)
+	}
+	if c.MethodTimeoutMs < 0 {
+		return fmt.Errorf("invalid MethodTimeoutMs: %d", c.MethodTimeoutMs)
+	}
+	for method, ms := range c.MethodTimeoutsMs {
+		if ms < 0 {
+			return fmt.Errorf("invalid MethodTimeoutsMs for %s: %d", method, ms)
+		}
+	}
+	for method, n := range c.RetryMethodMaxAttempts {
+		if n < 0 {
+			return fmt.Errorf("invalid RetryMethodMaxAttempts for %s: %d", method, n)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,164 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ErrResultMismatch is returned in consensus mode when the endpoints
+// return different results and ConsensusFailOnMismatch is set.
+var ErrResultMismatch = errors.New("rpc endpoints returned different results")
+
+// consensusMethods are the methods supporting consensus reads.
+var consensusMethods = map[string]bool{
+	"BalanceAt":          true,
+	"CallContract":       true,
+	"CodeAt":             true,
+	"NonceAt":            true,
+	"StorageAt":          true,
+	"TransactionReceipt": true,
+}
+
+func (c *client) consensusEnabled(method string) bool {
+	for _, m := range c.cfg.ConsensusMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// consensus queries all available endpoints concurrently and compares
+// their results. For reads of the latest state, a disagreement is
+// tolerated if the endpoints' heights are within ConsensusBlockTolerance
+// of each other, as one of them is then merely catching up. The main
+// endpoint's result is returned unless the mismatch is fatal.
+func consensus[T any](
+	ctx context.Context,
+	c *client,
+	method string,
+	latest bool,
+	fn func(context.Context, *ethclient.Client) (T, error),
+	equal func(a, b T) bool,
+) (r T, err error) {
+	err = c.run(ctx, method, func(ctx context.Context) error {
+		var endpoints []*endpoint
+		for _, e := range c.endpoints() {
+			if !e.unavailable() && e.methods.allows(method) {
+				endpoints = append(endpoints, e)
+			}
+		}
+		if len(endpoints) < 2 || hasRoutingHint(ctx) {
+			return c.failover(ctx, method, func(ctx context.Context, ec *ethclient.Client) (err error) {
+				r, err = fn(ctx, ec)
+				return err
+			})
+		}
+
+		results := make([]T, len(endpoints))
+		errs := make([]error, len(endpoints))
+		var wg sync.WaitGroup
+		for i, e := range endpoints {
+			wg.Add(1)
+			go func(i int, e *endpoint) {
+				defer wg.Done()
+				errs[i] = c.try(ctx, method, e, false, func(ctx context.Context, ec *ethclient.Client) (err error) {
+					results[i], err = fn(ctx, ec)
+					return err
+				})
+			}(i, e)
+		}
+		wg.Wait()
+
+		// not found is an answer worth comparing, other errors aren't
+		answered := func(i int) bool {
+			return errs[i] == nil || errors.Is(errs[i], ethereum.NotFound)
+		}
+		switch {
+		case !answered(0) && !answered(1):
+			return errs[0]
+		case !answered(0):
+			r = results[1]
+			return errs[1]
+		case !answered(1):
+			r = results[0]
+			return errs[0]
+		}
+		r = results[0]
+		if (errs[0] == nil) == (errs[1] == nil) && (errs[0] != nil || equal(results[0], results[1])) {
+			return errs[0]
+		}
+		if latest && c.withinTolerance(ctx, endpoints[0], endpoints[1]) {
+			return errs[0]
+		}
+
+		c.metrics.ObserveMismatch(method)
+		mismatch := fmt.Errorf("%w: %s differs between rpc %s and %s", ErrResultMismatch, method, endpoints[0].name, endpoints[1].name)
+		if c.cfg.ConsensusFailOnMismatch {
+			return mismatch
+		}
+		c.logger.Warn().Msgf("%s", mismatch)
+		return errs[0]
+	})
+	return r, err
+}
+
+// withinTolerance reports whether the heights of a and b are close enough
+// to explain a disagreement on the latest state. Heights known from the
+// head watcher are used when available, else they are fetched.
+func (c *client) withinTolerance(ctx context.Context, a, b *endpoint) bool {
+	var heights [2]uint64
+	for i, e := range []*endpoint{a, b} {
+		if heights[i] = e.head.Load(); heights[i] > 0 {
+			continue
+		}
+		err := e.call(ctx, func(ec *ethclient.Client) (err error) {
+			heights[i], err = ec.BlockNumber(ctx)
+			return err
+		})
+		if err != nil {
+			return false
+		}
+	}
+	return heightsWithinTolerance(heights[0], heights[1], c.cfg.ConsensusBlockTolerance)
+}
+
+// heightsWithinTolerance reports whether a and b differ by at most
+// tolerance blocks. Equal heights don't explain a disagreement.
+func heightsWithinTolerance(a, b, tolerance uint64) bool {
+	lag := a - b
+	if b > a {
+		lag = b - a
+	}
+	return lag > 0 && lag <= tolerance
+}
+
+func bigEqual(a, b *big.Int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Cmp(b) == 0
+}
+
+func uint64Equal(a, b uint64) bool {
+	return a == b
+}
+
+func receiptEqual(a, b *types.Receipt) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.TxHash == b.TxHash &&
+		a.Status == b.Status &&
+		a.BlockHash == b.BlockHash &&
+		a.GasUsed == b.GasUsed &&
+		a.CumulativeGasUsed == b.CumulativeGasUsed &&
+		len(a.Logs) == len(b.Logs)
+}
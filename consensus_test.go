@@ -0,0 +1,105 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestHeightsWithinTolerance(t *testing.T) {
+	for _, tt := range []struct {
+		a, b, tolerance uint64
+		want            bool
+	}{
+		{100, 100, 2, false},
+		{100, 101, 2, true},
+		{102, 100, 2, true},
+		{100, 103, 2, false},
+		{100, 101, 0, false},
+	} {
+		if got := heightsWithinTolerance(tt.a, tt.b, tt.tolerance); got != tt.want {
+			t.Errorf("heightsWithinTolerance(%d, %d, %d) = %v, want %v", tt.a, tt.b, tt.tolerance, got, tt.want)
+		}
+	}
+}
+
+func TestConsensusEqual(t *testing.T) {
+	if !bigEqual(nil, nil) || bigEqual(big.NewInt(1), nil) || !bigEqual(big.NewInt(1), big.NewInt(1)) || bigEqual(big.NewInt(1), big.NewInt(2)) {
+		t.Error("bigEqual")
+	}
+	r := &types.Receipt{Status: 1, GasUsed: 21000, TxHash: common.Hash{1}}
+	same := *r
+	other := *r
+	other.Status = 0
+	if !receiptEqual(nil, nil) || receiptEqual(r, nil) || !receiptEqual(r, &same) || receiptEqual(r, &other) {
+		t.Error("receiptEqual")
+	}
+}
+
+func TestConsensusBalanceAt(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		main, backup testEthService
+		block        *big.Int
+		failOnDiff   bool
+		want         int64
+		wantMismatch bool
+	}{
+		{
+			name: "agree",
+			main: testEthService{head: 100, balance: 5}, backup: testEthService{head: 100, balance: 5},
+			failOnDiff: true, want: 5,
+		},
+		{
+			name: "disagree on past block",
+			main: testEthService{head: 100, balance: 5}, backup: testEthService{head: 101, balance: 6},
+			block: big.NewInt(90), failOnDiff: true, wantMismatch: true,
+		},
+		{
+			name: "disagree on latest within tolerance",
+			main: testEthService{head: 100, balance: 5}, backup: testEthService{head: 101, balance: 6},
+			failOnDiff: true, want: 5,
+		},
+		{
+			name: "disagree on latest at the same height",
+			main: testEthService{head: 100, balance: 5}, backup: testEthService{head: 100, balance: 6},
+			failOnDiff: true, wantMismatch: true,
+		},
+		{
+			name: "disagree without failing",
+			main: testEthService{head: 100, balance: 5}, backup: testEthService{head: 100, balance: 6},
+			want: 5,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.main.chainID, tt.backup.chainID = 1, 1
+			cfg := testConfig(newTestNodeWith(t, &tt.main), newTestNodeWith(t, &tt.backup))
+			cfg.ConsensusMethods = []string{"BalanceAt"}
+			cfg.ConsensusBlockTolerance = 2
+			cfg.ConsensusFailOnMismatch = tt.failOnDiff
+			c, err := New("test", "eth", cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.(*client).Close()
+
+			got, err := c.BalanceAt(context.Background(), common.Address{}, tt.block)
+			if tt.wantMismatch {
+				if !errors.Is(err, ErrResultMismatch) {
+					t.Fatalf("BalanceAt error = %v, want ErrResultMismatch", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Int64() != tt.want {
+				t.Fatalf("BalanceAt = %s, want %d", got, tt.want)
+			}
+		})
+	}
+}
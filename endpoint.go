@@ -0,0 +1,287 @@
+package ethclient
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// capabilityRecheckInterval is how long an endpoint found lacking a
+// capability gated method is skipped for it.
+const capabilityRecheckInterval = 10 * time.Minute
+
+// endpoint is a named rpc client along with its routing state.
+type endpoint struct {
+	name        string
+	url         string // empty for rpc clients dialed by the user
+	metrics     *metrics
+	dialOptions []rpc.ClientOption
+	pool        *Pool // nil unless sharing connections
+	// recent blocks whose state is kept, 0 for an archive node
+	stateHistory uint64
+	trace        bool         // offers the debug trace methods
+	methods      methodList   // methods it may be sent
+	limiter      *tokenBucket // nil without a rate limit
+	penalty      *penalty
+	preset       *ProviderPreset // nil unless a known provider
+
+	mu        sync.Mutex
+	rc        *rpc.Client // nil once reaped for being idle
+	ec        *ethclient.Client
+	closed    bool
+	inflight  int
+	retired   map[*ethclient.Client]*retiredConn
+	lastUsed  time.Time
+	subs      atomic.Int64
+	head      atomic.Uint64                // last polled block height
+	finalized atomic.Pointer[types.Header] // last polled finalized block
+
+	unsupported sync.Map // method -> time it was found missing
+
+	maintenance   []*maintenanceWindow
+	draining      atomic.Bool
+	chainMismatch atomic.Bool
+	dialFailed    atomic.Bool
+	sidelined     atomic.Bool  // see sideline
+	failures      atomic.Int64 // consecutive calls another endpoint succeeded
+	quality       quality
+	outcome       outcome
+}
+
+// retiredConn is a connection replaced by a rotation, it is closed once
+// the calls still using it are done.
+type retiredConn struct {
+	rc       *rpc.Client
+	inflight int
+}
+
+// newEndpoint creates an endpoint served by rc, or dialed on first use
+// when rc is nil.
+func newEndpoint(name string, url string, rc *rpc.Client, metrics *metrics) *endpoint {
+	e := &endpoint{
+		name:     name,
+		url:      url,
+		metrics:  metrics,
+		penalty:  &penalty{},
+		lastUsed: time.Now(),
+	}
+	if rc != nil {
+		e.rc, e.ec = rc, ethclient.NewClient(rc)
+		e.metrics.AddConnections(name, 1)
+	}
+	return e
+}
+
+// isWebsocket reports whether the endpoint keeps a persistent connection
+// that is worth reaping when idle.
+func (e *endpoint) isWebsocket() bool {
+	return isWebsocketURL(e.currentURL())
+}
+
+func (e *endpoint) currentURL() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.url
+}
+
+// acquire returns the endpoint's client and the rpc client it wraps,
+// dialing them again if they were reaped. Callers must release the client
+// once done.
+func (e *endpoint) acquire(ctx context.Context) (*ethclient.Client, *rpc.Client, error) {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil, nil, rpc.ErrClientQuit
+	}
+	if e.rc != nil {
+		e.inflight++
+		ec, rc := e.ec, e.rc
+		e.mu.Unlock()
+		return ec, rc, nil
+	}
+	url := e.url
+	e.mu.Unlock()
+
+	// dial without holding the lock so a slow dial doesn't block callers
+	// of the endpoint, or the reaper, on an unrelated connection
+	rc, err := e.pool.dial(ctx, url, e.dialOptions)
+	if err != nil {
+		if ctx.Err() == nil {
+			e.dialFailed.Store(true)
+		}
+		return nil, nil, err
+	}
+	e.dialFailed.Store(false)
+
+	e.mu.Lock()
+	if e.url != url && e.rc == nil && !e.closed {
+		// rotated and reaped meanwhile, dial the new url instead
+		e.mu.Unlock()
+		e.pool.release(rc)
+		return e.acquire(ctx)
+	}
+	defer e.mu.Unlock()
+	if e.closed {
+		e.pool.release(rc)
+		return nil, nil, rpc.ErrClientQuit
+	}
+	if e.rc != nil {
+		// lost a race with a concurrent dial or rotation
+		e.pool.release(rc)
+	} else {
+		e.rc, e.ec = rc, ethclient.NewClient(rc)
+		e.metrics.AddConnections(e.name, 1)
+	}
+	e.inflight++
+	return e.ec, e.rc, nil
+}
+
+// release returns a client obtained from acquire.
+func (e *endpoint) release(ec *ethclient.Client) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastUsed = time.Now()
+	if r, ok := e.retired[ec]; ok {
+		if r.inflight--; r.inflight == 0 {
+			e.closeRetired(ec, r)
+		}
+		return
+	}
+	e.inflight--
+}
+
+// call runs fn with the endpoint's client.
+func (e *endpoint) call(ctx context.Context, fn func(*ethclient.Client) error) error {
+	return e.callRPC(ctx, func(ec *ethclient.Client, _ *rpc.Client) error {
+		return fn(ec)
+	})
+}
+
+// callRPC runs fn with the endpoint's client and the rpc client it wraps,
+// for the methods ethclient lacks.
+func (e *endpoint) callRPC(ctx context.Context, fn func(*ethclient.Client, *rpc.Client) error) error {
+	ec, rc, err := e.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer e.release(ec)
+	return fn(ec, rc)
+}
+
+// rotate switches the endpoint to url, served by the already dialed rc.
+// The previous connection is closed once the calls in flight on it are
+// done, subscriptions established on it end with an error.
+func (e *endpoint) rotate(url string, rc *rpc.Client) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		e.pool.release(rc)
+		return
+	}
+	if e.rc != nil {
+		r := &retiredConn{rc: e.rc, inflight: e.inflight}
+		if r.inflight == 0 {
+			e.closeRetired(e.ec, r)
+		} else {
+			if e.retired == nil {
+				e.retired = make(map[*ethclient.Client]*retiredConn)
+			}
+			e.retired[e.ec] = r
+		}
+	}
+	e.url, e.rc, e.ec = url, rc, ethclient.NewClient(rc)
+	e.inflight = 0
+	e.lastUsed = time.Now()
+	e.metrics.AddConnections(e.name, 1)
+	// the new url may be served by another provider
+	e.unsupported.Range(func(method, _ interface{}) bool {
+		e.unsupported.Delete(method)
+		return true
+	})
+}
+
+func (e *endpoint) closeRetired(ec *ethclient.Client, r *retiredConn) {
+	e.pool.release(r.rc)
+	delete(e.retired, ec)
+	e.metrics.AddConnections(e.name, -1)
+}
+
+// reapIfIdle closes the connection if it has not been used for timeout and
+// has no call or subscription in flight.
+func (e *endpoint) reapIfIdle(timeout time.Duration) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.rc == nil || e.inflight > 0 || e.subs.Load() > 0 || time.Since(e.lastUsed) < timeout {
+		return false
+	}
+	e.pool.release(e.rc)
+	e.rc, e.ec = nil, nil
+	e.metrics.AddConnections(e.name, -1)
+	return true
+}
+
+func (e *endpoint) Close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.closed = true
+	if e.rc != nil {
+		e.pool.release(e.rc)
+		e.rc, e.ec = nil, nil
+		e.metrics.AddConnections(e.name, -1)
+	}
+	for ec, r := range e.retired {
+		e.closeRetired(ec, r)
+	}
+}
+
+// supports reports whether the endpoint wasn't recently found lacking
+// method. Marks expire so the endpoint gets probed again.
+func (e *endpoint) supports(method string) bool {
+	return e.unsupportedFor(method) == 0
+}
+
+// unsupportedFor returns how long the endpoint is still considered lacking
+// method, 0 if it isn't.
+func (e *endpoint) unsupportedFor(method string) time.Duration {
+	v, ok := e.unsupported.Load(method)
+	if !ok {
+		return 0
+	}
+	left := capabilityRecheckInterval - time.Since(v.(time.Time))
+	if left <= 0 {
+		e.unsupported.Delete(method)
+		return 0
+	}
+	return left
+}
+
+func (e *endpoint) markUnsupported(method string) {
+	e.unsupported.Store(method, time.Now())
+}
+
+// unavailable reports whether the endpoint should not receive traffic.
+func (e *endpoint) unavailable() bool {
+	return e.draining.Load() || e.chainMismatch.Load() || e.dialFailed.Load() || e.sidelined.Load()
+}
+
+type endpointKey struct{}
+
+// endpointFromContext returns the endpoint serving the current attempt.
+func endpointFromContext(ctx context.Context) *endpoint {
+	e, _ := ctx.Value(endpointKey{}).(*endpoint)
+	return e
+}
+
+type rpcClientKey struct{}
+
+// rpcClientFromContext returns the rpc client of the endpoint serving the
+// current attempt, acquired for it.
+func rpcClientFromContext(ctx context.Context) *rpc.Client {
+	rc, _ := ctx.Value(rpcClientKey{}).(*rpc.Client)
+	return rc
+}
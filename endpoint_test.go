@@ -0,0 +1,67 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// connOf returns the rpc client e holds, nil when reaped or closed.
+func connOf(e *endpoint) *rpc.Client {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.rc
+}
+
+func TestEndpointAcquireRedials(t *testing.T) {
+	url := newTestNode(t, 1)
+	rc, err := rpc.Dial(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := newEndpoint("test", url, rc, nil)
+	defer e.Close()
+
+	e.lastUsed = time.Now().Add(-time.Hour)
+	if !e.reapIfIdle(time.Minute) {
+		t.Fatal("idle endpoint was not reaped")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ec, _, err := e.acquire(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			e.release(ec)
+		}()
+	}
+	wg.Wait()
+	if connOf(e) == nil {
+		t.Fatal("endpoint was not dialed again")
+	}
+	if e.inflight != 0 {
+		t.Fatalf("inflight = %d, want 0", e.inflight)
+	}
+}
+
+func TestEndpointAcquireClosed(t *testing.T) {
+	url := newTestNode(t, 1)
+	rc, err := rpc.Dial(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := newEndpoint("test", url, rc, nil)
+	e.Close()
+	if _, _, err := e.acquire(context.Background()); !errors.Is(err, rpc.ErrClientQuit) {
+		t.Fatalf("acquire error = %v, want ErrClientQuit", err)
+	}
+}
@@ -0,0 +1,128 @@
+package ethclient
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrMethodNotSupported is returned when no endpoint offers the method.
+var ErrMethodNotSupported = errors.New("method not supported by any rpc endpoint")
+
+// ErrLogRangeExceeded wraps the errors of log queries rejected by a
+// provider for their block range or result count, with
+// Config.SplitLogQueries.
+var ErrLogRangeExceeded = errors.New("log query exceeds the limits of the rpc endpoint")
+
+// logRangeErrors are the messages of providers rejecting log queries for
+// their size, lower cased.
+var logRangeErrors = []string{
+	"query returned more than", // Infura: more than 10000 results
+	"block range",
+	"range is too large",
+	"range too large",
+	"response size exceeded", // Alchemy
+	"exceeds limit",
+	"max results",
+	"too many results",
+	"limited to a", // QuickNode: limited to a 10,000 range
+}
+
+// IsLogRangeError reports whether err is a provider rejecting a log query
+// for its block range or result count, which a narrower range could avoid.
+func IsLogRangeError(err error) bool {
+	if errors.Is(err, ErrLogRangeExceeded) {
+		return true
+	}
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range logRangeErrors {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMethodNotFound reports whether err is a JSON-RPC "method not found"
+// error, e.g. from a node with the admin or debug namespace disabled.
+func isMethodNotFound(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32601
+}
+
+// FailoverError is returned when a call failed on every endpoint it was
+// tried on. errors.Is and errors.As match the error of any attempt.
+type FailoverError struct {
+	Method   string
+	Attempts []EndpointAttempt // in the order the endpoints were tried
+}
+
+// EndpointAttempt is the failure of a call on one endpoint, retries
+// included.
+type EndpointAttempt struct {
+	Endpoint string
+	Duration time.Duration
+	Err      error
+}
+
+func (e *FailoverError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s failed on all rpc endpoints", e.Method)
+	for i, a := range e.Attempts {
+		sep := "; "
+		if i == 0 {
+			sep = ": "
+		}
+		fmt.Fprintf(&b, "%s%s after %s: %s", sep, a.Endpoint, a.Duration.Round(time.Millisecond), a.Err)
+	}
+	return b.String()
+}
+
+func (e *FailoverError) Unwrap() []error {
+	errs := make([]error, len(e.Attempts))
+	for i, a := range e.Attempts {
+		errs[i] = a.Err
+	}
+	return errs
+}
+
+// Endpoint returns the name of the endpoint that failed the call last.
+func (e *FailoverError) Endpoint() string {
+	if len(e.Attempts) == 0 {
+		return ""
+	}
+	return e.Attempts[len(e.Attempts)-1].Endpoint
+}
+
+// RetryAfterError is returned when a call fails fast without reaching any
+// endpoint, e.g. because they are all known to lack the method for now. It
+// tells when the call stands a chance to succeed again.
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%s (retry after %s)", e.Err, e.RetryAfter)
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter returns how long to wait before retrying a call that failed
+// with err, if known.
+func RetryAfter(err error) (time.Duration, bool) {
+	var ra *RetryAfterError
+	if errors.As(err, &ra) {
+		return ra.RetryAfter, true
+	}
+	return 0, false
+}
@@ -0,0 +1,79 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestFailoverError(t *testing.T) {
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1}, &testTxPool{reject: true}),
+		newTestNodeWith(t, &testEthService{chainID: 1}, &testTxPool{reject: true}),
+	)
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	err = c.SendTransaction(context.Background(), types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1)}))
+	var fe *FailoverError
+	if !errors.As(err, &fe) {
+		t.Fatalf("SendTransaction error = %v, want a FailoverError", err)
+	}
+	if len(fe.Attempts) != 2 || fe.Attempts[0].Endpoint != "main" || fe.Endpoint() != "failover" {
+		t.Fatalf("attempts = %+v, want main then failover", fe.Attempts)
+	}
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("underlying rpc error not matched by errors.As: %v", err)
+	}
+}
+
+func TestFailoverErrorUnwrap(t *testing.T) {
+	err := &FailoverError{Method: "BlockNumber", Attempts: []EndpointAttempt{
+		{Endpoint: "main", Duration: 12 * time.Millisecond, Err: io.EOF},
+		{Endpoint: "failover", Duration: 3 * time.Millisecond, Err: ErrAttemptTimeout},
+	}}
+	if !errors.Is(err, io.EOF) || !errors.Is(err, ErrAttemptTimeout) {
+		t.Fatalf("errors.Is doesn't match the attempts of %v", err)
+	}
+	want := "BlockNumber failed on all rpc endpoints: main after 12ms: EOF; failover after 3ms: "
+	if !strings.HasPrefix(err.Error(), want) {
+		t.Fatalf("Error() = %q, want prefix %q", err.Error(), want)
+	}
+}
+
+func TestDoReturnsResultOfSuccessfulAttempt(t *testing.T) {
+	c, err := New("test", "eth", testConfig(newTestNode(t, 1), newTestNode(t, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	r, err := do(context.Background(), c.(*client), "Test", func(ctx context.Context, _ *ethclient.Client) (string, error) {
+		if endpointFromContext(ctx).name == "main" {
+			return "partial", io.ErrUnexpectedEOF
+		}
+		return "ok", nil
+	})
+	if err != nil || r != "ok" {
+		t.Fatalf("do = %q, %v, want the result of the failover", r, err)
+	}
+
+	r, err = do(context.Background(), c.(*client), "Test", func(ctx context.Context, _ *ethclient.Client) (string, error) {
+		return "partial", io.ErrUnexpectedEOF
+	})
+	if err == nil || r != "" {
+		t.Fatalf("do = %q, %v, want the zero value and an error", r, err)
+	}
+}
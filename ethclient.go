@@ -1,30 +1,88 @@
 package ethclient
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"math/big"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
-type client struct {
-	metrics *metrics
-	logger  *zerolog.Logger
-	cfg     *Config
+const verifyTimeout = 10 * time.Second
 
-	m *ethclient.Client // main
-	b *ethclient.Client // backup
+type client struct {
+	metrics     *metrics
+	logger      *zerolog.Logger
+	cfg         *Config
+	interceptor Interceptor
+	tracer      trace.Tracer
+
+	m  *endpoint   // main
+	b  *endpoint   // backup
+	ws []*endpoint // of subscriptions, nil unless configured
+	// promoted swaps the roles of main and backup, see promoteOnLatency
+	// and adaptRoutes
+	promoted atomic.Bool
+
+	chainID  atomic.Pointer[big.Int]
+	head     headTracker
+	stats    *requestStats  // nil unless exporting stats
+	pins     *readPins      // nil unless pinning reads after writes
+	cache    *responseCache // nil unless caching immutable responses
+	failsafe *responseCache // last answers of immutable calls, nil unless enabled
+	calls    *callGroup     // nil unless coalescing calls
+	nonces   *nonceManager  // nil unless managing nonces
+	baseFees *baseFeeWindow // nil unless keeping recent base fees
+	// the multicall contract has no code, see MulticallContract
+	multicallAbsent atomic.Bool
+
+	failovers       atomic.Uint64
+	activeFailovers atomic.Int64
+	// last hour of failovers, attempts and rate limited attempts
+	failoverWindow  window
+	attemptWindow   window
+	rateLimitWindow window
+
+	shuttingDown  atomic.Bool
+	inflight      inflightCalls
+	subscriptions inflightCalls // active subscriptions
+
+	done      chan struct{}
+	stopOnce  sync.Once
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// FeeHistoryReader provides fee market history (eth_feeHistory), e.g. to
+// derive gas tip percentiles.
+type FeeHistoryReader interface {
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+}
+
+// BlobBaseFeeReader provides the blob base fee of the next block
+// (eth_blobBaseFee), for EIP-4844 fee estimation.
+type BlobBaseFeeReader interface {
+	BlobBaseFee(ctx context.Context) (*big.Int, error)
 }
 
 type Client interface {
 	bind.ContractBackend
+	bind.DeployBackend
 	ethereum.ChainReader
 	ethereum.TransactionReader
 	ethereum.ChainStateReader
@@ -36,14 +94,107 @@ type Client interface {
 	ethereum.PendingStateReader
 	ethereum.PendingContractCaller
 	ethereum.GasEstimator
-}
-
+	FeeHistoryReader
+	BlobBaseFeeReader
+	BlockHashContractCaller
+
+	// VerifyEndpoints checks that all endpoints serve the same chain.
+	VerifyEndpoints(ctx context.Context) error
+	// ApproxHead returns the approximate block height without an RPC call.
+	ApproxHead() uint64
+	// HeaderByTag returns the header of the latest, pending, safe or
+	// finalized block.
+	HeaderByTag(ctx context.Context, tag string) (*types.Header, error)
+	// FinalizedBlockNumber returns the number of the latest finalized block.
+	FinalizedBlockNumber(ctx context.Context) (uint64, error)
+	// RotateEndpointURL points an endpoint to a new url without downtime.
+	RotateEndpointURL(ctx context.Context, name string, newURL string) error
+	// UpdateEndpoints applies the endpoint urls and order of cfg at runtime.
+	UpdateEndpoints(ctx context.Context, cfg *Config) error
+	// BatchCall sends elems in batches with failover.
+	BatchCall(ctx context.Context, elems []rpc.BatchElem) error
+	// BatchBalanceAt returns the balances of accounts at blockNumber.
+	BatchBalanceAt(ctx context.Context, accounts []common.Address, blockNumber *big.Int) ([]*big.Int, error)
+	// BatchTransactionReceipt returns the receipts of txHashes.
+	BatchTransactionReceipt(ctx context.Context, txHashes []common.Hash) ([]*types.Receipt, error)
+	// MulticallContract executes read-only calls in Multicall3 aggregate3
+	// calls.
+	MulticallContract(ctx context.Context, calls []ethereum.CallMsg, blockNumber *big.Int) ([][]byte, error)
+	// BlockReceipts returns the receipts of the transactions of a block.
+	BlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*types.Receipt, error)
+	// Snapshot returns the code, balance, nonce and storage slots of
+	// accounts at a block.
+	Snapshot(ctx context.Context, accounts []common.Address, slots map[common.Address][]common.Hash, block *big.Int) (*StateSnapshot, error)
+	// Extension returns the sub-client calling the rpc methods of
+	// namespace, e.g. chain specific ones.
+	Extension(namespace string) *Extension
+	// GetProof returns the Merkle proofs of account and of its storage
+	// keys at blockNumber.
+	GetProof(ctx context.Context, account common.Address, keys []string, blockNumber *big.Int) (*AccountResult, error)
+	// InspectNonces returns the latest and pending nonces of account on
+	// every endpoint.
+	InspectNonces(ctx context.Context, account common.Address) (*NonceStatus, error)
+	// CancelStuckTransactions replaces the stuck transactions of an account.
+	CancelStuckTransactions(ctx context.Context, status *NonceStatus, sign bind.SignerFn, feeBumpPercent int) ([]*types.Transaction, error)
+	// BackfillLogs passes the logs of q to fn in chunks, checking the logs
+	// around a failover against the new endpoint.
+	BackfillLogs(ctx context.Context, q ethereum.FilterQuery, fn func(from, to uint64, logs []types.Log) error) error
+	// Status returns the routing state and health of the endpoints.
+	Status() Status
+	// StatusSnapshot sums up Status and the last hour of calls, e.g. for a
+	// /status page.
+	StatusSnapshot() StatusSnapshot
+	// NextNonce returns the nonce of the next transaction of account.
+	NextNonce(ctx context.Context, account common.Address) (uint64, error)
+	// SendTransactionWithNonce sends the transaction built with the next
+	// nonce of from.
+	SendTransactionWithNonce(ctx context.Context, from common.Address, build func(nonce uint64) (*types.Transaction, error)) (*types.Transaction, error)
+	// BaseFeeAt returns the base fee of block number, the latest if nil.
+	BaseFeeAt(ctx context.Context, number *big.Int) (*big.Int, error)
+	// RecentBaseFees returns the base fees of the latest blocks.
+	RecentBaseFees() []BlockBaseFee
+	// SendTransactionAndWait sends tx and waits for its receipt.
+	SendTransactionAndWait(ctx context.Context, tx *types.Transaction, opts *WaitOpts) (*types.Receipt, error)
+	// SubscribeCanonicalHeads sends the blocks of the canonical chain, and
+	// those orphaned by reorgs, once they have confirmations on top.
+	SubscribeCanonicalHeads(ctx context.Context, ch chan<- HeadEvent, confirmations uint64) (ethereum.Subscription, error)
+	// FillTransactOpts fills the unset nonce, fees and gas limit of opts.
+	FillTransactOpts(ctx context.Context, opts *bind.TransactOpts, to *common.Address, input []byte) error
+	// Run blocks until ctx is done, then closes the client.
+	Run(ctx context.Context) error
+	// Shutdown rejects new calls but critical ones, waits for the calls in
+	// flight, subscriptions and background loops until ctx is done, then
+	// closes the connections.
+	Shutdown(ctx context.Context) error
+	// Close stops the background goroutines and closes the connections.
+	Close()
+}
+
+var _ Client = (*client)(nil)
+
+// New creates a client for cfg, further customized by opts.
 func New(
 	appName string,
 	chain string,
 	cfg *Config,
+	opts ...Option,
 ) (Client, error) {
-	logger := zerolog.DefaultContextLogger
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if len(o.apply) > 0 {
+		// leave the caller's config untouched
+		cp := *cfg
+		for _, apply := range o.apply {
+			apply(&cp)
+		}
+		cfg = &cp
+	}
+	logger := o.logger
+	if logger == nil {
+		logger = zerolog.DefaultContextLogger
+	}
 	if logger == nil {
 		l := log.With().Caller().Logger()
 		logger = &l
@@ -52,663 +203,769 @@ func New(
 	if err := cfg.Valid(); err != nil {
 		return nil, err
 	}
-	m, err := ethclient.Dial(cfg.RpcUrl)
-	if err != nil {
-		return nil, err
-	}
-	b, err := ethclient.Dial(cfg.FailoverRpcUrl)
-	if err != nil {
-		return nil, err
-	}
+	urls := [2]string{cfg.RpcUrl, cfg.FailoverRpcUrl}
+	var rcs [2]*rpc.Client
+	// honor the Retry-After of 429 responses, count the bytes of responses
+	// and propagate trace contexts, options from the caller take precedence
+	penalties := [2]*penalty{{}, {}}
+	var dialOptions [2][]rpc.ClientOption
+	if o.httpClient == nil && o.pool != nil {
+		o.httpClient = o.pool.httpClient()
+	}
+	auths := [2][]rpc.ClientOption{}
+	auths[0], _ = endpointAuth(cfg.RpcHeaders, cfg.RpcAuthHeader, cfg.RpcJwtSecret)
+	auths[1], _ = endpointAuth(cfg.FailoverRpcHeaders, cfg.FailoverRpcAuthHeader, cfg.FailoverRpcJwtSecret)
+	for i, p := range penalties {
+		hc := o.httpClient
+		if o.endpointHTTPClients[i] != nil {
+			hc = o.endpointHTTPClients[i]
+		}
+		hc = withResponseSizes(withRetryAfter(hc, p))
+		if cfg.PropagateTraceContext {
+			hc = withTraceContext(hc)
+		}
+		dialOptions[i] = append([]rpc.ClientOption{rpc.WithHTTPClient(hc)}, auths[i]...)
+		dialOptions[i] = append(dialOptions[i], o.dialOptions...)
+	}
+	closeAll := func() {
+		for _, rc := range rcs {
+			if rc != nil {
+				o.pool.release(rc)
+			}
+		}
+	}
+	for i := range rcs {
+		if o.rpcClients[i] != nil {
+			rcs[i], urls[i] = o.rpcClients[i], ""
+			continue
+		}
+		if cfg.LazyDial {
+			// dialed on first use
+			continue
+		}
+		rc, err := o.pool.dial(context.Background(), urls[i], dialOptions[i])
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		rcs[i] = rc
+	}
+	m, b := rcs[0], rcs[1]
 	c := client{
-		logger: logger,
-		cfg:    cfg,
-		m:      m,
-		b:      b,
+		logger:      logger,
+		cfg:         cfg,
+		interceptor: chainInterceptors(cfg.Interceptors),
+		tracer:      cfg.Tracer,
+		done:        make(chan struct{}),
+	}
+	if c.tracer == nil {
+		c.tracer = trace.NewNoopTracerProvider().Tracer("")
 	}
 	if cfg.EnablePrometheus {
 		logger.Info().Msgf("enabling rpc metrics")
-		c.metrics = newMetrics(appName, chain)
-		c.metrics.Register()
+		c.metrics = newMetrics(appName, chain, cfg.PrometheusRegisterer, cfg.LatencyBucketsMs)
+		if err := c.metrics.Register(); err != nil {
+			closeAll()
+			return nil, err
+		}
+	}
+	c.m = newEndpoint(cfg.RpcName, urls[0], m, c.metrics)
+	c.b = newEndpoint(cfg.FailoverRpcName, urls[1], b, c.metrics)
+	c.m.dialOptions, c.b.dialOptions = dialOptions[0], dialOptions[1]
+	c.m.pool, c.b.pool = o.pool, o.pool
+	methods := []methodList{
+		{allow: cfg.RpcAllowMethods, deny: cfg.RpcDenyMethods},
+		{allow: cfg.FailoverRpcAllowMethods, deny: cfg.FailoverRpcDenyMethods},
+	}
+	for i, url := range []string{cfg.WsUrl, cfg.FailoverWsUrl} {
+		if url == "" {
+			continue
+		}
+		// dialed on first subscription
+		name := []string{cfg.RpcName, cfg.FailoverRpcName}[i] + "-ws"
+		e := newEndpoint(name, url, nil, c.metrics)
+		e.dialOptions, e.pool = dialOptions[i], o.pool
+		e.methods = methods[i]
+		c.ws = append(c.ws, e)
+	}
+	c.m.penalty, c.b.penalty = penalties[0], penalties[1]
+	c.m.limiter = newTokenBucket(cfg.RpcRateLimit, cfg.RpcRateBurst)
+	c.b.limiter = newTokenBucket(cfg.FailoverRpcRateLimit, cfg.FailoverRpcRateBurst)
+	c.m.stateHistory, c.b.stateHistory = cfg.RpcStateHistoryBlocks, cfg.FailoverRpcStateHistoryBlocks
+	c.m.trace, c.b.trace = cfg.RpcTrace, cfg.FailoverRpcTrace
+	c.m.methods, c.b.methods = methods[0], methods[1]
+	if cfg.ProviderPresets {
+		for _, e := range []*endpoint{c.m, c.b} {
+			if preset, ok := LookupProviderPreset(e.url); ok {
+				logger.Info().Msgf("applying %s provider preset to rpc %s", preset.Name, e.name)
+				e.applyPreset(preset)
+			}
+		}
+	}
+	// before verification, which remembers the chain id
+	if cfg.FailsafeCache {
+		size := cfg.FailsafeCacheSize
+		if size == 0 {
+			size = defaultFailsafeCacheSize
+		}
+		c.failsafe = newResponseCache(size, 0)
+	}
+	if cfg.VerifyChainID && cfg.LazyDial {
+		c.verifyUntilReachable(0, verifyTimeout)
+	} else if cfg.VerifyChainID {
+		ctx, cancel := context.WithTimeout(context.Background(), verifyTimeout)
+		err := c.VerifyEndpoints(ctx)
+		cancel()
+		switch {
+		case errors.Is(err, ErrChainIDMismatch):
+			c.Close()
+			return nil, err
+		case err != nil:
+			// don't refuse to start while an endpoint is down, check it
+			// once it is back
+			c.logger.Warn().Msgf("failed to verify rpc endpoints, retrying in the background: %s", err)
+			if cfg.VerifyChainIDIntervalSec <= 0 {
+				c.verifyUntilReachable(verifyTimeout, verifyTimeout)
+			}
+		}
+	}
+	if cfg.VerifyChainIDIntervalSec > 0 {
+		c.verifyPeriodically(time.Duration(cfg.VerifyChainIDIntervalSec) * time.Second)
+	}
+	if err := c.setupMaintenance(); err != nil {
+		c.Close()
+		return nil, err
+	}
+	if cfg.BaseFeeWindowBlocks > 0 {
+		c.baseFees = newBaseFeeWindow(cfg.BaseFeeWindowBlocks)
+	}
+	if cfg.HeadPollIntervalMs > 0 {
+		c.watchHeads(time.Duration(cfg.HeadPollIntervalMs) * time.Millisecond)
+	}
+	if cfg.IdleConnTimeoutSec > 0 {
+		c.reapIdleConnections(time.Duration(cfg.IdleConnTimeoutSec) * time.Second)
+	}
+	if cfg.LazyDial {
+		c.redialFailed(redialInterval)
+	}
+	if cfg.StatsIntervalSec > 0 {
+		c.stats = newRequestStats()
+		c.exportStats(time.Duration(cfg.StatsIntervalSec) * time.Second)
+	}
+	if cfg.CoalesceCalls {
+		c.calls = &callGroup{calls: make(map[string]*groupCall)}
+	}
+	if cfg.ManageNonces {
+		c.nonces = newNonceManager()
+	}
+	if cfg.CacheSize > 0 {
+		c.cache = newResponseCache(cfg.CacheSize, time.Duration(cfg.CacheTTLSec)*time.Second)
+	}
+	if cfg.ReadAfterWriteGraceSec > 0 {
+		c.pins = newReadPins(time.Duration(cfg.ReadAfterWriteGraceSec) * time.Second)
+	}
+	if cfg.PromotionWindowSec > 0 {
+		c.promoteOnLatency(time.Duration(cfg.PromotionWindowSec)*time.Second, cfg.PromotionLatencyRatio)
+	}
+	if cfg.AdaptiveRouting {
+		interval := time.Duration(cfg.AdaptiveRoutingProbeSec) * time.Second
+		if interval == 0 {
+			interval = defaultAdaptiveProbeInterval
+		}
+		c.probeQuality(interval)
+	}
+	c.observePreference()
+	if o.ctx != nil {
+		// not a background goroutine, Close waits for those
+		go c.Run(o.ctx)
 	}
 	return &c, nil
 }
 
-func (c *client) shouldFailover(err error) bool {
-	if err == context.DeadlineExceeded || err == context.Canceled {
-		return false
-	}
-	return true
+// do runs fn through the configured interceptors and the failover logic.
+func (c *client) do(ctx context.Context, method string, fn func(context.Context, *ethclient.Client) error) error {
+	return c.run(ctx, method, func(ctx context.Context) error {
+		return c.failover(ctx, method, fn)
+	})
 }
 
-func (c *client) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
-	t := time.Now()
-	r, err := c.m.BalanceAt(ctx, account, blockNumber)
-	c.metrics.Observe("BalanceAt", t, c.cfg.RpcName, err == nil)
-
+// do is client.do for calls returning a result, the result of the attempt
+// that succeeded, or the zero value with the error of the call.
+func do[T any](ctx context.Context, c *client, method string, fn func(context.Context, *ethclient.Client) (T, error)) (T, error) {
+	var r T
+	err := c.do(ctx, method, func(ctx context.Context, ec *ethclient.Client) (err error) {
+		r, err = fn(ctx, ec)
+		return err
+	})
 	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
-
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.BalanceAt(ctx, account, blockNumber)
-		c.metrics.Observe("BalanceAt", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
+		var zero T
+		return zero, err
 	}
 	return r, nil
 }
 
-func (c *client) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
-	t := time.Now()
-	r, err := c.m.BlockByHash(ctx, hash)
-	c.metrics.Observe("BlockByHash", t, c.cfg.RpcName, err == nil)
-
+// run wraps a method call, whatever its routing, with tracing and the
+// configured interceptors.
+func (c *client) run(ctx context.Context, method string, call func(context.Context) error) (err error) {
+	leave, err := c.enter(ctx, method)
 	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
+		return err
+	}
+	defer leave()
+	ctx, span := c.startSpan(ctx, "ethclient."+method, attrMethod.String(method))
+	defer func() { endSpan(span, err) }()
 
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.BlockByHash(ctx, hash)
-		c.metrics.Observe("BlockByHash", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
+	if c.interceptor == nil {
+		return call(ctx)
 	}
-	return r, nil
+	return c.interceptor(ctx, method, call)
 }
 
-func (c *client) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
-	t := time.Now()
-	r, err := c.m.BlockByNumber(ctx, number)
-	c.metrics.Observe("BlockByNumber", t, c.cfg.RpcName, err == nil)
-
+// failover runs fn against the main rpc client, retrying according to the
+// method's retry policy, and falls back to the failover rpc client if the
+// main one keeps failing.
+func (c *client) failover(ctx context.Context, method string, fn func(context.Context, *ethclient.Client) error) error {
+	routes, err := c.routeCall(ctx, method)
 	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
+		return err
+	}
+	strict := c.failoverForbidden(ctx, method)
+	if primary := c.endpointsFor(method)[0]; strict && routes[0] != primary {
+		return fmt.Errorf("%w: %s, rpc %s is unavailable", ErrFailoverRequiresOptIn, method, primary.name)
+	}
+	gated := capabilityGated(method)
+	attempts := make([]EndpointAttempt, 0, len(routes))
+	for i, e := range routes {
+		if i > 0 {
+			if !(gated && isMethodNotFound(err)) && !c.shouldFailover(err) {
+				return err
+			}
+			if strict {
+				return fmt.Errorf("%w: %s on rpc %s: %w", ErrFailoverRequiresOptIn, method, routes[i-1].name, err)
+			}
+			// use failover rpc client
+			trace.SpanFromContext(ctx).AddEvent("failover", trace.WithAttributes(
+				attrEndpoint.String(e.name),
+				attrReason.String(err.Error())))
+		}
+		t := time.Now()
+		if i > 0 {
+			c.metrics.ObserveFailover(method, e.name)
+			c.failovers.Add(1)
+			c.failoverWindow.add(t)
+			c.activeFailovers.Add(1)
+		}
+		err = c.try(ctx, method, e, i > 0, fn)
+		if i > 0 {
+			c.activeFailovers.Add(-1)
+		}
+		if err == nil {
+			c.recordOutcome(routes[:i], e, gated)
+			return nil
+		}
+		attempts = append(attempts, EndpointAttempt{Endpoint: e.name, Duration: time.Since(t), Err: err})
+		if gated && isMethodNotFound(err) {
+			c.logger.Info().Msgf("rpc %s doesn't support %s, skipping it for %s", e.name, method, capabilityRecheckInterval)
+			e.markUnsupported(method)
+		}
+	}
+	if gated && isMethodNotFound(err) {
+		return fmt.Errorf("%w: %s: %s", ErrMethodNotSupported, method, err)
+	}
+	if len(attempts) > 1 {
+		return &FailoverError{Method: method, Attempts: attempts}
+	}
+	return err
+}
+
+// route returns the endpoints to try for method, in order. Drained or
+// misconfigured endpoints are skipped unless no other endpoint is left,
+// endpoints lacking a capability gated method are always skipped, as are
+// endpoints not offering trace methods when another one does.
+func (c *client) route(method string) ([]*endpoint, error) {
+	gated := capabilityGated(method)
+	tracers := traceMethods[method] && (c.m.trace || c.b.trace)
+	var routes, fallback []*endpoint
+	var recheck time.Duration
+	for _, e := range c.endpointsFor(method) {
+		if !e.methods.allows(method) || tracers && !e.trace {
+			continue
+		}
+		if gated {
+			if left := e.unsupportedFor(method); left > 0 {
+				if recheck == 0 || left < recheck {
+					recheck = left
+				}
+				continue
+			}
+		}
+		if e.unavailable() {
+			fallback = append(fallback, e)
+			continue
+		}
+		routes = append(routes, e)
+	}
+	if len(routes) == 0 {
+		routes = fallback
+	}
+	if len(routes) == 0 {
+		err := fmt.Errorf("%w: %s", ErrMethodNotSupported, method)
+		if recheck > 0 {
+			// the capability is checked again then
+			return nil, &RetryAfterError{Err: err, RetryAfter: recheck}
 		}
+		return nil, err
+	}
+	return routes, nil
+}
 
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.BlockByNumber(ctx, number)
-		c.metrics.Observe("BlockByNumber", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
+// endpoints returns the endpoints in routing order, primary first.
+func (c *client) endpoints() []*endpoint {
+	if c.promoted.Load() {
+		return []*endpoint{c.b, c.m}
 	}
-	return r, nil
+	return []*endpoint{c.m, c.b}
 }
 
-func (c *client) BlockNumber(ctx context.Context) (uint64, error) {
-	t := time.Now()
-	r, err := c.m.BlockNumber(ctx)
-	c.metrics.Observe("BlockNumber", t, c.cfg.RpcName, err == nil)
+// endpointsFor returns the endpoints serving method in routing order, the
+// websocket endpoints for subscriptions when configured.
+func (c *client) endpointsFor(method string) []*endpoint {
+	if len(c.ws) > 0 && strings.HasPrefix(method, "Subscribe") {
+		return c.ws
+	}
+	return c.endpoints()
+}
 
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
+// allEndpoints returns the endpoints, including the websocket ones.
+func (c *client) allEndpoints() []*endpoint {
+	return append([]*endpoint{c.m, c.b}, c.ws...)
+}
 
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.BlockNumber(ctx)
-		c.metrics.Observe("BlockNumber", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
+func (c *client) shouldFailover(err error) bool {
+	if err == context.DeadlineExceeded || err == context.Canceled {
+		return false
 	}
-	return r, nil
+	// split rather than sent to another endpoint
+	return !errors.Is(err, ErrLogRangeExceeded)
 }
 
-func (c *client) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
-	t := time.Now()
-	r, err := c.m.CallContract(ctx, msg, blockNumber)
-	c.metrics.Observe("CallContract", t, c.cfg.RpcName, err == nil)
+func (c *client) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	r, err := coalesce(ctx, c, "BalanceAt", "BalanceAt:"+account.Hex()+":"+blockKey(blockNumber), func(ctx context.Context) (*big.Int, error) {
+		return c.balanceAt(ctx, account, blockNumber)
+	})
+	return copyBig(r), err
+}
 
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
+func (c *client) balanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	if c.consensusEnabled("BalanceAt") {
+		return consensus(ctx, c, "BalanceAt", blockNumber == nil, func(ctx context.Context, ec *ethclient.Client) (*big.Int, error) {
+			return ec.BalanceAt(ctx, account, blockNumber)
+		}, bigEqual)
+	}
+	if c.freshestEnabled("BalanceAt", blockNumber == nil) {
+		return freshest(ctx, c, "BalanceAt", func(ctx context.Context, ec *ethclient.Client) (*big.Int, error) {
+			return ec.BalanceAt(ctx, account, blockNumber)
+		}, nil)
+	}
+	return do(c.withStateBlock(ctx, blockNumber), c, "BalanceAt", func(ctx context.Context, ec *ethclient.Client) (*big.Int, error) {
+		return ec.BalanceAt(ctx, account, blockNumber)
+	})
+}
+
+func (c *client) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return cached(ctx, c, "BlockByHash", "BlockByHash:"+hash.Hex(), func() (*types.Block, error) {
+		return do(ctx, c, "BlockByHash", func(ctx context.Context, ec *ethclient.Client) (*types.Block, error) {
+			return ec.BlockByHash(ctx, hash)
+		})
+	}, nil)
+}
+
+func (c *client) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	return coalesce(ctx, c, "BlockByNumber", "BlockByNumber:"+blockKey(number), func(ctx context.Context) (*types.Block, error) {
+		return c.blockByNumber(ctx, number)
+	})
+}
 
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.CallContract(ctx, msg, blockNumber)
-		c.metrics.Observe("CallContract", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
+func (c *client) blockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	if c.freshestEnabled("BlockByNumber", number == nil) {
+		return freshest(ctx, c, "BlockByNumber", func(ctx context.Context, ec *ethclient.Client) (*types.Block, error) {
+			r, err := ec.BlockByNumber(ctx, number)
+			return r, c.validate(ctx, err, func() error { return checkNumber(r.Number(), number) })
+		}, blockHeight)
 	}
-	return r, nil
+	return do(c.withLatestRead(ctx, number), c, "BlockByNumber", func(ctx context.Context, ec *ethclient.Client) (*types.Block, error) {
+		r, err := ec.BlockByNumber(ctx, number)
+		return r, c.validate(ctx, err, func() error { return checkNumber(r.Number(), number) })
+	})
 }
 
-func (c *client) CallContractAtHash(ctx context.Context, msg ethereum.CallMsg, blockHash common.Hash) ([]byte, error) {
-	t := time.Now()
-	r, err := c.m.CallContractAtHash(ctx, msg, blockHash)
-	c.metrics.Observe("CallContractAtHash", t, c.cfg.RpcName, err == nil)
+func (c *client) BlockNumber(ctx context.Context) (uint64, error) {
+	return coalesce(ctx, c, "BlockNumber", "BlockNumber", c.blockNumber)
+}
 
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
+func (c *client) blockNumber(ctx context.Context) (uint64, error) {
+	if c.freshestEnabled("BlockNumber", true) {
+		return freshest(ctx, c, "BlockNumber", func(ctx context.Context, ec *ethclient.Client) (uint64, error) {
+			return ec.BlockNumber(ctx)
+		}, identityHeight)
+	}
+	return do(c.withLatestRead(ctx, nil), c, "BlockNumber", func(ctx context.Context, ec *ethclient.Client) (uint64, error) {
+		return ec.BlockNumber(ctx)
+	})
+}
 
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.CallContractAtHash(ctx, msg, blockHash)
-		c.metrics.Observe("CallContractAtHash", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
+func (c *client) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if c.consensusEnabled("CallContract") {
+		return consensus(ctx, c, "CallContract", blockNumber == nil, func(ctx context.Context, ec *ethclient.Client) ([]byte, error) {
+			return ec.CallContract(ctx, msg, blockNumber)
+		}, bytes.Equal)
 	}
-	return r, nil
+	if c.freshestEnabled("CallContract", blockNumber == nil) {
+		return freshest(ctx, c, "CallContract", func(ctx context.Context, ec *ethclient.Client) ([]byte, error) {
+			return ec.CallContract(ctx, msg, blockNumber)
+		}, nil)
+	}
+	return do(c.withStateBlock(ctx, blockNumber), c, "CallContract", func(ctx context.Context, ec *ethclient.Client) ([]byte, error) {
+		return ec.CallContract(ctx, msg, blockNumber)
+	})
 }
 
-func (c *client) ChainID(ctx context.Context) (*big.Int, error) {
-	t := time.Now()
-	r, err := c.m.ChainID(ctx)
-	c.metrics.Observe("ChainID", t, c.cfg.RpcName, err == nil)
+func (c *client) CallContractAtHash(ctx context.Context, msg ethereum.CallMsg, blockHash common.Hash) ([]byte, error) {
+	return do(ctx, c, "CallContractAtHash", func(ctx context.Context, ec *ethclient.Client) ([]byte, error) {
+		return ec.CallContractAtHash(ctx, msg, blockHash)
+	})
+}
 
+func (c *client) ChainID(ctx context.Context) (*big.Int, error) {
+	id, err := cached(ctx, c, "ChainID", "ChainID", func() (*big.Int, error) {
+		return do(ctx, c, "ChainID", func(ctx context.Context, ec *ethclient.Client) (*big.Int, error) {
+			return ec.ChainID(ctx)
+		})
+	}, nil)
 	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
+		return nil, err
+	}
+	// big.Int is mutable, don't share the cached one
+	return new(big.Int).Set(id), nil
+}
 
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.ChainID(ctx)
-		c.metrics.Observe("ChainID", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
+// Run blocks until ctx is done, then closes the client. It returns early,
+// without error, if the client is closed meanwhile. It suits lifecycle
+// managers such as run groups.
+func (c *client) Run(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		c.Close()
+	case <-c.done:
 	}
-	return r, nil
+	return nil
 }
 
 func (c *client) Close() {
-	c.m.Close()
-	c.b.Close()
+	c.stop()
+	c.wg.Wait()
+	c.closeConnections()
 }
 
-func (c *client) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
-	t := time.Now()
-	r, err := c.m.CodeAt(ctx, account, blockNumber)
-	c.metrics.Observe("CodeAt", t, c.cfg.RpcName, err == nil)
+// stop signals the background loops and subscriptions to exit.
+func (c *client) stop() {
+	c.stopOnce.Do(func() {
+		close(c.done)
+	})
+}
 
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
+// closeConnections closes the connections of every endpoint.
+func (c *client) closeConnections() {
+	c.closeOnce.Do(func() {
+		for _, e := range c.allEndpoints() {
+			e.Close()
 		}
+	})
+}
 
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.CodeAt(ctx, account, blockNumber)
-		c.metrics.Observe("CodeAt", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
+func (c *client) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	if blockNumber == nil || !c.finalized(blockNumber) {
+		return c.codeAt(ctx, account, blockNumber)
 	}
-	return r, nil
+	code, err := cached(ctx, c, "CodeAt", "CodeAt:"+account.Hex()+":"+blockNumber.String(), func() ([]byte, error) {
+		return c.codeAt(ctx, account, blockNumber)
+	}, nil)
+	return bytes.Clone(code), err
 }
 
-func (c *client) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
-	t := time.Now()
-	r, err := c.m.EstimateGas(ctx, msg)
-	c.metrics.Observe("EstimateGas", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
-
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.EstimateGas(ctx, msg)
-		c.metrics.Observe("EstimateGas", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
+func (c *client) codeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	if c.consensusEnabled("CodeAt") {
+		return consensus(ctx, c, "CodeAt", blockNumber == nil, func(ctx context.Context, ec *ethclient.Client) ([]byte, error) {
+			return ec.CodeAt(ctx, account, blockNumber)
+		}, bytes.Equal)
 	}
-	return r, nil
+	if c.freshestEnabled("CodeAt", blockNumber == nil) {
+		return freshest(ctx, c, "CodeAt", func(ctx context.Context, ec *ethclient.Client) ([]byte, error) {
+			return ec.CodeAt(ctx, account, blockNumber)
+		}, nil)
+	}
+	return do(c.withStateBlock(ctx, blockNumber), c, "CodeAt", func(ctx context.Context, ec *ethclient.Client) ([]byte, error) {
+		return ec.CodeAt(ctx, account, blockNumber)
+	})
 }
 
-func (c *client) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
-	t := time.Now()
-	r, err := c.m.FilterLogs(ctx, q)
-	c.metrics.Observe("FilterLogs", t, c.cfg.RpcName, err == nil)
+func (c *client) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return do(ctx, c, "EstimateGas", func(ctx context.Context, ec *ethclient.Client) (uint64, error) {
+		return ec.EstimateGas(ctx, msg)
+	})
+}
 
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
+func (c *client) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return do(ctx, c, "FeeHistory", func(ctx context.Context, ec *ethclient.Client) (*ethereum.FeeHistory, error) {
+		return c.feeHistory(ctx, ec, blockCount, lastBlock, rewardPercentiles)
+	})
+}
+
+// BlobBaseFee returns the blob base fee of the next block. Endpoints that
+// predate EIP-4844 are skipped, ErrMethodNotSupported is returned if none
+// supports it.
+func (c *client) BlobBaseFee(ctx context.Context) (*big.Int, error) {
+	return do(ctx, c, "BlobBaseFee", func(ctx context.Context, _ *ethclient.Client) (*big.Int, error) {
+		var fee hexutil.Big
+		if err := rpcClientFromContext(ctx).CallContext(ctx, &fee, "eth_blobBaseFee"); err != nil {
+			return nil, err
 		}
+		return (*big.Int)(&fee), nil
+	})
+}
 
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.FilterLogs(ctx, q)
-		c.metrics.Observe("FilterLogs", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
+func (c *client) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	if c.cfg.SplitLogQueries && q.BlockHash == nil {
+		return c.filterLogsSplit(ctx, q)
 	}
-	return r, nil
+	return c.filterLogs(ctx, q)
 }
 
-func (c *client) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
-	t := time.Now()
-	r, err := c.m.HeaderByHash(ctx, hash)
-	c.metrics.Observe("HeaderByHash", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
+func (c *client) filterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	return do(ctx, c, "FilterLogs", func(ctx context.Context, ec *ethclient.Client) ([]types.Log, error) {
+		r, err := ec.FilterLogs(ctx, q)
+		if c.cfg.SplitLogQueries && IsLogRangeError(err) {
+			return nil, fmt.Errorf("%w: %w", ErrLogRangeExceeded, err)
 		}
+		return r, c.validate(ctx, err, func() error { return checkLogs(r, q) })
+	})
+}
 
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.HeaderByHash(ctx, hash)
-		c.metrics.Observe("HeaderByHash", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
-	}
-	return r, nil
+func (c *client) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return cached(ctx, c, "HeaderByHash", "HeaderByHash:"+hash.Hex(), func() (*types.Header, error) {
+		return do(ctx, c, "HeaderByHash", func(ctx context.Context, ec *ethclient.Client) (*types.Header, error) {
+			return ec.HeaderByHash(ctx, hash)
+		})
+	}, nil)
 }
 
 func (c *client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
-	t := time.Now()
-	r, err := c.m.HeaderByNumber(ctx, number)
-	c.metrics.Observe("HeaderByNumber", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
+	return coalesce(ctx, c, "HeaderByNumber", "HeaderByNumber:"+blockKey(number), func(ctx context.Context) (*types.Header, error) {
+		return c.headerByNumber(ctx, number)
+	})
+}
 
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.HeaderByNumber(ctx, number)
-		c.metrics.Observe("HeaderByNumber", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
+func (c *client) headerByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if c.freshestEnabled("HeaderByNumber", number == nil) {
+		return freshest(ctx, c, "HeaderByNumber", func(ctx context.Context, ec *ethclient.Client) (*types.Header, error) {
+			r, err := ec.HeaderByNumber(ctx, number)
+			return r, c.validate(ctx, err, func() error { return checkNumber(r.Number, number) })
+		}, headerHeight)
 	}
-	return r, nil
+	return do(c.withLatestRead(ctx, number), c, "HeaderByNumber", func(ctx context.Context, ec *ethclient.Client) (*types.Header, error) {
+		r, err := ec.HeaderByNumber(ctx, number)
+		return r, c.validate(ctx, err, func() error { return checkNumber(r.Number, number) })
+	})
 }
 
 func (c *client) NetworkID(ctx context.Context) (*big.Int, error) {
-	t := time.Now()
-	r, err := c.m.NetworkID(ctx)
-	c.metrics.Observe("NetworkID", t, c.cfg.RpcName, err == nil)
-
+	r, err := do(ctx, c, "NetworkID", func(ctx context.Context, ec *ethclient.Client) (*big.Int, error) {
+		return ec.NetworkID(ctx)
+	})
 	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
+		if r, err = failsafe[*big.Int](ctx, c, "NetworkID", "NetworkID", err); err != nil {
+			return nil, err
 		}
-
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.NetworkID(ctx)
-		c.metrics.Observe("NetworkID", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
+		return new(big.Int).Set(r), nil
 	}
+	c.remember("NetworkID", new(big.Int).Set(r))
 	return r, nil
 }
 
 func (c *client) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
-	t := time.Now()
-	r, err := c.m.NonceAt(ctx, account, blockNumber)
-	c.metrics.Observe("NonceAt", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
-
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.NonceAt(ctx, account, blockNumber)
-		c.metrics.Observe("NonceAt", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
+	if c.consensusEnabled("NonceAt") {
+		return consensus(ctx, c, "NonceAt", blockNumber == nil, func(ctx context.Context, ec *ethclient.Client) (uint64, error) {
+			return ec.NonceAt(ctx, account, blockNumber)
+		}, uint64Equal)
 	}
-	return r, nil
+	if c.freshestEnabled("NonceAt", blockNumber == nil) {
+		return freshest(ctx, c, "NonceAt", func(ctx context.Context, ec *ethclient.Client) (uint64, error) {
+			return ec.NonceAt(ctx, account, blockNumber)
+		}, nil)
+	}
+	return do(c.withStateBlock(ctx, blockNumber), c, "NonceAt", func(ctx context.Context, ec *ethclient.Client) (uint64, error) {
+		return ec.NonceAt(ctx, account, blockNumber)
+	})
 }
 
 func (c *client) PeerCount(ctx context.Context) (uint64, error) {
-	t := time.Now()
-	r, err := c.m.PeerCount(ctx)
-	c.metrics.Observe("PeerCount", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
-
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.PeerCount(ctx)
-		c.metrics.Observe("PeerCount", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
-	}
-	return r, nil
+	return do(ctx, c, "PeerCount", func(ctx context.Context, ec *ethclient.Client) (uint64, error) {
+		return ec.PeerCount(ctx)
+	})
 }
 
 func (c *client) PendingBalanceAt(ctx context.Context, account common.Address) (*big.Int, error) {
-	t := time.Now()
-	r, err := c.m.PendingBalanceAt(ctx, account)
-	c.metrics.Observe("PendingBalanceAt", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
-
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.PendingBalanceAt(ctx, account)
-		c.metrics.Observe("PendingBalanceAt", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
-	}
-	return r, nil
+	return do(ctx, c, "PendingBalanceAt", func(ctx context.Context, ec *ethclient.Client) (*big.Int, error) {
+		return ec.PendingBalanceAt(ctx, account)
+	})
 }
 
 func (c *client) PendingCallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
-	t := time.Now()
-	r, err := c.m.PendingCallContract(ctx, msg)
-	c.metrics.Observe("PendingCallContract", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
-
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.PendingCallContract(ctx, msg)
-		c.metrics.Observe("PendingCallContract", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
-	}
-	return r, nil
+	return do(ctx, c, "PendingCallContract", func(ctx context.Context, ec *ethclient.Client) ([]byte, error) {
+		return ec.PendingCallContract(ctx, msg)
+	})
 }
 
 func (c *client) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
-	t := time.Now()
-	r, err := c.m.PendingCodeAt(ctx, account)
-	c.metrics.Observe("PendingCodeAt", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
-
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.PendingCodeAt(ctx, account)
-		c.metrics.Observe("PendingCodeAt", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
-	}
-	return r, nil
+	return do(ctx, c, "PendingCodeAt", func(ctx context.Context, ec *ethclient.Client) ([]byte, error) {
+		return ec.PendingCodeAt(ctx, account)
+	})
 }
 
 func (c *client) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
-	t := time.Now()
-	r, err := c.m.PendingNonceAt(ctx, account)
-	c.metrics.Observe("PendingNonceAt", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
-
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.PendingNonceAt(ctx, account)
-		c.metrics.Observe("PendingNonceAt", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
-	}
-	return r, nil
+	return do(c.withPin(ctx, account), c, "PendingNonceAt", func(ctx context.Context, ec *ethclient.Client) (uint64, error) {
+		return ec.PendingNonceAt(ctx, account)
+	})
 }
 
 func (c *client) PendingStorageAt(ctx context.Context, account common.Address, key common.Hash) ([]byte, error) {
-	t := time.Now()
-	r, err := c.m.PendingStorageAt(ctx, account, key)
-	c.metrics.Observe("PendingStorageAt", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
-
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.PendingStorageAt(ctx, account, key)
-		c.metrics.Observe("PendingStorageAt", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
-	}
-	return r, nil
+	return do(ctx, c, "PendingStorageAt", func(ctx context.Context, ec *ethclient.Client) ([]byte, error) {
+		return ec.PendingStorageAt(ctx, account, key)
+	})
 }
 
 func (c *client) PendingTransactionCount(ctx context.Context) (uint, error) {
-	t := time.Now()
-	r, err := c.m.PendingTransactionCount(ctx)
-	c.metrics.Observe("PendingTransactionCount", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
-
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.PendingTransactionCount(ctx)
-		c.metrics.Observe("PendingTransactionCount", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
-	}
-	return r, nil
+	return do(ctx, c, "PendingTransactionCount", func(ctx context.Context, ec *ethclient.Client) (uint, error) {
+		return ec.PendingTransactionCount(ctx)
+	})
 }
 
+// SendTransaction checks that the chain accepts the type of tx before
+// sending it, see RegisterTxTypes. With Config.ReadAfterWriteGraceSec set,
+// reads of tx and of its sender's pending nonce are then pinned to the
+// endpoint that accepted it.
 func (c *client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
-	t := time.Now()
-	err := c.m.SendTransaction(ctx, tx)
-	c.metrics.Observe("SendTransaction", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return err
-		}
-
-		// use failover rpc client
-		t = time.Now()
-		err = c.b.SendTransaction(ctx, tx)
-		c.metrics.Observe("SendTransaction", t, c.cfg.FailoverRpcName, err == nil)
+	chainID := c.chainID.Load()
+	if chainID == nil {
+		chainID = tx.ChainId()
+	}
+	if err := checkTxType(chainID, tx); err != nil {
 		return err
 	}
-	return nil
+	err := c.do(ctx, "SendTransaction", func(ctx context.Context, ec *ethclient.Client) error {
+		if err := ec.SendTransaction(ctx, tx); err != nil {
+			return err
+		}
+		c.pins.pinTx(tx, endpointFromContext(ctx))
+		return nil
+	})
+	c.nonces.observeSend(ctx, tx, err)
+	return err
 }
 
 func (c *client) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
-	t := time.Now()
-	r, err := c.m.StorageAt(ctx, account, key, blockNumber)
-	c.metrics.Observe("StorageAt", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
-
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.StorageAt(ctx, account, key, blockNumber)
-		c.metrics.Observe("StorageAt", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
+	if c.consensusEnabled("StorageAt") {
+		return consensus(ctx, c, "StorageAt", blockNumber == nil, func(ctx context.Context, ec *ethclient.Client) ([]byte, error) {
+			return ec.StorageAt(ctx, account, key, blockNumber)
+		}, bytes.Equal)
 	}
-	return r, nil
-}
-
-func (c *client) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
-	t := time.Now()
-	r, err := c.m.SubscribeFilterLogs(ctx, q, ch)
-	c.metrics.Observe("SubscribeFilterLogs", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
-
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.SubscribeFilterLogs(ctx, q, ch)
-		c.metrics.Observe("SubscribeFilterLogs", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
+	if c.freshestEnabled("StorageAt", blockNumber == nil) {
+		return freshest(ctx, c, "StorageAt", func(ctx context.Context, ec *ethclient.Client) ([]byte, error) {
+			return ec.StorageAt(ctx, account, key, blockNumber)
+		}, nil)
 	}
-	return r, nil
+	return do(c.withStateBlock(ctx, blockNumber), c, "StorageAt", func(ctx context.Context, ec *ethclient.Client) ([]byte, error) {
+		return ec.StorageAt(ctx, account, key, blockNumber)
+	})
 }
 
-func (c *client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
-	t := time.Now()
-	r, err := c.m.SubscribeNewHead(ctx, ch)
-	c.metrics.Observe("SubscribeNewHead", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
+func (c *client) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return do(ctx, c, "SubscribeFilterLogs", func(ctx context.Context, ec *ethclient.Client) (ethereum.Subscription, error) {
+		sub, err := ec.SubscribeFilterLogs(ctx, q, ch)
+		if err != nil {
+			return nil, err
 		}
-
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.SubscribeNewHead(ctx, ch)
-		c.metrics.Observe("SubscribeNewHead", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
-	}
-	return r, nil
+		return c.trackSubscription(ctx, endpointFromContext(ctx), sub), nil
+	})
 }
 
 func (c *client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
-	t := time.Now()
-	r, err := c.m.SuggestGasPrice(ctx)
-	c.metrics.Observe("SuggestGasPrice", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
-
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.SuggestGasPrice(ctx)
-		c.metrics.Observe("SuggestGasPrice", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
-	}
-	return r, nil
+	r, err := coalesce(ctx, c, "SuggestGasPrice", "SuggestGasPrice", func(ctx context.Context) (*big.Int, error) {
+		return do(ctx, c, "SuggestGasPrice", func(ctx context.Context, ec *ethclient.Client) (*big.Int, error) {
+			return ec.SuggestGasPrice(ctx)
+		})
+	})
+	return copyBig(r), err
 }
 
 func (c *client) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
-	t := time.Now()
-	r, err := c.m.SuggestGasTipCap(ctx)
-	c.metrics.Observe("SuggestGasTipCap", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
-
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.SuggestGasTipCap(ctx)
-		c.metrics.Observe("SuggestGasTipCap", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
-	}
-	return r, nil
+	r, err := coalesce(ctx, c, "SuggestGasTipCap", "SuggestGasTipCap", func(ctx context.Context) (*big.Int, error) {
+		return do(ctx, c, "SuggestGasTipCap", func(ctx context.Context, ec *ethclient.Client) (*big.Int, error) {
+			return c.suggestGasTipCap(ctx, ec)
+		})
+	})
+	return copyBig(r), err
 }
 
 func (c *client) SyncProgress(ctx context.Context) (*ethereum.SyncProgress, error) {
-	t := time.Now()
-	r, err := c.m.SyncProgress(ctx)
-	c.metrics.Observe("SyncProgress", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
-
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.SyncProgress(ctx)
-		c.metrics.Observe("SyncProgress", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
-	}
-	return r, nil
+	return do(ctx, c, "SyncProgress", func(ctx context.Context, ec *ethclient.Client) (*ethereum.SyncProgress, error) {
+		return ec.SyncProgress(ctx)
+	})
 }
 
 func (c *client) TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error) {
-	t := time.Now()
-	r1, r2, err := c.m.TransactionByHash(ctx, hash)
-	c.metrics.Observe("BalanceAtTransactionByHash", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r1, r2, err
-		}
-
-		// use failover rpc client
-		t = time.Now()
-		r1, r2, err = c.b.TransactionByHash(ctx, hash)
-		c.metrics.Observe("BalanceAtTransactionByHash", t, c.cfg.FailoverRpcName, err == nil)
-		return r1, r2, err
-	}
-	return r1, r2, nil
+	err = c.do(c.withPin(ctx, hash), "TransactionByHash", func(ctx context.Context, ec *ethclient.Client) error {
+		tx, isPending, err = ec.TransactionByHash(ctx, hash)
+		return err
+	})
+	return tx, isPending, err
 }
 
 func (c *client) TransactionCount(ctx context.Context, blockHash common.Hash) (uint, error) {
-	t := time.Now()
-	r, err := c.m.TransactionCount(ctx, blockHash)
-	c.metrics.Observe("TransactionCount", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
-
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.TransactionCount(ctx, blockHash)
-		c.metrics.Observe("TransactionCount", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
-	}
-	return r, nil
+	return do(ctx, c, "TransactionCount", func(ctx context.Context, ec *ethclient.Client) (uint, error) {
+		return ec.TransactionCount(ctx, blockHash)
+	})
 }
 
 func (c *client) TransactionInBlock(ctx context.Context, blockHash common.Hash, index uint) (*types.Transaction, error) {
-	t := time.Now()
-	r, err := c.m.TransactionInBlock(ctx, blockHash, index)
-	c.metrics.Observe("TransactionInBlock", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
-
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.TransactionInBlock(ctx, blockHash, index)
-		c.metrics.Observe("TransactionInBlock", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
-	}
-	return r, nil
+	return do(ctx, c, "TransactionInBlock", func(ctx context.Context, ec *ethclient.Client) (*types.Transaction, error) {
+		return ec.TransactionInBlock(ctx, blockHash, index)
+	})
 }
 
 func (c *client) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
-	t := time.Now()
-	r, err := c.m.TransactionReceipt(ctx, txHash)
-	c.metrics.Observe("TransactionReceipt", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
+	return cached(ctx, c, "TransactionReceipt", "TransactionReceipt:"+txHash.Hex(), func() (*types.Receipt, error) {
+		return c.transactionReceipt(ctx, txHash)
+	}, func(r *types.Receipt) bool {
+		return c.finalized(r.BlockNumber)
+	})
+}
 
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.TransactionReceipt(ctx, txHash)
-		c.metrics.Observe("TransactionReceipt", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
+func (c *client) transactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if c.consensusEnabled("TransactionReceipt") {
+		return consensus(ctx, c, "TransactionReceipt", true, func(ctx context.Context, ec *ethclient.Client) (*types.Receipt, error) {
+			r, err := ec.TransactionReceipt(ctx, txHash)
+			return r, c.validate(ctx, err, func() error { return checkReceipt(r, txHash) })
+		}, receiptEqual)
 	}
-	return r, nil
+	return do(c.withPin(ctx, txHash), c, "TransactionReceipt", func(ctx context.Context, ec *ethclient.Client) (*types.Receipt, error) {
+		r, err := ec.TransactionReceipt(ctx, txHash)
+		return r, c.validate(ctx, err, func() error { return checkReceipt(r, txHash) })
+	})
 }
 
 func (c *client) TransactionSender(ctx context.Context, tx *types.Transaction, block common.Hash, index uint) (common.Address, error) {
-	t := time.Now()
-	r, err := c.m.TransactionSender(ctx, tx, block, index)
-	c.metrics.Observe("TransactionSender", t, c.cfg.RpcName, err == nil)
-
-	if err != nil {
-		if !c.shouldFailover(err) {
-			return r, err
-		}
-
-		// use failover rpc client
-		t = time.Now()
-		r, err = c.b.TransactionSender(ctx, tx, block, index)
-		c.metrics.Observe("TransactionSender", t, c.cfg.FailoverRpcName, err == nil)
-		return r, err
-	}
-	return r, nil
+	return do(ctx, c, "TransactionSender", func(ctx context.Context, ec *ethclient.Client) (common.Address, error) {
+		return ec.TransactionSender(ctx, tx, block, index)
+	})
 }
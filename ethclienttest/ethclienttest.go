@@ -0,0 +1,43 @@
+// Package ethclienttest provides test doubles of ethclient.Client for the
+// packages using it: Mock, with a function per method, and Node, a fake
+// endpoint serving canned chain data, which a real client created with
+// NewClient can fail over from and to, to test failover end-to-end.
+package ethclienttest
+
+//go:generate go run ./internal/mockgen mock.go
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNotMocked is returned by the methods of Mock without a function.
+var ErrNotMocked = errors.New("ethclienttest: method not mocked")
+
+func notMocked(method string) error {
+	return fmt.Errorf("%w: %s", ErrNotMocked, method)
+}
+
+// calls counts the calls of each method of a Mock.
+type calls struct {
+	mu sync.Mutex
+	n  map[string]int
+}
+
+func (m *Mock) record(method string) {
+	m.calls.mu.Lock()
+	defer m.calls.mu.Unlock()
+	if m.calls.n == nil {
+		m.calls.n = make(map[string]int)
+	}
+	m.calls.n[method]++
+}
+
+// Calls returns the number of calls of method, e.g. "BlockNumber", whether
+// its function is set or not.
+func (m *Mock) Calls(method string) int {
+	m.calls.mu.Lock()
+	defer m.calls.mu.Unlock()
+	return m.calls.n[method]
+}
@@ -0,0 +1,119 @@
+package ethclienttest
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestMock(t *testing.T) {
+	m := &Mock{ApproxHeadFunc: func() uint64 { return 7 }}
+	if n := m.ApproxHead(); n != 7 {
+		t.Fatalf("ApproxHead = %d, want 7", n)
+	}
+	if _, err := m.BalanceAt(context.Background(), common.Address{}, nil); !errors.Is(err, ErrNotMocked) {
+		t.Fatalf("BalanceAt error = %v, want ErrNotMocked", err)
+	}
+	if m.Calls("ApproxHead") != 1 || m.Calls("BalanceAt") != 1 || m.Calls("Close") != 0 {
+		t.Fatalf("calls = %v", m.calls.n)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	primary, failover := NewNode(t, 1), NewNode(t, 1)
+	account := common.Address{1}
+	primary.SetBalance(account, big.NewInt(1))
+	failover.SetBalance(account, big.NewInt(2))
+	c := NewClient(t, primary, failover)
+	ctx := context.Background()
+
+	if b, err := c.BalanceAt(ctx, account, nil); err != nil || b.Int64() != 1 {
+		t.Fatalf("BalanceAt = %v, %v, want the primary's", b, err)
+	}
+	primary.SetError("eth_getBalance", errors.New("internal error"))
+	if b, err := c.BalanceAt(ctx, account, nil); err != nil || b.Int64() != 2 {
+		t.Fatalf("BalanceAt = %v, %v, want the failover's", b, err)
+	}
+	primary.SetError("eth_getBalance", nil)
+
+	restore := Outage(t, primary)
+	if _, err := c.BalanceAt(ctx, account, nil); err != nil {
+		t.Fatalf("BalanceAt during the outage: %v", err)
+	}
+	if primary.Calls("eth_getBalance") != 2 || failover.Calls("eth_getBalance") != 2 {
+		t.Fatalf("eth_getBalance calls = %d, %d, want 2, 2", primary.Calls("eth_getBalance"), failover.Calls("eth_getBalance"))
+	}
+	restore()
+}
+
+func TestNodeChain(t *testing.T) {
+	primary, failover := NewNode(t, 1), NewNode(t, 1)
+	c := NewClient(t, primary, failover)
+	ctx := context.Background()
+
+	key, _ := crypto.GenerateKey()
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	tx := types.MustSignNewTx(key, signer, &types.DynamicFeeTx{ChainID: big.NewInt(1), Gas: 21000, GasFeeCap: big.NewInt(2e9), GasTipCap: big.NewInt(1e9)})
+	if err := c.SendTransaction(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	b := primary.AddBlock()
+	if h, err := c.HeaderByNumber(ctx, nil); err != nil || h.Number.Uint64() != 1 {
+		t.Fatalf("HeaderByNumber = %v, %v, want block 1", h, err)
+	}
+	got, err := c.BlockByNumber(ctx, big.NewInt(1))
+	if err != nil || got.Hash() != b.Hash() || len(got.Transactions()) != 1 {
+		t.Fatalf("BlockByNumber = %v, %v, want the block with the sent transaction", got, err)
+	}
+	r, err := c.TransactionReceipt(ctx, tx.Hash())
+	if err != nil || r.Status != types.ReceiptStatusSuccessful || r.BlockHash != b.Hash() {
+		t.Fatalf("TransactionReceipt = %v, %v", r, err)
+	}
+	if rs, err := c.BlockReceipts(ctx, rpc.BlockNumberOrHashWithHash(b.Hash(), false)); err != nil || len(rs) != 1 || rs[0].TxHash != tx.Hash() {
+		t.Fatalf("BlockReceipts = %v, %v", rs, err)
+	}
+
+	primary.SetLatency("", 50*time.Millisecond)
+	start := time.Now()
+	if _, err := c.HeaderByNumber(ctx, nil); err != nil || time.Since(start) < 50*time.Millisecond {
+		t.Fatalf("HeaderByNumber = %v after %s, want the latency", err, time.Since(start))
+	}
+}
+
+func TestSubscriptionFailover(t *testing.T) {
+	primary, failover := NewNode(t, 1), NewNode(t, 1)
+	c := NewClient(t, primary, failover)
+
+	ch := make(chan *types.Header, 1)
+	sub, err := c.SubscribeNewHead(context.Background(), ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	Outage(t, primary)
+
+	// heads of the failover arrive once subscribed again
+	deadline := time.After(5 * time.Second)
+	for {
+		b := failover.AddBlock()
+		select {
+		case h := <-ch:
+			if h.Hash() != b.Hash() {
+				t.Fatalf("got head %s, want %s", h.Hash(), b.Hash())
+			}
+			return
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(20 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no head of the failover")
+		}
+	}
+}
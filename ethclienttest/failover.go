@@ -0,0 +1,56 @@
+package ethclienttest
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	ethclient "github.com/oyyblin/failover-ethclient"
+)
+
+// Names of the endpoints of Config, as in Status and metrics.
+const (
+	PrimaryName  = "primary"
+	FailoverName = "failover"
+)
+
+// Config returns the config of a client of the primary and failover nodes,
+// over HTTP and websocket, without retries so that a call failing on the
+// primary goes straight to the failover.
+func Config(primary, failover *Node) *ethclient.Config {
+	return &ethclient.Config{
+		RpcUrl:                primary.URL(),
+		RpcName:               PrimaryName,
+		FailoverRpcUrl:        failover.URL(),
+		FailoverRpcName:       FailoverName,
+		WsUrl:                 primary.WSURL(),
+		FailoverWsUrl:         failover.WSURL(),
+		VerifyChainID:         true,
+		RetryMaxAttempts:      1,
+		RetryInitialBackoffMs: 100,
+		RetryMaxBackoffMs:     2000,
+		ResubscribeDelayMs:    10,
+	}
+}
+
+// NewClient creates a client of Config(primary, failover), customized by
+// opts, and closes it when the test ends. Its logs are discarded unless
+// opts set a logger.
+func NewClient(t testing.TB, primary, failover *Node, opts ...ethclient.Option) ethclient.Client {
+	t.Helper()
+	logger := zerolog.Nop()
+	c, err := ethclient.New("test", "test", Config(primary, failover), append([]ethclient.Option{ethclient.WithLogger(&logger)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+// Outage takes n down, as by Down, until the test ends or the returned
+// function is called.
+func Outage(t testing.TB, n *Node) (restore func()) {
+	n.Down()
+	t.Cleanup(n.Up)
+	return n.Up
+}
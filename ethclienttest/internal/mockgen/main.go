@@ -0,0 +1,171 @@
+// Command mockgen generates the Mock of ethclienttest from the methods of
+// ethclient.Client, see go:generate in mock.go.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	ethclient "github.com/oyyblin/failover-ethclient"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("usage: mockgen <output file>")
+	}
+	t := reflect.TypeOf((*ethclient.Client)(nil)).Elem()
+	imports := map[string]string{"context": "context", "github.com/oyyblin/failover-ethclient": "ethclient"}
+	var fields, methods bytes.Buffer
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		var params, args, results []string
+		for j := 0; j < m.Type.NumIn(); j++ {
+			in := m.Type.In(j)
+			name := fmt.Sprintf("a%d", j)
+			typ := typeName(in, imports)
+			if typ == "context.Context" {
+				name = "ctx"
+			}
+			if m.Type.IsVariadic() && j == m.Type.NumIn()-1 {
+				typ = "..." + typeName(in.Elem(), imports)
+				args = append(args, name+"...")
+			} else {
+				args = append(args, name)
+			}
+			params = append(params, name+" "+typ)
+		}
+		for j := 0; j < m.Type.NumOut(); j++ {
+			results = append(results, typeName(m.Type.Out(j), imports))
+		}
+		sig := fmt.Sprintf("func(%s)", strings.Join(params, ", "))
+		if len(results) == 1 {
+			sig += " " + results[0]
+		} else if len(results) > 1 {
+			sig += " (" + strings.Join(results, ", ") + ")"
+		}
+		fmt.Fprintf(&fields, "\t%sFunc %s\n", m.Name, sig)
+
+		fmt.Fprintf(&methods, "\nfunc (m *Mock) %s%s {\n", m.Name, strings.TrimPrefix(sig, "func"))
+		fmt.Fprintf(&methods, "\tm.record(%q)\n", m.Name)
+		fmt.Fprintf(&methods, "\tif m.%sFunc != nil {\n", m.Name)
+		call := fmt.Sprintf("m.%sFunc(%s)", m.Name, strings.Join(args, ", "))
+		if len(results) == 0 {
+			fmt.Fprintf(&methods, "\t\t%s\n\t}\n}\n", call)
+			continue
+		}
+		fmt.Fprintf(&methods, "\t\treturn %s\n\t}\n", call)
+		var zeros []string
+		for j, r := range results {
+			switch {
+			case r == "error":
+				zeros = append(zeros, fmt.Sprintf("notMocked(%q)", m.Name))
+			default:
+				zeros = append(zeros, fmt.Sprintf("r%d", j))
+				fmt.Fprintf(&methods, "\tvar r%d %s\n", j, r)
+			}
+		}
+		fmt.Fprintf(&methods, "\treturn %s\n}\n", strings.Join(zeros, ", "))
+	}
+
+	paths := make([]string, 0, len(imports))
+	for p := range imports {
+		paths = append(paths, p)
+	}
+	// standard library first
+	sort.Slice(paths, func(i, j int) bool {
+		if si, sj := !strings.Contains(paths[i], "."), !strings.Contains(paths[j], "."); si != sj {
+			return si
+		}
+		return paths[i] < paths[j]
+	})
+	var b bytes.Buffer
+	b.WriteString("// Code generated by ethclienttest/internal/mockgen. DO NOT EDIT.\n\npackage ethclienttest\n\nimport (\n")
+	for i, p := range paths {
+		if i > 0 && !strings.Contains(paths[i-1], ".") && strings.Contains(p, ".") {
+			b.WriteString("\n")
+		}
+		if name := imports[p]; path.Base(p) != name {
+			fmt.Fprintf(&b, "\t%s %q\n", name, p)
+		} else {
+			fmt.Fprintf(&b, "\t%q\n", p)
+		}
+	}
+	b.WriteString(")\n\n")
+	b.WriteString("// Mock implements ethclient.Client with a function per method. Methods\n")
+	b.WriteString("// without their function set return zero values and ErrNotMocked.\n")
+	b.WriteString("type Mock struct {\n\tcalls calls\n\n")
+	b.Write(fields.Bytes())
+	b.WriteString("}\n\nvar _ ethclient.Client = (*Mock)(nil)\n")
+	b.Write(methods.Bytes())
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatalf("formatting mock: %s\n%s", err, b.Bytes())
+	}
+	if err := os.WriteFile(os.Args[1], src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// typeName returns the name of t as written in the ethclienttest package,
+// adding the packages it refers to to imports.
+func typeName(t reflect.Type, imports map[string]string) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name()
+		}
+		imports[t.PkgPath()] = strings.SplitN(t.String(), ".", 2)[0]
+		return t.String()
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return "*" + typeName(t.Elem(), imports)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "[]byte"
+		}
+		return "[]" + typeName(t.Elem(), imports)
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), typeName(t.Elem(), imports))
+	case reflect.Map:
+		return fmt.Sprintf("map[%s]%s", typeName(t.Key(), imports), typeName(t.Elem(), imports))
+	case reflect.Chan:
+		switch t.ChanDir() {
+		case reflect.SendDir:
+			return "chan<- " + typeName(t.Elem(), imports)
+		case reflect.RecvDir:
+			return "<-chan " + typeName(t.Elem(), imports)
+		}
+		return "chan " + typeName(t.Elem(), imports)
+	case reflect.Func:
+		var in, out []string
+		for i := 0; i < t.NumIn(); i++ {
+			in = append(in, typeName(t.In(i), imports))
+		}
+		if t.IsVariadic() {
+			in[len(in)-1] = "..." + typeName(t.In(t.NumIn()-1).Elem(), imports)
+		}
+		for i := 0; i < t.NumOut(); i++ {
+			out = append(out, typeName(t.Out(i), imports))
+		}
+		s := "func(" + strings.Join(in, ", ") + ")"
+		if len(out) == 1 {
+			return s + " " + out[0]
+		} else if len(out) > 1 {
+			return s + " (" + strings.Join(out, ", ") + ")"
+		}
+		return s
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "interface{}"
+		}
+	}
+	log.Fatalf("unsupported type %s", t)
+	return ""
+}
@@ -0,0 +1,614 @@
+// Code generated by ethclienttest/internal/mockgen. DO NOT EDIT.
+
+package ethclienttest
+
+import (
+	"context"
+	"math/big"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	ethclient "github.com/oyyblin/failover-ethclient"
+)
+
+// Mock implements ethclient.Client with a function per method. Methods
+// without their function set return zero values and ErrNotMocked.
+type Mock struct {
+	calls calls
+
+	ApproxHeadFunc               func() uint64
+	BackfillLogsFunc             func(ctx context.Context, a1 ethereum.FilterQuery, a2 func(uint64, uint64, []types.Log) error) error
+	BalanceAtFunc                func(ctx context.Context, a1 common.Address, a2 *big.Int) (*big.Int, error)
+	BaseFeeAtFunc                func(ctx context.Context, a1 *big.Int) (*big.Int, error)
+	BatchBalanceAtFunc           func(ctx context.Context, a1 []common.Address, a2 *big.Int) ([]*big.Int, error)
+	BatchCallFunc                func(ctx context.Context, a1 []rpc.BatchElem) error
+	BatchTransactionReceiptFunc  func(ctx context.Context, a1 []common.Hash) ([]*types.Receipt, error)
+	BlobBaseFeeFunc              func(ctx context.Context) (*big.Int, error)
+	BlockByHashFunc              func(ctx context.Context, a1 common.Hash) (*types.Block, error)
+	BlockByNumberFunc            func(ctx context.Context, a1 *big.Int) (*types.Block, error)
+	BlockReceiptsFunc            func(ctx context.Context, a1 rpc.BlockNumberOrHash) ([]*types.Receipt, error)
+	CallContractFunc             func(ctx context.Context, a1 ethereum.CallMsg, a2 *big.Int) ([]byte, error)
+	CallContractAtHashFunc       func(ctx context.Context, a1 ethereum.CallMsg, a2 common.Hash) ([]byte, error)
+	CancelStuckTransactionsFunc  func(ctx context.Context, a1 *ethclient.NonceStatus, a2 bind.SignerFn, a3 int) ([]*types.Transaction, error)
+	CloseFunc                    func()
+	CodeAtFunc                   func(ctx context.Context, a1 common.Address, a2 *big.Int) ([]byte, error)
+	CodeAtHashFunc               func(ctx context.Context, a1 common.Address, a2 common.Hash) ([]byte, error)
+	EstimateGasFunc              func(ctx context.Context, a1 ethereum.CallMsg) (uint64, error)
+	ExtensionFunc                func(a0 string) *ethclient.Extension
+	FeeHistoryFunc               func(ctx context.Context, a1 uint64, a2 *big.Int, a3 []float64) (*ethereum.FeeHistory, error)
+	FillTransactOptsFunc         func(ctx context.Context, a1 *bind.TransactOpts, a2 *common.Address, a3 []byte) error
+	FilterLogsFunc               func(ctx context.Context, a1 ethereum.FilterQuery) ([]types.Log, error)
+	FinalizedBlockNumberFunc     func(ctx context.Context) (uint64, error)
+	GetProofFunc                 func(ctx context.Context, a1 common.Address, a2 []string, a3 *big.Int) (*ethclient.AccountResult, error)
+	HeaderByHashFunc             func(ctx context.Context, a1 common.Hash) (*types.Header, error)
+	HeaderByNumberFunc           func(ctx context.Context, a1 *big.Int) (*types.Header, error)
+	HeaderByTagFunc              func(ctx context.Context, a1 string) (*types.Header, error)
+	InspectNoncesFunc            func(ctx context.Context, a1 common.Address) (*ethclient.NonceStatus, error)
+	MulticallContractFunc        func(ctx context.Context, a1 []ethereum.CallMsg, a2 *big.Int) ([][]byte, error)
+	NextNonceFunc                func(ctx context.Context, a1 common.Address) (uint64, error)
+	NonceAtFunc                  func(ctx context.Context, a1 common.Address, a2 *big.Int) (uint64, error)
+	PendingBalanceAtFunc         func(ctx context.Context, a1 common.Address) (*big.Int, error)
+	PendingCallContractFunc      func(ctx context.Context, a1 ethereum.CallMsg) ([]byte, error)
+	PendingCodeAtFunc            func(ctx context.Context, a1 common.Address) ([]byte, error)
+	PendingNonceAtFunc           func(ctx context.Context, a1 common.Address) (uint64, error)
+	PendingStorageAtFunc         func(ctx context.Context, a1 common.Address, a2 common.Hash) ([]byte, error)
+	PendingTransactionCountFunc  func(ctx context.Context) (uint, error)
+	RecentBaseFeesFunc           func() []ethclient.BlockBaseFee
+	RotateEndpointURLFunc        func(ctx context.Context, a1 string, a2 string) error
+	RunFunc                      func(ctx context.Context) error
+	SendTransactionFunc          func(ctx context.Context, a1 *types.Transaction) error
+	SendTransactionAndWaitFunc   func(ctx context.Context, a1 *types.Transaction, a2 *ethclient.WaitOpts) (*types.Receipt, error)
+	SendTransactionWithNonceFunc func(ctx context.Context, a1 common.Address, a2 func(uint64) (*types.Transaction, error)) (*types.Transaction, error)
+	ShutdownFunc                 func(ctx context.Context) error
+	SnapshotFunc                 func(ctx context.Context, a1 []common.Address, a2 map[common.Address][]common.Hash, a3 *big.Int) (*ethclient.StateSnapshot, error)
+	StatusFunc                   func() ethclient.Status
+	StatusSnapshotFunc           func() ethclient.StatusSnapshot
+	StorageAtFunc                func(ctx context.Context, a1 common.Address, a2 common.Hash, a3 *big.Int) ([]byte, error)
+	SubscribeCanonicalHeadsFunc  func(ctx context.Context, a1 chan<- ethclient.HeadEvent, a2 uint64) (ethereum.Subscription, error)
+	SubscribeFilterLogsFunc      func(ctx context.Context, a1 ethereum.FilterQuery, a2 chan<- types.Log) (ethereum.Subscription, error)
+	SubscribeNewHeadFunc         func(ctx context.Context, a1 chan<- *types.Header) (ethereum.Subscription, error)
+	SuggestGasPriceFunc          func(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCapFunc         func(ctx context.Context) (*big.Int, error)
+	SyncProgressFunc             func(ctx context.Context) (*ethereum.SyncProgress, error)
+	TransactionByHashFunc        func(ctx context.Context, a1 common.Hash) (*types.Transaction, bool, error)
+	TransactionCountFunc         func(ctx context.Context, a1 common.Hash) (uint, error)
+	TransactionInBlockFunc       func(ctx context.Context, a1 common.Hash, a2 uint) (*types.Transaction, error)
+	TransactionReceiptFunc       func(ctx context.Context, a1 common.Hash) (*types.Receipt, error)
+	UpdateEndpointsFunc          func(ctx context.Context, a1 *ethclient.Config) error
+	VerifyEndpointsFunc          func(ctx context.Context) error
+}
+
+var _ ethclient.Client = (*Mock)(nil)
+
+func (m *Mock) ApproxHead() uint64 {
+	m.record("ApproxHead")
+	if m.ApproxHeadFunc != nil {
+		return m.ApproxHeadFunc()
+	}
+	var r0 uint64
+	return r0
+}
+
+func (m *Mock) BackfillLogs(ctx context.Context, a1 ethereum.FilterQuery, a2 func(uint64, uint64, []types.Log) error) error {
+	m.record("BackfillLogs")
+	if m.BackfillLogsFunc != nil {
+		return m.BackfillLogsFunc(ctx, a1, a2)
+	}
+	return notMocked("BackfillLogs")
+}
+
+func (m *Mock) BalanceAt(ctx context.Context, a1 common.Address, a2 *big.Int) (*big.Int, error) {
+	m.record("BalanceAt")
+	if m.BalanceAtFunc != nil {
+		return m.BalanceAtFunc(ctx, a1, a2)
+	}
+	var r0 *big.Int
+	return r0, notMocked("BalanceAt")
+}
+
+func (m *Mock) BaseFeeAt(ctx context.Context, a1 *big.Int) (*big.Int, error) {
+	m.record("BaseFeeAt")
+	if m.BaseFeeAtFunc != nil {
+		return m.BaseFeeAtFunc(ctx, a1)
+	}
+	var r0 *big.Int
+	return r0, notMocked("BaseFeeAt")
+}
+
+func (m *Mock) BatchBalanceAt(ctx context.Context, a1 []common.Address, a2 *big.Int) ([]*big.Int, error) {
+	m.record("BatchBalanceAt")
+	if m.BatchBalanceAtFunc != nil {
+		return m.BatchBalanceAtFunc(ctx, a1, a2)
+	}
+	var r0 []*big.Int
+	return r0, notMocked("BatchBalanceAt")
+}
+
+func (m *Mock) BatchCall(ctx context.Context, a1 []rpc.BatchElem) error {
+	m.record("BatchCall")
+	if m.BatchCallFunc != nil {
+		return m.BatchCallFunc(ctx, a1)
+	}
+	return notMocked("BatchCall")
+}
+
+func (m *Mock) BatchTransactionReceipt(ctx context.Context, a1 []common.Hash) ([]*types.Receipt, error) {
+	m.record("BatchTransactionReceipt")
+	if m.BatchTransactionReceiptFunc != nil {
+		return m.BatchTransactionReceiptFunc(ctx, a1)
+	}
+	var r0 []*types.Receipt
+	return r0, notMocked("BatchTransactionReceipt")
+}
+
+func (m *Mock) BlobBaseFee(ctx context.Context) (*big.Int, error) {
+	m.record("BlobBaseFee")
+	if m.BlobBaseFeeFunc != nil {
+		return m.BlobBaseFeeFunc(ctx)
+	}
+	var r0 *big.Int
+	return r0, notMocked("BlobBaseFee")
+}
+
+func (m *Mock) BlockByHash(ctx context.Context, a1 common.Hash) (*types.Block, error) {
+	m.record("BlockByHash")
+	if m.BlockByHashFunc != nil {
+		return m.BlockByHashFunc(ctx, a1)
+	}
+	var r0 *types.Block
+	return r0, notMocked("BlockByHash")
+}
+
+func (m *Mock) BlockByNumber(ctx context.Context, a1 *big.Int) (*types.Block, error) {
+	m.record("BlockByNumber")
+	if m.BlockByNumberFunc != nil {
+		return m.BlockByNumberFunc(ctx, a1)
+	}
+	var r0 *types.Block
+	return r0, notMocked("BlockByNumber")
+}
+
+func (m *Mock) BlockReceipts(ctx context.Context, a1 rpc.BlockNumberOrHash) ([]*types.Receipt, error) {
+	m.record("BlockReceipts")
+	if m.BlockReceiptsFunc != nil {
+		return m.BlockReceiptsFunc(ctx, a1)
+	}
+	var r0 []*types.Receipt
+	return r0, notMocked("BlockReceipts")
+}
+
+func (m *Mock) CallContract(ctx context.Context, a1 ethereum.CallMsg, a2 *big.Int) ([]byte, error) {
+	m.record("CallContract")
+	if m.CallContractFunc != nil {
+		return m.CallContractFunc(ctx, a1, a2)
+	}
+	var r0 []byte
+	return r0, notMocked("CallContract")
+}
+
+func (m *Mock) CallContractAtHash(ctx context.Context, a1 ethereum.CallMsg, a2 common.Hash) ([]byte, error) {
+	m.record("CallContractAtHash")
+	if m.CallContractAtHashFunc != nil {
+		return m.CallContractAtHashFunc(ctx, a1, a2)
+	}
+	var r0 []byte
+	return r0, notMocked("CallContractAtHash")
+}
+
+func (m *Mock) CancelStuckTransactions(ctx context.Context, a1 *ethclient.NonceStatus, a2 bind.SignerFn, a3 int) ([]*types.Transaction, error) {
+	m.record("CancelStuckTransactions")
+	if m.CancelStuckTransactionsFunc != nil {
+		return m.CancelStuckTransactionsFunc(ctx, a1, a2, a3)
+	}
+	var r0 []*types.Transaction
+	return r0, notMocked("CancelStuckTransactions")
+}
+
+func (m *Mock) Close() {
+	m.record("Close")
+	if m.CloseFunc != nil {
+		m.CloseFunc()
+	}
+}
+
+func (m *Mock) CodeAt(ctx context.Context, a1 common.Address, a2 *big.Int) ([]byte, error) {
+	m.record("CodeAt")
+	if m.CodeAtFunc != nil {
+		return m.CodeAtFunc(ctx, a1, a2)
+	}
+	var r0 []byte
+	return r0, notMocked("CodeAt")
+}
+
+func (m *Mock) CodeAtHash(ctx context.Context, a1 common.Address, a2 common.Hash) ([]byte, error) {
+	m.record("CodeAtHash")
+	if m.CodeAtHashFunc != nil {
+		return m.CodeAtHashFunc(ctx, a1, a2)
+	}
+	var r0 []byte
+	return r0, notMocked("CodeAtHash")
+}
+
+func (m *Mock) EstimateGas(ctx context.Context, a1 ethereum.CallMsg) (uint64, error) {
+	m.record("EstimateGas")
+	if m.EstimateGasFunc != nil {
+		return m.EstimateGasFunc(ctx, a1)
+	}
+	var r0 uint64
+	return r0, notMocked("EstimateGas")
+}
+
+func (m *Mock) Extension(a0 string) *ethclient.Extension {
+	m.record("Extension")
+	if m.ExtensionFunc != nil {
+		return m.ExtensionFunc(a0)
+	}
+	var r0 *ethclient.Extension
+	return r0
+}
+
+func (m *Mock) FeeHistory(ctx context.Context, a1 uint64, a2 *big.Int, a3 []float64) (*ethereum.FeeHistory, error) {
+	m.record("FeeHistory")
+	if m.FeeHistoryFunc != nil {
+		return m.FeeHistoryFunc(ctx, a1, a2, a3)
+	}
+	var r0 *ethereum.FeeHistory
+	return r0, notMocked("FeeHistory")
+}
+
+func (m *Mock) FillTransactOpts(ctx context.Context, a1 *bind.TransactOpts, a2 *common.Address, a3 []byte) error {
+	m.record("FillTransactOpts")
+	if m.FillTransactOptsFunc != nil {
+		return m.FillTransactOptsFunc(ctx, a1, a2, a3)
+	}
+	return notMocked("FillTransactOpts")
+}
+
+func (m *Mock) FilterLogs(ctx context.Context, a1 ethereum.FilterQuery) ([]types.Log, error) {
+	m.record("FilterLogs")
+	if m.FilterLogsFunc != nil {
+		return m.FilterLogsFunc(ctx, a1)
+	}
+	var r0 []types.Log
+	return r0, notMocked("FilterLogs")
+}
+
+func (m *Mock) FinalizedBlockNumber(ctx context.Context) (uint64, error) {
+	m.record("FinalizedBlockNumber")
+	if m.FinalizedBlockNumberFunc != nil {
+		return m.FinalizedBlockNumberFunc(ctx)
+	}
+	var r0 uint64
+	return r0, notMocked("FinalizedBlockNumber")
+}
+
+func (m *Mock) GetProof(ctx context.Context, a1 common.Address, a2 []string, a3 *big.Int) (*ethclient.AccountResult, error) {
+	m.record("GetProof")
+	if m.GetProofFunc != nil {
+		return m.GetProofFunc(ctx, a1, a2, a3)
+	}
+	var r0 *ethclient.AccountResult
+	return r0, notMocked("GetProof")
+}
+
+func (m *Mock) HeaderByHash(ctx context.Context, a1 common.Hash) (*types.Header, error) {
+	m.record("HeaderByHash")
+	if m.HeaderByHashFunc != nil {
+		return m.HeaderByHashFunc(ctx, a1)
+	}
+	var r0 *types.Header
+	return r0, notMocked("HeaderByHash")
+}
+
+func (m *Mock) HeaderByNumber(ctx context.Context, a1 *big.Int) (*types.Header, error) {
+	m.record("HeaderByNumber")
+	if m.HeaderByNumberFunc != nil {
+		return m.HeaderByNumberFunc(ctx, a1)
+	}
+	var r0 *types.Header
+	return r0, notMocked("HeaderByNumber")
+}
+
+func (m *Mock) HeaderByTag(ctx context.Context, a1 string) (*types.Header, error) {
+	m.record("HeaderByTag")
+	if m.HeaderByTagFunc != nil {
+		return m.HeaderByTagFunc(ctx, a1)
+	}
+	var r0 *types.Header
+	return r0, notMocked("HeaderByTag")
+}
+
+func (m *Mock) InspectNonces(ctx context.Context, a1 common.Address) (*ethclient.NonceStatus, error) {
+	m.record("InspectNonces")
+	if m.InspectNoncesFunc != nil {
+		return m.InspectNoncesFunc(ctx, a1)
+	}
+	var r0 *ethclient.NonceStatus
+	return r0, notMocked("InspectNonces")
+}
+
+func (m *Mock) MulticallContract(ctx context.Context, a1 []ethereum.CallMsg, a2 *big.Int) ([][]byte, error) {
+	m.record("MulticallContract")
+	if m.MulticallContractFunc != nil {
+		return m.MulticallContractFunc(ctx, a1, a2)
+	}
+	var r0 [][]byte
+	return r0, notMocked("MulticallContract")
+}
+
+func (m *Mock) NextNonce(ctx context.Context, a1 common.Address) (uint64, error) {
+	m.record("NextNonce")
+	if m.NextNonceFunc != nil {
+		return m.NextNonceFunc(ctx, a1)
+	}
+	var r0 uint64
+	return r0, notMocked("NextNonce")
+}
+
+func (m *Mock) NonceAt(ctx context.Context, a1 common.Address, a2 *big.Int) (uint64, error) {
+	m.record("NonceAt")
+	if m.NonceAtFunc != nil {
+		return m.NonceAtFunc(ctx, a1, a2)
+	}
+	var r0 uint64
+	return r0, notMocked("NonceAt")
+}
+
+func (m *Mock) PendingBalanceAt(ctx context.Context, a1 common.Address) (*big.Int, error) {
+	m.record("PendingBalanceAt")
+	if m.PendingBalanceAtFunc != nil {
+		return m.PendingBalanceAtFunc(ctx, a1)
+	}
+	var r0 *big.Int
+	return r0, notMocked("PendingBalanceAt")
+}
+
+func (m *Mock) PendingCallContract(ctx context.Context, a1 ethereum.CallMsg) ([]byte, error) {
+	m.record("PendingCallContract")
+	if m.PendingCallContractFunc != nil {
+		return m.PendingCallContractFunc(ctx, a1)
+	}
+	var r0 []byte
+	return r0, notMocked("PendingCallContract")
+}
+
+func (m *Mock) PendingCodeAt(ctx context.Context, a1 common.Address) ([]byte, error) {
+	m.record("PendingCodeAt")
+	if m.PendingCodeAtFunc != nil {
+		return m.PendingCodeAtFunc(ctx, a1)
+	}
+	var r0 []byte
+	return r0, notMocked("PendingCodeAt")
+}
+
+func (m *Mock) PendingNonceAt(ctx context.Context, a1 common.Address) (uint64, error) {
+	m.record("PendingNonceAt")
+	if m.PendingNonceAtFunc != nil {
+		return m.PendingNonceAtFunc(ctx, a1)
+	}
+	var r0 uint64
+	return r0, notMocked("PendingNonceAt")
+}
+
+func (m *Mock) PendingStorageAt(ctx context.Context, a1 common.Address, a2 common.Hash) ([]byte, error) {
+	m.record("PendingStorageAt")
+	if m.PendingStorageAtFunc != nil {
+		return m.PendingStorageAtFunc(ctx, a1, a2)
+	}
+	var r0 []byte
+	return r0, notMocked("PendingStorageAt")
+}
+
+func (m *Mock) PendingTransactionCount(ctx context.Context) (uint, error) {
+	m.record("PendingTransactionCount")
+	if m.PendingTransactionCountFunc != nil {
+		return m.PendingTransactionCountFunc(ctx)
+	}
+	var r0 uint
+	return r0, notMocked("PendingTransactionCount")
+}
+
+func (m *Mock) RecentBaseFees() []ethclient.BlockBaseFee {
+	m.record("RecentBaseFees")
+	if m.RecentBaseFeesFunc != nil {
+		return m.RecentBaseFeesFunc()
+	}
+	var r0 []ethclient.BlockBaseFee
+	return r0
+}
+
+func (m *Mock) RotateEndpointURL(ctx context.Context, a1 string, a2 string) error {
+	m.record("RotateEndpointURL")
+	if m.RotateEndpointURLFunc != nil {
+		return m.RotateEndpointURLFunc(ctx, a1, a2)
+	}
+	return notMocked("RotateEndpointURL")
+}
+
+func (m *Mock) Run(ctx context.Context) error {
+	m.record("Run")
+	if m.RunFunc != nil {
+		return m.RunFunc(ctx)
+	}
+	return notMocked("Run")
+}
+
+func (m *Mock) SendTransaction(ctx context.Context, a1 *types.Transaction) error {
+	m.record("SendTransaction")
+	if m.SendTransactionFunc != nil {
+		return m.SendTransactionFunc(ctx, a1)
+	}
+	return notMocked("SendTransaction")
+}
+
+func (m *Mock) SendTransactionAndWait(ctx context.Context, a1 *types.Transaction, a2 *ethclient.WaitOpts) (*types.Receipt, error) {
+	m.record("SendTransactionAndWait")
+	if m.SendTransactionAndWaitFunc != nil {
+		return m.SendTransactionAndWaitFunc(ctx, a1, a2)
+	}
+	var r0 *types.Receipt
+	return r0, notMocked("SendTransactionAndWait")
+}
+
+func (m *Mock) SendTransactionWithNonce(ctx context.Context, a1 common.Address, a2 func(uint64) (*types.Transaction, error)) (*types.Transaction, error) {
+	m.record("SendTransactionWithNonce")
+	if m.SendTransactionWithNonceFunc != nil {
+		return m.SendTransactionWithNonceFunc(ctx, a1, a2)
+	}
+	var r0 *types.Transaction
+	return r0, notMocked("SendTransactionWithNonce")
+}
+
+func (m *Mock) Shutdown(ctx context.Context) error {
+	m.record("Shutdown")
+	if m.ShutdownFunc != nil {
+		return m.ShutdownFunc(ctx)
+	}
+	return notMocked("Shutdown")
+}
+
+func (m *Mock) Snapshot(ctx context.Context, a1 []common.Address, a2 map[common.Address][]common.Hash, a3 *big.Int) (*ethclient.StateSnapshot, error) {
+	m.record("Snapshot")
+	if m.SnapshotFunc != nil {
+		return m.SnapshotFunc(ctx, a1, a2, a3)
+	}
+	var r0 *ethclient.StateSnapshot
+	return r0, notMocked("Snapshot")
+}
+
+func (m *Mock) Status() ethclient.Status {
+	m.record("Status")
+	if m.StatusFunc != nil {
+		return m.StatusFunc()
+	}
+	var r0 ethclient.Status
+	return r0
+}
+
+func (m *Mock) StatusSnapshot() ethclient.StatusSnapshot {
+	m.record("StatusSnapshot")
+	if m.StatusSnapshotFunc != nil {
+		return m.StatusSnapshotFunc()
+	}
+	var r0 ethclient.StatusSnapshot
+	return r0
+}
+
+func (m *Mock) StorageAt(ctx context.Context, a1 common.Address, a2 common.Hash, a3 *big.Int) ([]byte, error) {
+	m.record("StorageAt")
+	if m.StorageAtFunc != nil {
+		return m.StorageAtFunc(ctx, a1, a2, a3)
+	}
+	var r0 []byte
+	return r0, notMocked("StorageAt")
+}
+
+func (m *Mock) SubscribeCanonicalHeads(ctx context.Context, a1 chan<- ethclient.HeadEvent, a2 uint64) (ethereum.Subscription, error) {
+	m.record("SubscribeCanonicalHeads")
+	if m.SubscribeCanonicalHeadsFunc != nil {
+		return m.SubscribeCanonicalHeadsFunc(ctx, a1, a2)
+	}
+	var r0 ethereum.Subscription
+	return r0, notMocked("SubscribeCanonicalHeads")
+}
+
+func (m *Mock) SubscribeFilterLogs(ctx context.Context, a1 ethereum.FilterQuery, a2 chan<- types.Log) (ethereum.Subscription, error) {
+	m.record("SubscribeFilterLogs")
+	if m.SubscribeFilterLogsFunc != nil {
+		return m.SubscribeFilterLogsFunc(ctx, a1, a2)
+	}
+	var r0 ethereum.Subscription
+	return r0, notMocked("SubscribeFilterLogs")
+}
+
+func (m *Mock) SubscribeNewHead(ctx context.Context, a1 chan<- *types.Header) (ethereum.Subscription, error) {
+	m.record("SubscribeNewHead")
+	if m.SubscribeNewHeadFunc != nil {
+		return m.SubscribeNewHeadFunc(ctx, a1)
+	}
+	var r0 ethereum.Subscription
+	return r0, notMocked("SubscribeNewHead")
+}
+
+func (m *Mock) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	m.record("SuggestGasPrice")
+	if m.SuggestGasPriceFunc != nil {
+		return m.SuggestGasPriceFunc(ctx)
+	}
+	var r0 *big.Int
+	return r0, notMocked("SuggestGasPrice")
+}
+
+func (m *Mock) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	m.record("SuggestGasTipCap")
+	if m.SuggestGasTipCapFunc != nil {
+		return m.SuggestGasTipCapFunc(ctx)
+	}
+	var r0 *big.Int
+	return r0, notMocked("SuggestGasTipCap")
+}
+
+func (m *Mock) SyncProgress(ctx context.Context) (*ethereum.SyncProgress, error) {
+	m.record("SyncProgress")
+	if m.SyncProgressFunc != nil {
+		return m.SyncProgressFunc(ctx)
+	}
+	var r0 *ethereum.SyncProgress
+	return r0, notMocked("SyncProgress")
+}
+
+func (m *Mock) TransactionByHash(ctx context.Context, a1 common.Hash) (*types.Transaction, bool, error) {
+	m.record("TransactionByHash")
+	if m.TransactionByHashFunc != nil {
+		return m.TransactionByHashFunc(ctx, a1)
+	}
+	var r0 *types.Transaction
+	var r1 bool
+	return r0, r1, notMocked("TransactionByHash")
+}
+
+func (m *Mock) TransactionCount(ctx context.Context, a1 common.Hash) (uint, error) {
+	m.record("TransactionCount")
+	if m.TransactionCountFunc != nil {
+		return m.TransactionCountFunc(ctx, a1)
+	}
+	var r0 uint
+	return r0, notMocked("TransactionCount")
+}
+
+func (m *Mock) TransactionInBlock(ctx context.Context, a1 common.Hash, a2 uint) (*types.Transaction, error) {
+	m.record("TransactionInBlock")
+	if m.TransactionInBlockFunc != nil {
+		return m.TransactionInBlockFunc(ctx, a1, a2)
+	}
+	var r0 *types.Transaction
+	return r0, notMocked("TransactionInBlock")
+}
+
+func (m *Mock) TransactionReceipt(ctx context.Context, a1 common.Hash) (*types.Receipt, error) {
+	m.record("TransactionReceipt")
+	if m.TransactionReceiptFunc != nil {
+		return m.TransactionReceiptFunc(ctx, a1)
+	}
+	var r0 *types.Receipt
+	return r0, notMocked("TransactionReceipt")
+}
+
+func (m *Mock) UpdateEndpoints(ctx context.Context, a1 *ethclient.Config) error {
+	m.record("UpdateEndpoints")
+	if m.UpdateEndpointsFunc != nil {
+		return m.UpdateEndpointsFunc(ctx, a1)
+	}
+	return notMocked("UpdateEndpoints")
+}
+
+func (m *Mock) VerifyEndpoints(ctx context.Context) error {
+	m.record("VerifyEndpoints")
+	if m.VerifyEndpointsFunc != nil {
+		return m.VerifyEndpointsFunc(ctx)
+	}
+	return notMocked("VerifyEndpoints")
+}
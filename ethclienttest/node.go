@@ -0,0 +1,725 @@
+package ethclienttest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// blockTime is the time between the blocks of a Node, in seconds.
+const blockTime = 12
+
+// Node is a fake endpoint serving an in-memory chain over HTTP and
+// websocket: the blocks added with AddBlock, their transactions and
+// receipts, and the balances, nonces, code, storage, call results and logs
+// set by the test. Balances, nonces, code, storage and call results are the
+// same at every block. Errors and latencies can be injected per rpc method,
+// and Down simulates an outage.
+type Node struct {
+	chainID *big.Int
+	srv     *rpc.Server
+	hs      *httptest.Server
+
+	mu        sync.Mutex
+	blocks    []*types.Block
+	byHash    map[common.Hash]*types.Block
+	txs       map[common.Hash]*types.Block
+	receipts  map[common.Hash]*types.Receipt
+	pending   []*types.Transaction
+	sent      []*types.Transaction
+	balances  map[common.Address]*big.Int
+	nonces    map[common.Address]uint64
+	code      map[common.Address][]byte
+	storage   map[common.Address]map[common.Hash]common.Hash
+	results   map[common.Address][]byte
+	logs      []types.Log
+	gasPrice  *big.Int
+	gas       uint64
+	errs      map[string]error
+	latencies map[string]time.Duration
+	calls     map[string]int
+	down      bool
+	heads     []chan *types.Header
+	hijacked  []net.Conn
+}
+
+// NewNode starts a node of chain chainID with a genesis block, stopped
+// when the test ends.
+func NewNode(t testing.TB, chainID int64) *Node {
+	t.Helper()
+	n := &Node{
+		chainID:   big.NewInt(chainID),
+		srv:       rpc.NewServer(),
+		byHash:    make(map[common.Hash]*types.Block),
+		txs:       make(map[common.Hash]*types.Block),
+		receipts:  make(map[common.Hash]*types.Receipt),
+		balances:  make(map[common.Address]*big.Int),
+		nonces:    make(map[common.Address]uint64),
+		code:      make(map[common.Address][]byte),
+		storage:   make(map[common.Address]map[common.Hash]common.Hash),
+		results:   make(map[common.Address][]byte),
+		gasPrice:  big.NewInt(gwei(1)),
+		gas:       21000,
+		errs:      make(map[string]error),
+		latencies: make(map[string]time.Duration),
+		calls:     make(map[string]int),
+	}
+	n.addBlock(nil)
+	if err := n.srv.RegisterName("eth", &ethService{n}); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.srv.RegisterName("net", &netService{n}); err != nil {
+		t.Fatal(err)
+	}
+	ws := n.srv.WebsocketHandler([]string{"*"})
+	n.hs = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n.isDown() {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			ws.ServeHTTP(w, r)
+			return
+		}
+		n.srv.ServeHTTP(w, r)
+	}))
+	// the server forgets websocket connections once hijacked, Down closes
+	// them
+	n.hs.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateHijacked {
+			n.mu.Lock()
+			n.hijacked = append(n.hijacked, conn)
+			n.mu.Unlock()
+		}
+	}
+	n.hs.Start()
+	t.Cleanup(func() {
+		n.hs.Close()
+		n.srv.Stop()
+	})
+	return n
+}
+
+// gwei returns n gwei in wei.
+func gwei(n int64) int64 {
+	return n * 1e9
+}
+
+// URL returns the HTTP url of the node.
+func (n *Node) URL() string {
+	return n.hs.URL
+}
+
+// WSURL returns the websocket url of the node.
+func (n *Node) WSURL() string {
+	return "ws" + strings.TrimPrefix(n.hs.URL, "http")
+}
+
+// AddBlock mines a block on top of the head with txs and the transactions
+// sent since the last block, each with a successful receipt, notifies the
+// new head subscriptions and returns the block.
+func (n *Node) AddBlock(txs ...*types.Transaction) *types.Block {
+	n.mu.Lock()
+	txs = append(n.pending, txs...)
+	n.pending = nil
+	b := n.addBlock(txs)
+	heads := append([]chan *types.Header{}, n.heads...)
+	n.mu.Unlock()
+	for _, ch := range heads {
+		select {
+		case ch <- b.Header():
+		default:
+		}
+	}
+	return b
+}
+
+// addBlock mines a block with txs, n.mu held.
+func (n *Node) addBlock(txs []*types.Transaction) *types.Block {
+	h := &types.Header{
+		UncleHash:  types.EmptyUncleHash,
+		TxHash:     types.EmptyRootHash,
+		Root:       types.EmptyRootHash,
+		Difficulty: new(big.Int),
+		Number:     big.NewInt(int64(len(n.blocks))),
+		GasLimit:   30_000_000,
+		BaseFee:    big.NewInt(gwei(1)),
+	}
+	if len(n.blocks) > 0 {
+		parent := n.blocks[len(n.blocks)-1].Header()
+		h.ParentHash = parent.Hash()
+		h.Time = parent.Time + blockTime
+	}
+	if len(txs) > 0 {
+		// not a trie root, just unique to the transactions
+		var hashes []byte
+		for _, tx := range txs {
+			hashes = append(hashes, tx.Hash().Bytes()...)
+		}
+		h.TxHash = crypto.Keccak256Hash(hashes)
+	}
+	b := types.NewBlockWithHeader(h).WithBody(txs, nil)
+	var gasUsed uint64
+	for i, tx := range txs {
+		gasUsed += tx.Gas()
+		n.txs[tx.Hash()] = b
+		if _, ok := n.receipts[tx.Hash()]; ok {
+			continue
+		}
+		n.receipts[tx.Hash()] = &types.Receipt{
+			Type:              tx.Type(),
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: gasUsed,
+			Logs:              []*types.Log{},
+			TxHash:            tx.Hash(),
+			GasUsed:           tx.Gas(),
+			EffectiveGasPrice: tx.GasPrice(),
+			BlockHash:         b.Hash(),
+			BlockNumber:       b.Number(),
+			TransactionIndex:  uint(i),
+		}
+	}
+	n.blocks = append(n.blocks, b)
+	n.byHash[b.Hash()] = b
+	return b
+}
+
+// Head returns the latest block.
+func (n *Node) Head() *types.Block {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.blocks[len(n.blocks)-1]
+}
+
+// SetReceipt replaces the receipt of the transaction r.TxHash, e.g. with a
+// failed one, or serves it before the transaction is mined.
+func (n *Node) SetReceipt(r *types.Receipt) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if r.Logs == nil {
+		r.Logs = []*types.Log{}
+	}
+	n.receipts[r.TxHash] = r
+}
+
+// SetBalance sets the balance of account.
+func (n *Node) SetBalance(account common.Address, balance *big.Int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.balances[account] = new(big.Int).Set(balance)
+}
+
+// SetNonce sets the nonce of account.
+func (n *Node) SetNonce(account common.Address, nonce uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.nonces[account] = nonce
+}
+
+// SetCode sets the code of the contract at account.
+func (n *Node) SetCode(account common.Address, code []byte) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.code[account] = code
+}
+
+// SetStorage sets a storage slot of the contract at account.
+func (n *Node) SetStorage(account common.Address, slot, value common.Hash) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.storage[account] == nil {
+		n.storage[account] = make(map[common.Hash]common.Hash)
+	}
+	n.storage[account][slot] = value
+}
+
+// SetCallResult sets the result of every call to the contract at to.
+func (n *Node) SetCallResult(to common.Address, result []byte) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.results[to] = result
+}
+
+// SetGasPrice sets the suggested gas price and the gas estimate of every
+// transaction (1 gwei and 21000 by default).
+func (n *Node) SetGasPrice(gasPrice *big.Int, gas uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.gasPrice, n.gas = new(big.Int).Set(gasPrice), gas
+}
+
+// AddLogs adds logs served by eth_getLogs, with their block number set.
+func (n *Node) AddLogs(logs ...types.Log) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.logs = append(n.logs, logs...)
+}
+
+// Sent returns the transactions sent to the node.
+func (n *Node) Sent() []*types.Transaction {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]*types.Transaction{}, n.sent...)
+}
+
+// SetError makes the rpc method, e.g. "eth_getBalance", or every method if
+// empty, fail with err until it is set again to nil. An rpc.Error keeps its
+// code.
+func (n *Node) SetError(method string, err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if err == nil {
+		delete(n.errs, method)
+		return
+	}
+	n.errs[method] = err
+}
+
+// SetLatency delays the responses of the rpc method, or of every method if
+// empty, by d.
+func (n *Node) SetLatency(method string, d time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.latencies[method] = d
+}
+
+// Down simulates an outage: requests are answered with 503 Service
+// Unavailable and the open connections, websocket ones included, are
+// dropped, until Up.
+func (n *Node) Down() {
+	n.mu.Lock()
+	n.down = true
+	hijacked := n.hijacked
+	n.hijacked = nil
+	n.mu.Unlock()
+	n.hs.CloseClientConnections()
+	for _, conn := range hijacked {
+		conn.Close()
+	}
+}
+
+// Up ends the outage started by Down.
+func (n *Node) Up() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.down = false
+}
+
+func (n *Node) isDown() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.down
+}
+
+// Calls returns the number of requests of the rpc method, e.g.
+// "eth_blockNumber", the node served, failed ones included.
+func (n *Node) Calls(method string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.calls[method]
+}
+
+// serve counts a request of method and applies its latency and error.
+func (n *Node) serve(method string) error {
+	n.mu.Lock()
+	n.calls[method]++
+	d, ok := n.latencies[method]
+	if !ok {
+		d = n.latencies[""]
+	}
+	err, ok := n.errs[method]
+	if !ok {
+		err = n.errs[""]
+	}
+	n.mu.Unlock()
+	time.Sleep(d)
+	return err
+}
+
+// block returns the block of number, nil if unknown, n.mu held.
+func (n *Node) block(number rpc.BlockNumber) *types.Block {
+	if number < 0 {
+		return n.blocks[len(n.blocks)-1]
+	}
+	if int(number) >= len(n.blocks) {
+		return nil
+	}
+	return n.blocks[number]
+}
+
+// marshalBlock encodes b as served by eth_getBlockByNumber.
+func (n *Node) marshalBlock(b *types.Block, full bool) (map[string]interface{}, error) {
+	if b == nil {
+		return nil, nil
+	}
+	m, err := toMap(b.Header())
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]interface{}, len(b.Transactions()))
+	for i, tx := range b.Transactions() {
+		if !full {
+			txs[i] = tx.Hash()
+			continue
+		}
+		if txs[i], err = n.marshalTransaction(tx, b, i); err != nil {
+			return nil, err
+		}
+	}
+	m["transactions"] = txs
+	m["uncles"] = []common.Hash{}
+	m["size"] = hexutil.Uint64(b.Size())
+	return m, nil
+}
+
+// marshalTransaction encodes tx, included at index of b if not nil, as
+// served by eth_getTransactionByHash.
+func (n *Node) marshalTransaction(tx *types.Transaction, b *types.Block, index int) (map[string]interface{}, error) {
+	m, err := toMap(tx)
+	if err != nil {
+		return nil, err
+	}
+	if from, err := types.Sender(types.LatestSignerForChainID(n.chainID), tx); err == nil {
+		m["from"] = from
+	}
+	if b != nil {
+		m["blockHash"] = b.Hash()
+		m["blockNumber"] = (*hexutil.Big)(b.Number())
+		m["transactionIndex"] = hexutil.Uint64(index)
+	}
+	return m, nil
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	return m, json.Unmarshal(raw, &m)
+}
+
+// ethService serves the eth namespace of a Node.
+type ethService struct {
+	n *Node
+}
+
+func (s *ethService) ChainId() (*hexutil.Big, error) {
+	if err := s.n.serve("eth_chainId"); err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(s.n.chainID), nil
+}
+
+func (s *ethService) BlockNumber() (hexutil.Uint64, error) {
+	if err := s.n.serve("eth_blockNumber"); err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(s.n.Head().NumberU64()), nil
+}
+
+func (s *ethService) GetBlockByNumber(number rpc.BlockNumber, full bool) (map[string]interface{}, error) {
+	if err := s.n.serve("eth_getBlockByNumber"); err != nil {
+		return nil, err
+	}
+	s.n.mu.Lock()
+	b := s.n.block(number)
+	s.n.mu.Unlock()
+	return s.n.marshalBlock(b, full)
+}
+
+func (s *ethService) GetBlockByHash(hash common.Hash, full bool) (map[string]interface{}, error) {
+	if err := s.n.serve("eth_getBlockByHash"); err != nil {
+		return nil, err
+	}
+	s.n.mu.Lock()
+	b := s.n.byHash[hash]
+	s.n.mu.Unlock()
+	return s.n.marshalBlock(b, full)
+}
+
+func (s *ethService) GetTransactionByHash(hash common.Hash) (map[string]interface{}, error) {
+	if err := s.n.serve("eth_getTransactionByHash"); err != nil {
+		return nil, err
+	}
+	s.n.mu.Lock()
+	b, ok := s.n.txs[hash]
+	var pending *types.Transaction
+	for _, tx := range s.n.pending {
+		if tx.Hash() == hash {
+			pending = tx
+		}
+	}
+	s.n.mu.Unlock()
+	if ok {
+		for i, tx := range b.Transactions() {
+			if tx.Hash() == hash {
+				return s.n.marshalTransaction(tx, b, i)
+			}
+		}
+	}
+	if pending != nil {
+		return s.n.marshalTransaction(pending, nil, 0)
+	}
+	return nil, nil
+}
+
+func (s *ethService) GetTransactionReceipt(hash common.Hash) (*types.Receipt, error) {
+	if err := s.n.serve("eth_getTransactionReceipt"); err != nil {
+		return nil, err
+	}
+	s.n.mu.Lock()
+	defer s.n.mu.Unlock()
+	return s.n.receipts[hash], nil
+}
+
+func (s *ethService) GetBlockReceipts(blockNrOrHash rpc.BlockNumberOrHash) ([]*types.Receipt, error) {
+	if err := s.n.serve("eth_getBlockReceipts"); err != nil {
+		return nil, err
+	}
+	s.n.mu.Lock()
+	defer s.n.mu.Unlock()
+	var b *types.Block
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		b = s.n.byHash[hash]
+	} else if number, ok := blockNrOrHash.Number(); ok {
+		b = s.n.block(number)
+	}
+	if b == nil {
+		return nil, nil
+	}
+	receipts := make([]*types.Receipt, 0, len(b.Transactions()))
+	for _, tx := range b.Transactions() {
+		receipts = append(receipts, s.n.receipts[tx.Hash()])
+	}
+	return receipts, nil
+}
+
+func (s *ethService) GetBalance(account common.Address, _ rpc.BlockNumberOrHash) (*hexutil.Big, error) {
+	if err := s.n.serve("eth_getBalance"); err != nil {
+		return nil, err
+	}
+	s.n.mu.Lock()
+	defer s.n.mu.Unlock()
+	if b, ok := s.n.balances[account]; ok {
+		return (*hexutil.Big)(new(big.Int).Set(b)), nil
+	}
+	return (*hexutil.Big)(new(big.Int)), nil
+}
+
+func (s *ethService) GetTransactionCount(account common.Address, _ rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+	if err := s.n.serve("eth_getTransactionCount"); err != nil {
+		return 0, err
+	}
+	s.n.mu.Lock()
+	defer s.n.mu.Unlock()
+	return hexutil.Uint64(s.n.nonces[account]), nil
+}
+
+func (s *ethService) GetCode(account common.Address, _ rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	if err := s.n.serve("eth_getCode"); err != nil {
+		return nil, err
+	}
+	s.n.mu.Lock()
+	defer s.n.mu.Unlock()
+	return s.n.code[account], nil
+}
+
+func (s *ethService) GetStorageAt(account common.Address, slot common.Hash, _ rpc.BlockNumberOrHash) (common.Hash, error) {
+	if err := s.n.serve("eth_getStorageAt"); err != nil {
+		return common.Hash{}, err
+	}
+	s.n.mu.Lock()
+	defer s.n.mu.Unlock()
+	return s.n.storage[account][slot], nil
+}
+
+// callArgs are the arguments of eth_call and eth_estimateGas used by the
+// node.
+type callArgs struct {
+	To *common.Address `json:"to"`
+}
+
+func (s *ethService) Call(args callArgs, _ *rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	if err := s.n.serve("eth_call"); err != nil {
+		return nil, err
+	}
+	s.n.mu.Lock()
+	defer s.n.mu.Unlock()
+	if args.To == nil {
+		return hexutil.Bytes{}, nil
+	}
+	return s.n.results[*args.To], nil
+}
+
+func (s *ethService) EstimateGas(callArgs, *rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+	if err := s.n.serve("eth_estimateGas"); err != nil {
+		return 0, err
+	}
+	s.n.mu.Lock()
+	defer s.n.mu.Unlock()
+	return hexutil.Uint64(s.n.gas), nil
+}
+
+func (s *ethService) GasPrice() (*hexutil.Big, error) {
+	if err := s.n.serve("eth_gasPrice"); err != nil {
+		return nil, err
+	}
+	s.n.mu.Lock()
+	defer s.n.mu.Unlock()
+	return (*hexutil.Big)(new(big.Int).Set(s.n.gasPrice)), nil
+}
+
+func (s *ethService) MaxPriorityFeePerGas() (*hexutil.Big, error) {
+	if err := s.n.serve("eth_maxPriorityFeePerGas"); err != nil {
+		return nil, err
+	}
+	s.n.mu.Lock()
+	defer s.n.mu.Unlock()
+	return (*hexutil.Big)(new(big.Int).Set(s.n.gasPrice)), nil
+}
+
+func (s *ethService) SendRawTransaction(raw hexutil.Bytes) (common.Hash, error) {
+	if err := s.n.serve("eth_sendRawTransaction"); err != nil {
+		return common.Hash{}, err
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return common.Hash{}, err
+	}
+	s.n.mu.Lock()
+	defer s.n.mu.Unlock()
+	s.n.sent = append(s.n.sent, tx)
+	s.n.pending = append(s.n.pending, tx)
+	return tx.Hash(), nil
+}
+
+// filterArgs are the arguments of eth_getLogs.
+type filterArgs struct {
+	BlockHash *common.Hash     `json:"blockHash"`
+	FromBlock *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock   *rpc.BlockNumber `json:"toBlock"`
+	Addresses []common.Address `json:"address"`
+	Topics    [][]common.Hash  `json:"topics"`
+}
+
+func (s *ethService) GetLogs(args filterArgs) ([]types.Log, error) {
+	if err := s.n.serve("eth_getLogs"); err != nil {
+		return nil, err
+	}
+	s.n.mu.Lock()
+	defer s.n.mu.Unlock()
+	head := s.n.blocks[len(s.n.blocks)-1].NumberU64()
+	from, to := uint64(0), head
+	if args.FromBlock != nil && *args.FromBlock >= 0 {
+		from = uint64(*args.FromBlock)
+	}
+	if args.ToBlock != nil && *args.ToBlock >= 0 {
+		to = uint64(*args.ToBlock)
+	}
+	logs := []types.Log{}
+	for _, l := range s.n.logs {
+		if args.BlockHash != nil && l.BlockHash != *args.BlockHash {
+			continue
+		}
+		if args.BlockHash == nil && (l.BlockNumber < from || l.BlockNumber > to) {
+			continue
+		}
+		if matches(l, args.Addresses, args.Topics) {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+// matches reports whether l has one of addresses, if any, and one of the
+// topics at each position.
+func matches(l types.Log, addresses []common.Address, topics [][]common.Hash) bool {
+	if len(addresses) > 0 && !contains(addresses, l.Address) {
+		return false
+	}
+	for i, set := range topics {
+		if len(set) == 0 {
+			continue
+		}
+		if i >= len(l.Topics) || !contains(set, l.Topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains[T comparable](set []T, v T) bool {
+	for _, s := range set {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// NewHeads serves the newHeads subscriptions, sending the blocks of
+// AddBlock.
+func (s *ethService) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	if err := s.n.serve("eth_subscribe"); err != nil {
+		return nil, err
+	}
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	ch := make(chan *types.Header, 16)
+	s.n.mu.Lock()
+	s.n.heads = append(s.n.heads, ch)
+	s.n.mu.Unlock()
+	go func() {
+		defer func() {
+			s.n.mu.Lock()
+			defer s.n.mu.Unlock()
+			for i, head := range s.n.heads {
+				if head == ch {
+					s.n.heads = append(s.n.heads[:i], s.n.heads[i+1:]...)
+					break
+				}
+			}
+		}()
+		for {
+			select {
+			case h := <-ch:
+				if err := notifier.Notify(sub.ID, h); err != nil {
+					return
+				}
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// netService serves the net namespace of a Node.
+type netService struct {
+	n *Node
+}
+
+func (s *netService) Version() (string, error) {
+	if err := s.n.serve("net_version"); err != nil {
+		return "", err
+	}
+	return fmt.Sprint(s.n.chainID), nil
+}
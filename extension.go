@@ -0,0 +1,59 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Extension calls the rpc methods of a namespace, e.g. the optimism or
+// arbtrace methods of L2 chains, with the retries, failover and metrics of
+// the client it comes from. Calls are labeled with their rpc method in
+// metrics, and endpoints answering "method not found" are skipped for
+// that method for a while. Chain specific clients such as OptimismClient
+// are built on it.
+type Extension struct {
+	c         *client
+	namespace string
+}
+
+// Extension returns the sub-client calling the methods of namespace.
+func (c *client) Extension(namespace string) *Extension {
+	return &Extension{c: c, namespace: namespace}
+}
+
+// Namespace returns the namespace of the methods called by x.
+func (x *Extension) Namespace() string {
+	return x.namespace
+}
+
+// Call calls the method of the namespace of x (e.g. rollupConfig for
+// optimism_rollupConfig) with args and decodes its result into result. A
+// null result is returned as ethereum.NotFound, like lookups of the
+// client, so that the other endpoint is tried.
+func (x *Extension) Call(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	rpcMethod := x.namespace + "_" + method
+	return x.c.do(ctx, rpcMethod, func(ctx context.Context, _ *ethclient.Client) error {
+		var raw json.RawMessage
+		if err := rpcClientFromContext(ctx).CallContext(ctx, &raw, rpcMethod, args...); err != nil {
+			return err
+		}
+		if len(raw) == 0 || string(raw) == "null" {
+			return ethereum.NotFound
+		}
+		if result == nil {
+			return nil
+		}
+		return json.Unmarshal(raw, result)
+	})
+}
+
+// isExtensionMethod reports whether method is called through an
+// Extension, they are named after their rpc method, unlike the methods of
+// the client.
+func isExtensionMethod(method string) bool {
+	return strings.Contains(method, "_")
+}
@@ -0,0 +1,90 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+// defaultFailsafeCacheSize is the number of responses kept by the failsafe
+// cache when FailsafeCacheSize isn't set.
+const defaultFailsafeCacheSize = 1024
+
+// failsafeEntry is the last successful answer of a call and when it was
+// fetched.
+type failsafeEntry struct {
+	value   any
+	fetched time.Time
+}
+
+// Staleness reports whether calls made with the context returned by
+// WithStalenessReport were answered from the failsafe cache because every
+// endpoint failed.
+type Staleness struct {
+	mu      sync.Mutex
+	fetched time.Time // of the oldest stale answer, zero if none
+}
+
+type stalenessKey struct{}
+
+// WithStalenessReport returns a context whose calls record in the returned
+// Staleness whether they were answered from the failsafe cache.
+func WithStalenessReport(ctx context.Context) (context.Context, *Staleness) {
+	s := &Staleness{}
+	return context.WithValue(ctx, stalenessKey{}, s), s
+}
+
+// Stale reports whether a call was answered from the failsafe cache.
+func (s *Staleness) Stale() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.fetched.IsZero()
+}
+
+// FetchedAt returns when the oldest stale answer was fetched from an
+// endpoint, zero if no answer was stale.
+func (s *Staleness) FetchedAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fetched
+}
+
+func (s *Staleness) mark(fetched time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fetched.IsZero() || fetched.Before(s.fetched) {
+		s.fetched = fetched
+	}
+}
+
+// remember keeps r as the failsafe answer of the call identified by key.
+func (c *client) remember(key string, r any) {
+	if c.failsafe == nil {
+		return
+	}
+	c.failsafe.add(key, &failsafeEntry{value: r, fetched: time.Now()})
+}
+
+// failsafe answers the call of method identified by key that failed with
+// err from the failsafe cache, if enabled and the call failed on the
+// endpoints rather than because of the caller or a missing result.
+func failsafe[T any](ctx context.Context, c *client, method, key string, err error) (T, error) {
+	var zero T
+	if c.failsafe == nil || ctx.Err() != nil || errors.Is(err, ethereum.NotFound) {
+		return zero, err
+	}
+	v, ok := c.failsafe.get(key)
+	if !ok {
+		return zero, err
+	}
+	entry := v.(*failsafeEntry)
+	if s, _ := ctx.Value(stalenessKey{}).(*Staleness); s != nil {
+		s.mark(entry.fetched)
+	}
+	c.metrics.ObserveStale(method)
+	c.logger.Warn().Err(err).Msgf("answering %s from the failsafe cache, fetched %s ago", method, time.Since(entry.fetched).Round(time.Second))
+	return entry.value.(T), nil
+}
@@ -0,0 +1,108 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type testNetService struct{}
+
+func (testNetService) Version() string {
+	return "1"
+}
+
+// newOutageNode serves svc until down is set, then answers 503.
+func newOutageNode(t *testing.T, svc *testEthService, down *atomic.Bool) string {
+	t.Helper()
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", svc); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.RegisterName("net", testNetService{}); err != nil {
+		t.Fatal(err)
+	}
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		srv.ServeHTTP(w, r)
+	}))
+	t.Cleanup(func() {
+		hs.Close()
+		srv.Stop()
+	})
+	return hs.URL
+}
+
+func TestFailsafeCacheDuringOutage(t *testing.T) {
+	var down atomic.Bool
+	cfg := testConfig(
+		newOutageNode(t, &testEthService{chainID: 5}, &down),
+		newOutageNode(t, &testEthService{chainID: 5}, &down),
+	)
+	cfg.FailsafeCache = true
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err := c.(*client).NetworkID(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	down.Store(true)
+
+	ctx, staleness := WithStalenessReport(context.Background())
+	id, err := c.(*client).ChainID(ctx)
+	if err != nil || id.Int64() != 5 {
+		t.Fatalf("ChainID = %v, %v, want the verified chain id", id, err)
+	}
+	if !staleness.Stale() || staleness.FetchedAt().IsZero() {
+		t.Fatal("failsafe answer not reported as stale")
+	}
+	id.SetInt64(7) // must not alter the failsafe answer
+	if n, err := c.(*client).NetworkID(context.Background()); err != nil || n.Int64() != 1 {
+		t.Fatalf("NetworkID = %v, %v, want the last answer", n, err)
+	}
+	if id, _ := c.(*client).ChainID(context.Background()); id.Int64() != 5 {
+		t.Fatalf("ChainID = %v after altering a previous answer", id)
+	}
+
+	// calls never answered before still fail
+	if _, err := c.(*client).HeaderByHash(context.Background(), common.Hash{1}); err == nil || errors.Is(err, ethereum.NotFound) {
+		t.Fatalf("HeaderByHash = %v, want the endpoints' error", err)
+	}
+
+	down.Store(false)
+	ctx, staleness = WithStalenessReport(context.Background())
+	if _, err := c.(*client).ChainID(ctx); err != nil || staleness.Stale() {
+		t.Fatalf("ChainID = %v, stale %v once the endpoints are back", err, staleness.Stale())
+	}
+}
+
+func TestFailsafeCacheDisabled(t *testing.T) {
+	var down atomic.Bool
+	cfg := testConfig(
+		newOutageNode(t, &testEthService{chainID: 5}, &down),
+		newOutageNode(t, &testEthService{chainID: 5}, &down),
+	)
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	down.Store(true)
+	if _, err := c.(*client).ChainID(context.Background()); err == nil {
+		t.Fatal("ChainID answered during an outage without the failsafe cache")
+	}
+}
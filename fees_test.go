@@ -0,0 +1,75 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+type testBlobService struct{}
+
+func (testBlobService) BlobBaseFee() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(7))
+}
+
+func TestBlobBaseFee(t *testing.T) {
+	// only the failover endpoint knows about blobs
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1}),
+		newTestNodeWith(t, &testEthService{chainID: 1}, testBlobService{}),
+	)
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.(*client).Close()
+
+	fee, err := c.BlobBaseFee(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee.Int64() != 7 {
+		t.Fatalf("BlobBaseFee = %s, want 7", fee)
+	}
+	if c.(*client).m.supports("BlobBaseFee") {
+		t.Fatal("main endpoint should be marked as lacking BlobBaseFee")
+	}
+}
+
+func TestBlobBaseFeeUnsupported(t *testing.T) {
+	cfg := testConfig(newTestNode(t, 1), newTestNode(t, 1))
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.(*client).Close()
+
+	if _, err := c.BlobBaseFee(context.Background()); !errors.Is(err, ErrMethodNotSupported) {
+		t.Fatalf("BlobBaseFee error = %v, want ErrMethodNotSupported", err)
+	}
+}
+
+func TestRetryAfterUnsupported(t *testing.T) {
+	cfg := testConfig(newTestNode(t, 1), newTestNode(t, 1))
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.(*client).Close()
+
+	// the first call finds out, the next one fails fast
+	if _, err := c.BlobBaseFee(context.Background()); err == nil {
+		t.Fatal("BlobBaseFee succeeded")
+	}
+	_, err = c.BlobBaseFee(context.Background())
+	if !errors.Is(err, ErrMethodNotSupported) {
+		t.Fatalf("BlobBaseFee error = %v, want ErrMethodNotSupported", err)
+	}
+	after, ok := RetryAfter(err)
+	if !ok || after <= 0 || after > capabilityRecheckInterval {
+		t.Fatalf("RetryAfter = %s, %v, want within the recheck interval", after, ok)
+	}
+}
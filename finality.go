@@ -0,0 +1,92 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// finalityPollMethod labels finalized block polls in metrics.
+const finalityPollMethod = "FinalizedPoll"
+
+// blockTags are the tags accepted by HeaderByTag, as block numbers of
+// HeaderByNumber: nil for the latest block, -1 for the pending one.
+var blockTags = map[string]*big.Int{
+	"latest":    nil,
+	"pending":   big.NewInt(-1),
+	"safe":      big.NewInt(int64(rpc.SafeBlockNumber)),
+	"finalized": big.NewInt(int64(rpc.FinalizedBlockNumber)),
+}
+
+// HeaderByTag returns the header of the block tagged latest, pending, safe
+// or finalized, like HeaderByNumber.
+func (c *client) HeaderByTag(ctx context.Context, tag string) (*types.Header, error) {
+	number, ok := blockTags[tag]
+	if !ok {
+		return nil, fmt.Errorf("unknown block tag %q", tag)
+	}
+	return c.HeaderByNumber(ctx, number)
+}
+
+// FinalizedBlockNumber returns the number of the latest finalized block.
+func (c *client) FinalizedBlockNumber(ctx context.Context) (uint64, error) {
+	h, err := c.HeaderByTag(ctx, "finalized")
+	if err != nil {
+		return 0, err
+	}
+	return h.Number.Uint64(), nil
+}
+
+// pollFinalized polls the finalized block of every endpoint and checks
+// that they agree: the lowest one is looked up on the other endpoints, a
+// block differing means one of them serves blocks that aren't final.
+func (c *client) pollFinalized(ctx context.Context) {
+	var lowest *types.Header
+	var from *endpoint
+	var polled []*endpoint
+	for _, e := range c.endpoints() {
+		if e.unavailable() {
+			continue
+		}
+		var h *types.Header
+		t := time.Now()
+		err := e.call(ctx, func(ec *ethclient.Client) (err error) {
+			h, err = ec.HeaderByNumber(ctx, blockTags["finalized"])
+			return err
+		})
+		c.metrics.Observe(finalityPollMethod, t, e.name, err == nil)
+		if err != nil {
+			c.logger.Debug().Msgf("failed to poll finalized block of rpc %s: %s", e.name, err)
+			continue
+		}
+		e.finalized.Store(h)
+		polled = append(polled, e)
+		if lowest == nil || h.Number.Cmp(lowest.Number) < 0 {
+			lowest, from = h, e
+		}
+	}
+	for _, e := range polled {
+		if e == from {
+			continue
+		}
+		h := e.finalized.Load()
+		if h.Number.Cmp(lowest.Number) != 0 {
+			err := e.call(ctx, func(ec *ethclient.Client) (err error) {
+				h, err = ec.HeaderByNumber(ctx, lowest.Number)
+				return err
+			})
+			if err != nil {
+				continue
+			}
+		}
+		if h.Hash() != lowest.Hash() {
+			c.metrics.ObserveMismatch(finalityPollMethod)
+			c.logger.Warn().Msgf("finalized block %s differs between rpc %s (%s) and %s (%s)", lowest.Number, from.name, lowest.Hash(), e.name, h.Hash())
+		}
+	}
+}
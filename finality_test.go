@@ -0,0 +1,98 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// testFinalityNode serves blocks 0 to 9 of a fork, block 9 being the
+// latest, 8 the safe one and finalized the finalized one.
+type testFinalityNode struct {
+	fork      byte
+	finalized int64
+}
+
+func (s *testFinalityNode) GetBlockByNumber(number rpc.BlockNumber, _ bool) (map[string]interface{}, error) {
+	switch number {
+	case rpc.LatestBlockNumber, rpc.PendingBlockNumber:
+		number = 9
+	case rpc.SafeBlockNumber:
+		number = 8
+	case rpc.FinalizedBlockNumber:
+		number = rpc.BlockNumber(s.finalized)
+	}
+	h := &types.Header{Number: big.NewInt(int64(number)), Difficulty: big.NewInt(0), Extra: []byte{s.fork}}
+	raw, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	var block map[string]interface{}
+	err = json.Unmarshal(raw, &block)
+	return block, err
+}
+
+func TestHeaderByTag(t *testing.T) {
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1}, &testFinalityNode{finalized: 5}),
+		newTestNode(t, 1),
+	)
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for tag, want := range map[string]int64{"latest": 9, "pending": 9, "safe": 8, "finalized": 5} {
+		if h, err := c.HeaderByTag(context.Background(), tag); err != nil || h.Number.Int64() != want {
+			t.Errorf("HeaderByTag(%s) = %v, %v, want block %d", tag, h, err, want)
+		}
+	}
+	if _, err := c.HeaderByTag(context.Background(), "earliest"); err == nil {
+		t.Error("HeaderByTag accepted an unknown tag")
+	}
+	if n, err := c.FinalizedBlockNumber(context.Background()); err != nil || n != 5 {
+		t.Fatalf("FinalizedBlockNumber = %d, %v, want 5", n, err)
+	}
+}
+
+func TestPollFinalizedReportsForks(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		failover *testFinalityNode
+		want     float64
+	}{
+		{"same chain", &testFinalityNode{finalized: 6}, 0},
+		{"fork", &testFinalityNode{fork: 1, finalized: 6}, 1},
+		{"fork at the same height", &testFinalityNode{fork: 1, finalized: 5}, 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+			cfg := testConfig(
+				newTestNodeWith(t, &testEthService{chainID: 1}, &testFinalityNode{finalized: 5}),
+				newTestNodeWith(t, &testEthService{chainID: 1}, tt.failover),
+			)
+			cfg.EnablePrometheus = true
+			cfg.PrometheusRegisterer = reg
+			c, err := New("test", "eth", cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+
+			c.(*client).pollFinalized(context.Background())
+			s := c.Status()
+			if s.Endpoints[0].Finalized != 5 || s.Endpoints[1].Finalized != uint64(tt.failover.finalized) {
+				t.Fatalf("finalized heights = %d and %d", s.Endpoints[0].Finalized, s.Endpoints[1].Finalized)
+			}
+			if got, _ := gathered(t, reg, "rpc_result_mismatch_total", map[string]string{labelMethod: finalityPollMethod}); got != tt.want {
+				t.Fatalf("finality mismatches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package ethclient
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// freshestMethods are the methods supporting freshest reads, for their
+// latest block or state only.
+var freshestMethods = map[string]bool{
+	"BalanceAt":      true,
+	"BlockByNumber":  true,
+	"BlockNumber":    true,
+	"CallContract":   true,
+	"CodeAt":         true,
+	"HeaderByNumber": true,
+	"NonceAt":        true,
+	"StorageAt":      true,
+}
+
+func (c *client) freshestEnabled(method string, latest bool) bool {
+	if !latest {
+		return false
+	}
+	for _, m := range c.cfg.FreshestMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// freshest queries all available endpoints concurrently and returns the
+// result of the one that is the furthest ahead, waiting for the slowest
+// endpoint to favor recency over latency. The height of a result is given
+// by height, or when nil by the last polled head of its endpoint. Ties go
+// to the endpoint first in routing order.
+func freshest[T any](
+	ctx context.Context,
+	c *client,
+	method string,
+	fn func(context.Context, *ethclient.Client) (T, error),
+	height func(T) uint64,
+) (r T, err error) {
+	err = c.run(ctx, method, func(ctx context.Context) error {
+		var endpoints []*endpoint
+		for _, e := range c.endpoints() {
+			if !e.unavailable() && e.methods.allows(method) {
+				endpoints = append(endpoints, e)
+			}
+		}
+		if len(endpoints) < 2 || hasRoutingHint(ctx) {
+			return c.failover(ctx, method, func(ctx context.Context, ec *ethclient.Client) (err error) {
+				r, err = fn(ctx, ec)
+				return err
+			})
+		}
+
+		results := make([]T, len(endpoints))
+		errs := make([]error, len(endpoints))
+		var wg sync.WaitGroup
+		for i, e := range endpoints {
+			wg.Add(1)
+			go func(i int, e *endpoint) {
+				defer wg.Done()
+				errs[i] = c.try(ctx, method, e, false, func(ctx context.Context, ec *ethclient.Client) (err error) {
+					results[i], err = fn(ctx, ec)
+					return err
+				})
+			}(i, e)
+		}
+		wg.Wait()
+
+		best := -1
+		var bestHeight uint64
+		for i, e := range endpoints {
+			if errs[i] != nil {
+				continue
+			}
+			h := e.head.Load()
+			if height != nil {
+				h = height(results[i])
+			}
+			if best < 0 || h > bestHeight {
+				best, bestHeight = i, h
+			}
+		}
+		if best < 0 {
+			return errs[0]
+		}
+		r = results[best]
+		return nil
+	})
+	return r, err
+}
+
+func headerHeight(h *types.Header) uint64 {
+	if h == nil || h.Number == nil {
+		return 0
+	}
+	return h.Number.Uint64()
+}
+
+func blockHeight(b *types.Block) uint64 {
+	if b == nil {
+		return 0
+	}
+	return b.NumberU64()
+}
+
+func identityHeight(n uint64) uint64 {
+	return n
+}
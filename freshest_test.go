@@ -0,0 +1,45 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"testing"
+)
+
+func TestFreshestReads(t *testing.T) {
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 5, balance: 1}),
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 9, balance: 2}),
+	)
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	cl := c.(*client)
+	ctx := context.Background()
+
+	if n, err := cl.BlockNumber(ctx); err != nil || n != 5 {
+		t.Fatalf("BlockNumber = %d, %v, want 5 from the primary", n, err)
+	}
+
+	cfg.FreshestMethods = []string{"BlockNumber", "BalanceAt"}
+	if n, err := cl.BlockNumber(ctx); err != nil || n != 9 {
+		t.Fatalf("freshest BlockNumber = %d, %v, want 9", n, err)
+	}
+	// state reads go by the polled heads
+	cl.m.head.Store(5)
+	cl.b.head.Store(9)
+	if b, err := c.BalanceAt(ctx, [20]byte{}, nil); err != nil || b.Int64() != 2 {
+		t.Fatalf("freshest BalanceAt = %v, %v, want 2", b, err)
+	}
+	// reads at a given block aren't affected
+	if b, err := c.BalanceAt(ctx, [20]byte{}, big.NewInt(3)); err != nil || b.Int64() != 1 {
+		t.Fatalf("BalanceAt(3) = %v, %v, want 1 from the primary", b, err)
+	}
+
+	cl.b.sidelined.Store(true)
+	if n, err := cl.BlockNumber(ctx); err != nil || n != 5 {
+		t.Fatalf("BlockNumber with one endpoint available = %d, %v, want 5", n, err)
+	}
+}
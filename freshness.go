@@ -0,0 +1,45 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ErrStaleEndpoint is returned for reads of the latest state when every
+// endpoint lags more than Config.MaxHeadLagBlocks behind the head.
+var ErrStaleEndpoint = errors.New("rpc endpoints lag behind the head")
+
+type latestReadKey struct{}
+
+// withLatestRead marks the call in ctx as a read of the latest state or
+// block when blockNumber is nil, so that it is kept from lagging endpoints.
+func (c *client) withLatestRead(ctx context.Context, blockNumber *big.Int) context.Context {
+	if blockNumber != nil || c.cfg.MaxHeadLagBlocks == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, latestReadKey{}, true)
+}
+
+// routeByHead drops the endpoints of routes polled more than
+// Config.MaxHeadLagBlocks behind the highest height, for reads of the
+// latest state. Endpoints not polled yet are kept.
+func (c *client) routeByHead(ctx context.Context, method string, routes []*endpoint) ([]*endpoint, error) {
+	if latest, _ := ctx.Value(latestReadKey{}).(bool); !latest {
+		return routes, nil
+	}
+	best := c.head.best()
+	fresh := make([]*endpoint, 0, len(routes))
+	for _, e := range routes {
+		if n := e.head.Load(); n > 0 && n+c.cfg.MaxHeadLagBlocks < best {
+			c.metrics.ObserveStaleRead(method, e.name)
+			continue
+		}
+		fresh = append(fresh, e)
+	}
+	if len(fresh) == 0 {
+		return nil, fmt.Errorf("%w: %s, head is %d", ErrStaleEndpoint, method, best)
+	}
+	return fresh, nil
+}
@@ -0,0 +1,57 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestLatestReadsSkipLaggingEndpoints(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 100, balance: 1}),
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 600, balance: 2}),
+	)
+	cfg.HeadPollIntervalMs = 60000
+	cfg.MaxHeadLagBlocks = 5
+	cfg.EnablePrometheus = true
+	cfg.PrometheusRegisterer = reg
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	cl := c.(*client)
+	cl.pollHeads(time.Second)
+	ctx := context.Background()
+
+	if b, err := c.BalanceAt(ctx, [20]byte{}, nil); err != nil || b.Int64() != 2 {
+		t.Fatalf("BalanceAt = %v, %v, want 2 from the failover", b, err)
+	}
+	if n, err := cl.BlockNumber(ctx); err != nil || n != 600 {
+		t.Fatalf("BlockNumber = %d, %v, want 600 from the failover", n, err)
+	}
+	// reads at a block number are left alone
+	if b, err := c.BalanceAt(ctx, [20]byte{}, big.NewInt(90)); err != nil || b.Int64() != 1 {
+		t.Fatalf("BalanceAt at block 90 = %v, %v, want 1 from main", b, err)
+	}
+	if got, _ := gathered(t, reg, "rpc_stale_reads_total", map[string]string{labelMethod: "BalanceAt", labelClient: "main"}); got != 1 {
+		t.Fatalf("rpc_stale_reads_total = %v, want 1", got)
+	}
+
+	if _, err := cl.routeByHead(cl.withLatestRead(ctx, nil), "BalanceAt", []*endpoint{cl.m}); !errors.Is(err, ErrStaleEndpoint) {
+		t.Fatalf("routes of a lagging endpoint alone = %v, want ErrStaleEndpoint", err)
+	}
+}
+
+func TestMaxHeadLagBlocksNeedsHeadPolls(t *testing.T) {
+	cfg := testConfig("http://a", "http://b")
+	cfg.MaxHeadLagBlocks = 5
+	if err := cfg.Valid(); err == nil {
+		t.Fatal("MaxHeadLagBlocks accepted without HeadPollIntervalMs")
+	}
+}
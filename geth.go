@@ -0,0 +1,89 @@
+package ethclient
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// GethClient exposes geth specific node methods, the client returned by New
+// satisfies it. Endpoints that don't offer a method (e.g. a provider with
+// the admin namespace disabled) are detected and skipped for a while,
+// ErrMethodNotSupported is returned if none offers it.
+//
+//	gc, ok := client.(ethclient.GethClient)
+type GethClient interface {
+	GetNodeInfo(ctx context.Context) (*NodeInfo, error)
+	MemStats(ctx context.Context) (*runtime.MemStats, error)
+	SubscribeFullPendingTransactions(ctx context.Context, ch chan<- *types.Transaction) (ethereum.Subscription, error)
+	TxPoolContent(ctx context.Context) (*TxPoolContent, error)
+	TxPoolStatus(ctx context.Context) (*TxPoolStatus, error)
+}
+
+var _ GethClient = (*client)(nil)
+
+// gethMethods are only offered by some endpoints, they are subject to
+// capability gating.
+var gethMethods = map[string]bool{
+	"GetNodeInfo":                      true,
+	"MemStats":                         true,
+	"SubscribeFullPendingTransactions": true,
+	"TxPoolContent":                    true,
+	"TxPoolStatus":                     true,
+}
+
+// capabilityGated reports whether endpoints may lack method, in which case
+// they are skipped for it instead of failing the call. Besides geth node,
+// trace and extension methods, this covers eth methods of recent forks.
+func capabilityGated(method string) bool {
+	return gethMethods[method] || traceMethods[method] || isExtensionMethod(method) || method == "BlobBaseFee"
+}
+
+// NodeInfo is the result of admin_nodeInfo, mirroring geth's p2p.NodeInfo.
+type NodeInfo struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Enode string `json:"enode"`
+	ENR   string `json:"enr"`
+	IP    string `json:"ip"`
+	Ports struct {
+		Discovery int `json:"discovery"`
+		Listener  int `json:"listener"`
+	} `json:"ports"`
+	ListenAddr string                 `json:"listenAddr"`
+	Protocols  map[string]interface{} `json:"protocols"`
+}
+
+func (c *client) GetNodeInfo(ctx context.Context) (*NodeInfo, error) {
+	return do(ctx, c, "GetNodeInfo", func(ctx context.Context, _ *ethclient.Client) (*NodeInfo, error) {
+		var info NodeInfo
+		if err := rpcClientFromContext(ctx).CallContext(ctx, &info, "admin_nodeInfo"); err != nil {
+			return nil, err
+		}
+		return &info, nil
+	})
+}
+
+func (c *client) MemStats(ctx context.Context) (*runtime.MemStats, error) {
+	return do(ctx, c, "MemStats", func(ctx context.Context, _ *ethclient.Client) (*runtime.MemStats, error) {
+		var stats runtime.MemStats
+		if err := rpcClientFromContext(ctx).CallContext(ctx, &stats, "debug_memStats"); err != nil {
+			return nil, err
+		}
+		return &stats, nil
+	})
+}
+
+func (c *client) SubscribeFullPendingTransactions(ctx context.Context, ch chan<- *types.Transaction) (ethereum.Subscription, error) {
+	return do(ctx, c, "SubscribeFullPendingTransactions", func(ctx context.Context, _ *ethclient.Client) (ethereum.Subscription, error) {
+		e := endpointFromContext(ctx)
+		sub, err := rpcClientFromContext(ctx).EthSubscribe(ctx, ch, "newPendingTransactions", true)
+		if err != nil {
+			return nil, err
+		}
+		return c.trackSubscription(ctx, e, sub), nil
+	})
+}
@@ -0,0 +1,58 @@
+package ethclient
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type codeError struct{ code int }
+
+func (e codeError) Error() string  { return fmt.Sprintf("rpc error %d", e.code) }
+func (e codeError) ErrorCode() int { return e.code }
+
+func TestIsMethodNotFound(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{codeError{-32601}, true},
+		{fmt.Errorf("wrapped: %w", codeError{-32601}), true},
+		{codeError{-32000}, false},
+		{errors.New("the method admin_nodeInfo does not exist/is not available"), false},
+		{errors.New("method not supported"), false},
+	} {
+		if got := isMethodNotFound(tt.err); got != tt.want {
+			t.Errorf("isMethodNotFound(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestEndpointSupportsExpires(t *testing.T) {
+	e := &endpoint{name: "test"}
+	if !e.supports("MemStats") {
+		t.Fatal("unmarked method should be supported")
+	}
+	e.markUnsupported("MemStats")
+	if e.supports("MemStats") {
+		t.Fatal("marked method should not be supported")
+	}
+	if !e.supports("GetNodeInfo") {
+		t.Fatal("other methods should stay supported")
+	}
+	e.unsupported.Store("MemStats", time.Now().Add(-capabilityRecheckInterval))
+	if !e.supports("MemStats") {
+		t.Fatal("expired mark should be probed again")
+	}
+}
+
+func TestCapabilityGated(t *testing.T) {
+	if !capabilityGated("GetNodeInfo") {
+		t.Error("GetNodeInfo should be capability gated")
+	}
+	if capabilityGated("BlockNumber") {
+		t.Error("BlockNumber should not be capability gated")
+	}
+}
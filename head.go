@@ -0,0 +1,135 @@
+package ethclient
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	// headRateSmoothing is the weight of the latest block rate sample.
+	headRateSmoothing = 0.2
+	// headPollMethod labels head polls in metrics, apart from the user
+	// facing BlockNumber calls.
+	headPollMethod = "HeadPoll"
+)
+
+// headTracker keeps the best known head along with an exponentially
+// smoothed block rate, used to extrapolate the head between polls.
+type headTracker struct {
+	mu       sync.RWMutex
+	number   uint64
+	polledAt time.Time
+	rate     float64 // blocks per second
+	maxAge   time.Duration
+}
+
+func (h *headTracker) observe(number uint64, at time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.polledAt.IsZero() && number >= h.number {
+		if dt := at.Sub(h.polledAt).Seconds(); dt > 0 {
+			sample := float64(number-h.number) / dt
+			if h.rate == 0 {
+				h.rate = sample
+			} else {
+				h.rate = headRateSmoothing*sample + (1-headRateSmoothing)*h.rate
+			}
+		}
+	}
+	if number > h.number || h.polledAt.IsZero() {
+		h.number = number
+	}
+	h.polledAt = at
+}
+
+// best returns the highest polled head, or 0 if none was observed yet.
+func (h *headTracker) best() uint64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.number
+}
+
+// approx returns the extrapolated head, or 0 if none was observed yet.
+func (h *headTracker) approx(now time.Time) uint64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.polledAt.IsZero() {
+		return 0
+	}
+	// don't extrapolate past a couple of polls in case the chain halts
+	elapsed := now.Sub(h.polledAt)
+	if elapsed > h.maxAge {
+		elapsed = h.maxAge
+	}
+	return h.number + uint64(h.rate*elapsed.Seconds())
+}
+
+// ApproxHead returns the approximate current block height from the head
+// watcher without doing any RPC call. It returns 0 when the head watcher
+// is disabled or hasn't observed a block yet.
+func (c *client) ApproxHead() uint64 {
+	return c.head.approx(time.Now())
+}
+
+// watchHeads polls the block height of every endpoint in the background.
+func (c *client) watchHeads(interval time.Duration) {
+	c.head.maxAge = 2 * interval
+	c.goBackground(func() {
+		c.pollHeads(interval)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.done:
+				return
+			case <-ticker.C:
+				c.pollHeads(interval)
+			}
+		}
+	})
+}
+
+func (c *client) pollHeads(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var best uint64
+	for _, e := range c.endpoints() {
+		if e.unavailable() {
+			continue
+		}
+		var n uint64
+		t := time.Now()
+		err := e.call(ctx, func(ec *ethclient.Client) (err error) {
+			n, err = ec.BlockNumber(ctx)
+			return err
+		})
+		c.metrics.Observe(headPollMethod, t, e.name, err == nil)
+		if err != nil {
+			c.logger.Debug().Msgf("failed to poll head of rpc %s: %s", e.name, err)
+			continue
+		}
+		e.head.Store(n)
+		if n > best {
+			best = n
+		}
+	}
+	for _, e := range c.endpoints() {
+		c.metrics.SetHealthy(e.name, e.health() == HealthHealthy)
+		if n := e.head.Load(); n > 0 && n <= best {
+			c.metrics.SetBlockHeight(e.name, n, best)
+		}
+	}
+	if c.cfg.CheckFinality {
+		c.pollFinalized(ctx)
+	}
+	if best > 0 {
+		c.head.observe(best, time.Now())
+		if c.baseFees != nil {
+			c.trackBaseFees(ctx, best)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package ethclient
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHeadTracker(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	h := &headTracker{maxAge: 24 * time.Second}
+	if n := h.approx(start); n != 0 {
+		t.Fatalf("approx before any observation = %d, want 0", n)
+	}
+
+	h.observe(100, start)
+	if n := h.approx(start.Add(10 * time.Second)); n != 100 {
+		t.Fatalf("approx without a rate = %d, want 100", n)
+	}
+
+	// one block every 12s
+	h.observe(101, start.Add(12*time.Second))
+	for _, tt := range []struct {
+		elapsed time.Duration
+		want    uint64
+	}{
+		{0, 101},
+		{6 * time.Second, 101},
+		{12 * time.Second, 102},
+		{24 * time.Second, 103},
+		// capped at maxAge in case the chain halts
+		{time.Hour, 103},
+	} {
+		if n := h.approx(start.Add(12*time.Second + tt.elapsed)); n != tt.want {
+			t.Errorf("approx after %s = %d, want %d", tt.elapsed, n, tt.want)
+		}
+	}
+}
+
+func TestHeadTrackerSmoothsRate(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	h := &headTracker{maxAge: time.Hour}
+	h.observe(0, start)
+	h.observe(10, start.Add(10*time.Second)) // 1 block/s
+	h.observe(30, start.Add(20*time.Second)) // 2 blocks/s
+	want := headRateSmoothing*2 + (1-headRateSmoothing)*1
+	if math.Abs(h.rate-want) > 1e-9 {
+		t.Fatalf("rate = %v, want %v", h.rate, want)
+	}
+}
+
+func TestHeadTrackerIgnoresRegression(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	h := &headTracker{maxAge: time.Minute}
+	h.observe(100, start)
+	h.observe(90, start.Add(time.Second))
+	if h.number != 100 {
+		t.Fatalf("number = %d, want 100", h.number)
+	}
+	if h.rate != 0 {
+		t.Fatalf("rate = %v, want 0", h.rate)
+	}
+}
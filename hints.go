@@ -0,0 +1,67 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+)
+
+type endpointHintKey struct{}
+
+type noFailoverKey struct{}
+
+// WithEndpoint routes the calls made with the returned context to the
+// endpoint called name alone, whatever its health, e.g. to replay a failed
+// query against the backup or to read old state from an archive node.
+// Websocket endpoints are called after their rpc endpoint, with a -ws
+// suffix. It takes precedence over WithNoFailover and strict mode.
+func WithEndpoint(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, endpointHintKey{}, name)
+}
+
+// WithNoFailover keeps the calls made with the returned context on the
+// endpoint first in routing order, their error is returned instead of
+// failing over.
+func WithNoFailover(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noFailoverKey{}, true)
+}
+
+// hasRoutingHint reports whether the call in ctx is steered by WithEndpoint
+// or WithNoFailover, and must not be served by other endpoints or shared
+// with other calls.
+func hasRoutingHint(ctx context.Context) bool {
+	_, endpoint := ctx.Value(endpointHintKey{}).(string)
+	noFailover, _ := ctx.Value(noFailoverKey{}).(bool)
+	return endpoint || noFailover
+}
+
+// routeCall returns the endpoints to try for the call to method in ctx, in
+// order, see route.
+func (c *client) routeCall(ctx context.Context, method string) ([]*endpoint, error) {
+	if name, ok := ctx.Value(endpointHintKey{}).(string); ok {
+		for _, e := range c.endpointsFor(method) {
+			if e.name != name {
+				continue
+			}
+			if !e.methods.allows(method) {
+				return nil, fmt.Errorf("%w: %s on %s", ErrMethodNotSupported, method, name)
+			}
+			return []*endpoint{e}, nil
+		}
+		return nil, fmt.Errorf("unknown rpc endpoint %q", name)
+	}
+	routes, err := c.route(method)
+	if err != nil {
+		return nil, err
+	}
+	if routes, err = c.routeByState(ctx, method, routes); err != nil {
+		return nil, err
+	}
+	if routes, err = c.routeByHead(ctx, method, routes); err != nil {
+		return nil, err
+	}
+	routes = pinFirst(ctx, routes)
+	if noFailover, _ := ctx.Value(noFailoverKey{}).(bool); noFailover {
+		routes = routes[:1]
+	}
+	return routes, nil
+}
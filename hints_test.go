@@ -0,0 +1,56 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWithEndpoint(t *testing.T) {
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 1}),
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 2}),
+	)
+	cfg.StrictFailover = true
+	cfg.StrictFailoverMethods = []string{"BlockNumber"}
+	cfg.CoalesceCalls = true
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for name, want := range map[string]uint64{"main": 1, "failover": 2} {
+		if n, err := c.(*client).BlockNumber(WithEndpoint(context.Background(), name)); err != nil || n != want {
+			t.Fatalf("BlockNumber on %s = %d, %v, want %d", name, n, err, want)
+		}
+	}
+	if _, err := c.(*client).BlockNumber(WithEndpoint(context.Background(), "archive")); err == nil {
+		t.Fatal("BlockNumber on an unknown endpoint succeeded")
+	}
+}
+
+func TestWithNoFailover(t *testing.T) {
+	var down atomic.Bool
+	down.Store(true)
+	cfg := testConfig(newOutageNode(t, &testEthService{chainID: 1}, &down), newTestNode(t, 1))
+	cfg.VerifyChainID = false
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	_, err = c.(*client).BlockNumber(WithNoFailover(context.Background()))
+	var fe *FailoverError
+	if err == nil || errors.As(err, &fe) {
+		t.Fatalf("BlockNumber without failover = %v, want the error of the primary", err)
+	}
+	if n := c.Status().Failovers; n != 0 {
+		t.Fatalf("%d failovers, want none", n)
+	}
+	if _, err := c.(*client).BlockNumber(context.Background()); err != nil {
+		t.Fatalf("BlockNumber = %v, want it served by the failover", err)
+	}
+}
@@ -0,0 +1,59 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ErrStateNotAvailable is returned for state reads at a block older than
+// any endpoint keeps the state of.
+var ErrStateNotAvailable = errors.New("no rpc endpoint keeps the state of the block")
+
+type stateBlockKey struct{}
+
+// withStateBlock marks the call in ctx as a state read at blockNumber, so
+// that it is only routed to endpoints keeping the state of that block, or
+// to endpoints keeping up with the head for the latest block.
+func (c *client) withStateBlock(ctx context.Context, blockNumber *big.Int) context.Context {
+	ctx = c.withLatestRead(ctx, blockNumber)
+	if blockNumber == nil || blockNumber.Sign() < 0 || !blockNumber.IsUint64() {
+		// latest, pending and other tags are served by every endpoint
+		return ctx
+	}
+	if c.m.stateHistory == 0 && c.b.stateHistory == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, stateBlockKey{}, blockNumber.Uint64())
+}
+
+// routeByState drops the endpoints of routes that pruned the state the
+// call in ctx reads. The head is the one of the head watcher, without it
+// every endpoint is assumed to keep the state.
+func (c *client) routeByState(ctx context.Context, method string, routes []*endpoint) ([]*endpoint, error) {
+	block, ok := ctx.Value(stateBlockKey{}).(uint64)
+	if !ok {
+		return routes, nil
+	}
+	head := c.ApproxHead()
+	if head == 0 {
+		return routes, nil
+	}
+	capable := make([]*endpoint, 0, len(routes))
+	for _, e := range routes {
+		if e.keepsState(block, head) {
+			capable = append(capable, e)
+		}
+	}
+	if len(capable) == 0 {
+		return nil, fmt.Errorf("%w: %s at block %d, head is %d", ErrStateNotAvailable, method, block, head)
+	}
+	return capable, nil
+}
+
+// keepsState reports whether e still has the state of block, given the
+// chain is at head.
+func (e *endpoint) keepsState(block, head uint64) bool {
+	return e.stateHistory == 0 || block+e.stateHistory > head
+}
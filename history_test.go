@@ -0,0 +1,48 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestRouteByStateHistory(t *testing.T) {
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1, balance: 1}),
+		newTestNodeWith(t, &testEthService{chainID: 1, balance: 2}),
+	)
+	cfg.RpcStateHistoryBlocks = 128 // full node, the failover is an archive node
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	// without a known head every endpoint is assumed to keep the state
+	if b, err := c.BalanceAt(ctx, [20]byte{}, big.NewInt(10)); err != nil || b.Int64() != 1 {
+		t.Fatalf("BalanceAt = %v, %v, want 1 from the primary", b, err)
+	}
+
+	c.(*client).head.maxAge = time.Minute
+	c.(*client).head.observe(1000, time.Now())
+	for _, tt := range []struct {
+		block *big.Int
+		want  int64
+	}{
+		{nil, 1},
+		{big.NewInt(990), 1},
+		{big.NewInt(10), 2},
+	} {
+		if b, err := c.BalanceAt(ctx, [20]byte{}, tt.block); err != nil || b.Int64() != tt.want {
+			t.Errorf("BalanceAt(%v) = %v, %v, want %d", tt.block, b, err, tt.want)
+		}
+	}
+
+	c.(*client).b.stateHistory = 128
+	if _, err := c.BalanceAt(ctx, [20]byte{}, big.NewInt(10)); !errors.Is(err, ErrStateNotAvailable) {
+		t.Fatalf("BalanceAt error = %v, want ErrStateNotAvailable", err)
+	}
+}
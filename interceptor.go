@@ -0,0 +1,25 @@
+package ethclient
+
+import "context"
+
+// Interceptor wraps every RPC method of the client, including retries and
+// failover. Implementations must invoke call to perform the request and
+// may pass down a derived context, e.g. to attach auth or tracing data.
+type Interceptor func(ctx context.Context, method string, call func(ctx context.Context) error) error
+
+// chainInterceptors combines interceptors into one, the first interceptor
+// being the outermost one.
+func chainInterceptors(interceptors []Interceptor) Interceptor {
+	switch len(interceptors) {
+	case 0:
+		return nil
+	case 1:
+		return interceptors[0]
+	}
+	outer, inner := interceptors[0], chainInterceptors(interceptors[1:])
+	return func(ctx context.Context, method string, call func(ctx context.Context) error) error {
+		return outer(ctx, method, func(ctx context.Context) error {
+			return inner(ctx, method, call)
+		})
+	}
+}
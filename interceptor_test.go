@@ -0,0 +1,69 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInterceptors(t *testing.T) {
+	var calls []string
+	var short error
+	intercept := func(name string) Interceptor {
+		return func(ctx context.Context, method string, call func(ctx context.Context) error) error {
+			calls = append(calls, name+">"+method)
+			if short != nil && name == "outer" {
+				return short
+			}
+			err := call(ctx)
+			calls = append(calls, "<"+name+" "+errorString(err))
+			return err
+		}
+	}
+	cfg := testConfig(newTestNode(t, 1), newTestNode(t, 1))
+	cfg.Interceptors = []Interceptor{intercept("outer"), intercept("inner")}
+	cfg.RpcDenyMethods = []string{"BalanceAt"}
+	cfg.FailoverRpcDenyMethods = []string{"BalanceAt"}
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	cl := c.(*client)
+	ctx := context.Background()
+
+	if _, err := cl.BlockNumber(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(calls, ", "), "outer>BlockNumber, inner>BlockNumber, <inner ok, <outer ok"; got != want {
+		t.Fatalf("calls %q, want %q", got, want)
+	}
+
+	// both see the error of the call
+	calls = nil
+	_, err = c.BalanceAt(ctx, [20]byte{}, nil)
+	if !errors.Is(err, ErrMethodNotSupported) {
+		t.Fatalf("BalanceAt = %v, want ErrMethodNotSupported", err)
+	}
+	if got, want := strings.Join(calls, ", "), "outer>BalanceAt, inner>BalanceAt, <inner "+err.Error()+", <outer "+err.Error(); got != want {
+		t.Fatalf("calls %q, want %q", got, want)
+	}
+
+	// the outer interceptor short-circuits the inner one and the call
+	calls = nil
+	short = errors.New("denied")
+	if _, err := cl.BlockNumber(ctx); err != short {
+		t.Fatalf("BlockNumber = %v, want the outer interceptor's error", err)
+	}
+	if got, want := strings.Join(calls, ", "), "outer>BlockNumber"; got != want {
+		t.Fatalf("calls %q, want %q", got, want)
+	}
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	return err.Error()
+}
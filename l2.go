@@ -0,0 +1,162 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// L2Receipt is a receipt of an L2 chain along with the L1 fee components
+// its endpoint reports, which types.Receipt drops. Fields a chain doesn't
+// report are left unset.
+type L2Receipt struct {
+	*types.Receipt
+	// OP stack: fee paid for the L1 data of the transaction, and what it
+	// was derived from
+	L1Fee       *big.Int
+	L1GasPrice  *big.Int
+	L1GasUsed   *big.Int
+	L1FeeScalar string // decimal, before Ecotone
+	// OP stack since Ecotone
+	L1BlobBaseFee       *big.Int
+	L1BaseFeeScalar     uint64
+	L1BlobBaseFeeScalar uint64
+	// Arbitrum: gas used for the L1 data of the transaction and the L1
+	// block it was sequenced at
+	GasUsedForL1  uint64
+	L1BlockNumber uint64
+}
+
+// l2Receipt returns the receipt of txHash with its L1 fee components.
+func l2Receipt(ctx context.Context, eth *Extension, txHash common.Hash) (*L2Receipt, error) {
+	var raw json.RawMessage
+	if err := eth.Call(ctx, &raw, "getTransactionReceipt", txHash); err != nil {
+		return nil, err
+	}
+	var r types.Receipt
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return nil, err
+	}
+	var fees struct {
+		L1Fee               *hexutil.Big    `json:"l1Fee"`
+		L1GasPrice          *hexutil.Big    `json:"l1GasPrice"`
+		L1GasUsed           *hexutil.Big    `json:"l1GasUsed"`
+		L1FeeScalar         string          `json:"l1FeeScalar"`
+		L1BlobBaseFee       *hexutil.Big    `json:"l1BlobBaseFee"`
+		L1BaseFeeScalar     *hexutil.Uint64 `json:"l1BaseFeeScalar"`
+		L1BlobBaseFeeScalar *hexutil.Uint64 `json:"l1BlobBaseFeeScalar"`
+		GasUsedForL1        *hexutil.Uint64 `json:"gasUsedForL1"`
+		L1BlockNumber       *hexutil.Uint64 `json:"l1BlockNumber"`
+	}
+	if err := json.Unmarshal(raw, &fees); err != nil {
+		return nil, err
+	}
+	uint64Of := func(v *hexutil.Uint64) uint64 {
+		if v == nil {
+			return 0
+		}
+		return uint64(*v)
+	}
+	return &L2Receipt{
+		Receipt:             &r,
+		L1Fee:               fees.L1Fee.ToInt(),
+		L1GasPrice:          fees.L1GasPrice.ToInt(),
+		L1GasUsed:           fees.L1GasUsed.ToInt(),
+		L1FeeScalar:         fees.L1FeeScalar,
+		L1BlobBaseFee:       fees.L1BlobBaseFee.ToInt(),
+		L1BaseFeeScalar:     uint64Of(fees.L1BaseFeeScalar),
+		L1BlobBaseFeeScalar: uint64Of(fees.L1BlobBaseFeeScalar),
+		GasUsedForL1:        uint64Of(fees.GasUsedForL1),
+		L1BlockNumber:       uint64Of(fees.L1BlockNumber),
+	}, nil
+}
+
+// OptimismClient exposes the methods of OP stack chains (Optimism, Base),
+// through Extensions of a client. The optimism namespace is served by the
+// rollup node (op-node) rather than the execution client, its endpoints
+// must serve both or proxy it.
+type OptimismClient struct {
+	eth      *Extension
+	optimism *Extension
+}
+
+// NewOptimismClient returns the OptimismClient calling the endpoints of c.
+func NewOptimismClient(c Client) *OptimismClient {
+	return &OptimismClient{eth: c.Extension("eth"), optimism: c.Extension("optimism")}
+}
+
+// OutputResponse is the result of optimism_outputAtBlock, the output root
+// proposed to L1 for an L2 block.
+type OutputResponse struct {
+	Version    common.Hash `json:"version"`
+	OutputRoot common.Hash `json:"outputRoot"`
+	BlockRef   struct {
+		Hash   common.Hash    `json:"hash"`
+		Number hexutil.Uint64 `json:"number"`
+	} `json:"blockRef"`
+	WithdrawalStorageRoot common.Hash `json:"withdrawalStorageRoot"`
+	StateRoot             common.Hash `json:"stateRoot"`
+}
+
+// RollupConfig returns the rollup configuration of the chain
+// (optimism_rollupConfig), as JSON since its fields change between
+// upgrades.
+func (o *OptimismClient) RollupConfig(ctx context.Context) (json.RawMessage, error) {
+	var r json.RawMessage
+	err := o.optimism.Call(ctx, &r, "rollupConfig")
+	return r, err
+}
+
+// OutputAtBlock returns the output root of L2 block number
+// (optimism_outputAtBlock).
+func (o *OptimismClient) OutputAtBlock(ctx context.Context, number uint64) (*OutputResponse, error) {
+	var r OutputResponse
+	if err := o.optimism.Call(ctx, &r, "outputAtBlock", hexutil.Uint64(number)); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// TransactionReceipt returns the receipt of txHash along with its L1 fee.
+func (o *OptimismClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*L2Receipt, error) {
+	return l2Receipt(ctx, o.eth, txHash)
+}
+
+// ArbitrumClient exposes the methods of Arbitrum chains, through Extensions
+// of a client.
+type ArbitrumClient struct {
+	eth      *Extension
+	arbtrace *Extension
+}
+
+// NewArbitrumClient returns the ArbitrumClient calling the endpoints of c.
+func NewArbitrumClient(c Client) *ArbitrumClient {
+	return &ArbitrumClient{eth: c.Extension("eth"), arbtrace: c.Extension("arbtrace")}
+}
+
+// TraceTransaction returns the parity style traces of txHash
+// (arbtrace_transaction), for blocks before the Nitro upgrade.
+func (a *ArbitrumClient) TraceTransaction(ctx context.Context, txHash common.Hash) (json.RawMessage, error) {
+	var r json.RawMessage
+	err := a.arbtrace.Call(ctx, &r, "transaction", txHash)
+	return r, err
+}
+
+// TraceBlock returns the parity style traces of the block at blockNumber
+// (arbtrace_block), the latest block when nil, for blocks before the Nitro
+// upgrade.
+func (a *ArbitrumClient) TraceBlock(ctx context.Context, blockNumber *big.Int) (json.RawMessage, error) {
+	var r json.RawMessage
+	err := a.arbtrace.Call(ctx, &r, "block", blockNumberArg(blockNumber))
+	return r, err
+}
+
+// TransactionReceipt returns the receipt of txHash along with the gas used
+// for its L1 data.
+func (a *ArbitrumClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*L2Receipt, error) {
+	return l2Receipt(ctx, a.eth, txHash)
+}
@@ -0,0 +1,128 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testL2Receipts serves the receipt of common.Hash{1} with the L1 fee
+// components of OP stack and Arbitrum chains.
+type testL2Receipts struct{}
+
+func (testL2Receipts) GetTransactionReceipt(hash common.Hash) (map[string]interface{}, error) {
+	if hash != (common.Hash{1}) {
+		return nil, nil
+	}
+	raw, err := json.Marshal(&types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: hash, GasUsed: 21000, Logs: []*types.Log{}, BlockNumber: big.NewInt(3)})
+	if err != nil {
+		return nil, err
+	}
+	var r map[string]interface{}
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return nil, err
+	}
+	r["l1Fee"] = (*hexutil.Big)(big.NewInt(500))
+	r["l1GasUsed"] = (*hexutil.Big)(big.NewInt(1600))
+	r["l1FeeScalar"] = "0.684"
+	r["gasUsedForL1"] = hexutil.Uint64(7)
+	r["l1BlockNumber"] = hexutil.Uint64(17000000)
+	return r, nil
+}
+
+// testRollupNode serves the optimism namespace of op-node.
+type testRollupNode struct{}
+
+func (testRollupNode) RollupConfig() map[string]interface{} {
+	return map[string]interface{}{"l2_chain_id": 10}
+}
+
+func (testRollupNode) OutputAtBlock(number hexutil.Uint64) map[string]interface{} {
+	return map[string]interface{}{
+		"version":    common.Hash{},
+		"outputRoot": common.Hash{9},
+		"blockRef":   map[string]interface{}{"hash": common.Hash{8}, "number": number},
+	}
+}
+
+// newTestL2Node serves the eth namespace and, with rollup, the optimism
+// one.
+func newTestL2Node(t *testing.T, rollup bool) string {
+	t.Helper()
+	srv := rpc.NewServer()
+	receivers := map[string][]interface{}{"eth": {&testEthService{chainID: 1}, testL2Receipts{}}}
+	if rollup {
+		receivers["optimism"] = []interface{}{testRollupNode{}}
+	}
+	for name, rcvrs := range receivers {
+		for _, rcvr := range rcvrs {
+			if err := srv.RegisterName(name, rcvr); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	hs := httptest.NewServer(srv)
+	t.Cleanup(func() {
+		hs.Close()
+		srv.Stop()
+	})
+	return hs.URL
+}
+
+func TestOptimismClient(t *testing.T) {
+	// only the failover serves the rollup node methods
+	c, err := New("test", "eth", testConfig(newTestL2Node(t, false), newTestL2Node(t, true)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	op := NewOptimismClient(c)
+
+	config, err := op.RollupConfig(context.Background())
+	if err != nil || string(config) != `{"l2_chain_id":10}` {
+		t.Fatalf("RollupConfig = %s, %v", config, err)
+	}
+	if c.(*client).m.supports("optimism_rollupConfig") {
+		t.Fatal("primary lacking optimism_rollupConfig not remembered")
+	}
+	out, err := op.OutputAtBlock(context.Background(), 12)
+	if err != nil || out.OutputRoot != (common.Hash{9}) || out.BlockRef.Number != 12 {
+		t.Fatalf("OutputAtBlock = %+v, %v", out, err)
+	}
+
+	r, err := op.TransactionReceipt(context.Background(), common.Hash{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.GasUsed != 21000 || r.L1Fee.Int64() != 500 || r.L1GasUsed.Int64() != 1600 || r.L1FeeScalar != "0.684" || r.L1GasPrice != nil {
+		t.Fatalf("TransactionReceipt = %+v", r)
+	}
+	if _, err := op.TransactionReceipt(context.Background(), common.Hash{2}); !errors.Is(err, ethereum.NotFound) {
+		t.Fatalf("TransactionReceipt of an unknown transaction = %v, want NotFound", err)
+	}
+}
+
+func TestArbitrumClientReceipt(t *testing.T) {
+	c, err := New("test", "eth", testConfig(newTestL2Node(t, false), newTestL2Node(t, false)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	r, err := NewArbitrumClient(c).TransactionReceipt(context.Background(), common.Hash{1})
+	if err != nil || r.GasUsedForL1 != 7 || r.L1BlockNumber != 17000000 {
+		t.Fatalf("TransactionReceipt = %+v, %v", r, err)
+	}
+	if _, err := NewArbitrumClient(c).TraceBlock(context.Background(), big.NewInt(1)); !errors.Is(err, ErrMethodNotSupported) {
+		t.Fatalf("TraceBlock without arbtrace = %v, want ErrMethodNotSupported", err)
+	}
+}
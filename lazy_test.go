@@ -0,0 +1,32 @@
+package ethclient
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLazyDial(t *testing.T) {
+	cfg := testConfig("ws://127.0.0.1:1", newTestNodeWith(t, &testEthService{chainID: 1, head: 7}))
+	cfg.LazyDial = true
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatalf("New error = %v, want none with a down endpoint", err)
+	}
+	defer c.(*client).Close()
+	cl := c.(*client)
+	if connOf(cl.m) != nil || connOf(cl.b) != nil {
+		t.Fatal("endpoints dialed by New")
+	}
+
+	for i := 0; i < 2; i++ {
+		if n, err := cl.BlockNumber(context.Background()); err != nil || n != 7 {
+			t.Fatalf("BlockNumber = %d, %v, want 7", n, err)
+		}
+	}
+	if !cl.m.dialFailed.Load() || !cl.m.unavailable() {
+		t.Fatal("failed dial not recorded")
+	}
+	if routes, _ := cl.route("BlockNumber"); routes[0] != cl.b {
+		t.Fatal("endpoint failing to dial still routed first")
+	}
+}
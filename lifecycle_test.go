@@ -0,0 +1,52 @@
+package ethclient
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithContextClosesClient(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cfg := testConfig(newTestNode(t, 1), newTestNode(t, 1))
+	cfg.HeadPollIntervalMs = 10
+	c, err := New("test", "eth", cfg, WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	select {
+	case <-c.(*client).done:
+	case <-time.After(time.Second):
+		t.Fatal("client not closed once its context was canceled")
+	}
+	c.Close() // waits for the close in progress
+	if _, err := c.(*client).BlockNumber(context.Background()); err == nil {
+		t.Fatal("call on a closed client succeeded")
+	}
+}
+
+func TestRun(t *testing.T) {
+	c, err := New("test", "eth", testConfig(newTestNode(t, 1), newTestNode(t, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() { errs <- c.Run(ctx) }()
+	cancel()
+	if err := <-errs; err != nil {
+		t.Fatalf("Run error = %v", err)
+	}
+	select {
+	case <-c.(*client).done:
+	default:
+		t.Fatal("client not closed by Run")
+	}
+
+	// Run returns right away on a closed client
+	if err := c.Run(context.Background()); err != nil {
+		t.Fatalf("Run error = %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package ethclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// defaultLogChunkBlocks is the block range of the queries of BackfillLogs
+// when Config.LogChunkBlocks is 0.
+const defaultLogChunkBlocks = 2000
+
+// BackfillLogs queries the logs of q in chunks of Config.LogChunkBlocks
+// blocks, up to the current block when q.ToBlock is nil, and passes them
+// to fn in order.
+//
+// Each chunk is handed to fn once the next one has been fetched. When the
+// next chunk was served by another endpoint, e.g. after a failover, that
+// endpoint is asked for the held back chunk again: if its logs differ, as
+// can happen between providers around reorgs, its version is handed to fn
+// instead, so the logs of a backfill around a failover come from the same
+// endpoint.
+func (c *client) BackfillLogs(ctx context.Context, q ethereum.FilterQuery, fn func(from, to uint64, logs []types.Log) error) error {
+	if q.BlockHash != nil {
+		return errors.New("backfilling logs needs a block range, not a block hash")
+	}
+	var start, end uint64
+	if q.FromBlock != nil {
+		start = q.FromBlock.Uint64()
+	}
+	if q.ToBlock != nil {
+		end = q.ToBlock.Uint64()
+	} else {
+		head, err := c.BlockNumber(ctx)
+		if err != nil {
+			return err
+		}
+		end = head
+	}
+	size := c.logChunkBlocks()
+
+	var held *logChunk
+	for from := start; from <= end; {
+		to := end
+		if end-from >= size {
+			to = from + size - 1
+		}
+		next, err := c.filterLogsChunk(ctx, q, from, to)
+		if err != nil {
+			return err
+		}
+		if held != nil {
+			if held.e != next.e {
+				if err := c.recheckLogs(ctx, q, held, next.e); err != nil {
+					return err
+				}
+			}
+			if err := fn(held.from, held.to, held.logs); err != nil {
+				return err
+			}
+		}
+		held = next
+		if to == end {
+			break
+		}
+		from = to + 1
+	}
+	if held == nil {
+		return nil
+	}
+	return fn(held.from, held.to, held.logs)
+}
+
+// logChunk holds the logs of a block range and the endpoint serving them.
+type logChunk struct {
+	from, to uint64
+	logs     []types.Log
+	e        *endpoint
+}
+
+func (c *client) filterLogsChunk(ctx context.Context, q ethereum.FilterQuery, from, to uint64) (*logChunk, error) {
+	chunk, err := do(ctx, c, "FilterLogs", func(ctx context.Context, ec *ethclient.Client) (*logChunk, error) {
+		cq := chunkQuery(q, from, to)
+		logs, err := ec.FilterLogs(ctx, cq)
+		chunk := &logChunk{from: from, to: to, logs: logs, e: endpointFromContext(ctx)}
+		return chunk, c.validate(ctx, err, func() error { return checkLogs(logs, cq) })
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter logs of blocks %d to %d: %w", from, to, err)
+	}
+	return chunk, nil
+}
+
+// recheckLogs queries the logs of chunk again from e, replacing them with
+// e's if they differ.
+func (c *client) recheckLogs(ctx context.Context, q ethereum.FilterQuery, chunk *logChunk, e *endpoint) error {
+	var logs []types.Log
+	err := c.try(ctx, "FilterLogs", e, true, func(ctx context.Context, ec *ethclient.Client) (err error) {
+		cq := chunkQuery(q, chunk.from, chunk.to)
+		logs, err = ec.FilterLogs(ctx, cq)
+		return c.validate(ctx, err, func() error { return checkLogs(logs, cq) })
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check logs of blocks %d to %d on rpc %s: %w", chunk.from, chunk.to, e.name, err)
+	}
+	if !logsEqual(chunk.logs, logs) {
+		c.logger.Warn().Msgf("rpc %s and %s disagree on the logs of blocks %d to %d, using those of %s",
+			chunk.e.name, e.name, chunk.from, chunk.to, e.name)
+		c.metrics.ObserveMismatch("FilterLogs")
+		chunk.logs = logs
+	}
+	chunk.e = e
+	return nil
+}
+
+// logChunkBlocks returns the block range of backfill queries from Config,
+// capped by the provider presets of the endpoints.
+func (c *client) logChunkBlocks() uint64 {
+	size := c.cfg.LogChunkBlocks
+	if size == 0 {
+		size = defaultLogChunkBlocks
+	}
+	for _, e := range c.endpoints() {
+		if e.preset != nil && e.preset.MaxLogRange > 0 && e.preset.MaxLogRange < size {
+			size = e.preset.MaxLogRange
+		}
+	}
+	return size
+}
+
+func chunkQuery(q ethereum.FilterQuery, from, to uint64) ethereum.FilterQuery {
+	q.FromBlock = new(big.Int).SetUint64(from)
+	q.ToBlock = new(big.Int).SetUint64(to)
+	return q
+}
+
+func logsEqual(a, b []types.Log) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !logEqual(&a[i], &b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func logEqual(a, b *types.Log) bool {
+	if a.Address != b.Address ||
+		a.BlockNumber != b.BlockNumber ||
+		a.BlockHash != b.BlockHash ||
+		a.TxHash != b.TxHash ||
+		a.Index != b.Index ||
+		a.Removed != b.Removed ||
+		len(a.Topics) != len(b.Topics) ||
+		!bytes.Equal(a.Data, b.Data) {
+		return false
+	}
+	for i := range a.Topics {
+		if a.Topics[i] != b.Topics[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// testLogService returns one log per queried range, carrying tag as data,
+// and fails queries from failFrom on when set.
+type testLogService struct {
+	tag      byte
+	failFrom uint64
+}
+
+type testFilter struct {
+	FromBlock hexutil.Uint64 `json:"fromBlock"`
+	ToBlock   hexutil.Uint64 `json:"toBlock"`
+}
+
+func (s testLogService) GetLogs(f testFilter) ([]types.Log, error) {
+	if s.failFrom > 0 && uint64(f.FromBlock) >= s.failFrom {
+		return nil, errors.New("internal error")
+	}
+	return []types.Log{{BlockNumber: uint64(f.FromBlock), Data: []byte{s.tag}, Topics: []common.Hash{}}}, nil
+}
+
+func TestBackfillLogs(t *testing.T) {
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1}, testLogService{tag: 1, failFrom: 20}),
+		newTestNodeWith(t, &testEthService{chainID: 1}, testLogService{tag: 2}),
+	)
+	cfg.LogChunkBlocks = 10
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var got [][3]uint64
+	q := ethereum.FilterQuery{FromBlock: big.NewInt(0), ToBlock: big.NewInt(34)}
+	err = c.BackfillLogs(context.Background(), q, func(from, to uint64, logs []types.Log) error {
+		if len(logs) != 1 {
+			t.Fatalf("got %d logs for blocks %d to %d", len(logs), from, to)
+		}
+		got = append(got, [3]uint64{from, to, uint64(logs[0].Data[0])})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// blocks 10 to 19 precede the failover, the failover's logs replace them
+	want := [][3]uint64{{0, 9, 1}, {10, 19, 2}, {20, 29, 2}, {30, 34, 2}}
+	if len(got) != len(want) {
+		t.Fatalf("chunks = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("chunks = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestBackfillLogsStops(t *testing.T) {
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1}, testLogService{tag: 1}),
+		newTestNodeWith(t, &testEthService{chainID: 1}, testLogService{tag: 1}),
+	)
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	stop := errors.New("stop")
+	q := ethereum.FilterQuery{FromBlock: big.NewInt(0), ToBlock: big.NewInt(5000)}
+	calls := 0
+	err = c.BackfillLogs(context.Background(), q, func(from, to uint64, _ []types.Log) error {
+		calls++
+		if from != 0 || to != defaultLogChunkBlocks-1 {
+			t.Fatalf("first chunk is %d to %d", from, to)
+		}
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("BackfillLogs = %v after %d chunks, want the error of fn after 1", err, calls)
+	}
+}
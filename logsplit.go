@@ -0,0 +1,137 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const defaultLogSplitConcurrency = 4
+
+// filterLogsSplit queries the logs of q, splitting its block range in
+// halves while the provider rejects it as too large. The halves are
+// fetched concurrently and their logs merged in order.
+func (c *client) filterLogsSplit(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	logs, err := c.filterLogs(ctx, q)
+	if !errors.Is(err, ErrLogRangeExceeded) {
+		return logs, err
+	}
+	from, to, rerr := c.resolveLogRange(ctx, q)
+	if rerr != nil {
+		return nil, fmt.Errorf("%w, and its range can't be split: %w", err, rerr)
+	}
+	if from >= to {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	concurrency := c.cfg.LogSplitConcurrency
+	if concurrency == 0 {
+		concurrency = defaultLogSplitConcurrency
+	}
+	s := &logSplitter{c: c, q: q, slots: make(chan struct{}, concurrency), cancel: cancel}
+	logs, err = s.split(ctx, from, to)
+	if err != nil {
+		// rather than the cancellation of the other queries
+		return nil, s.failure()
+	}
+	return logs, nil
+}
+
+// resolveLogRange returns the block range of q, a missing bound being the
+// latest block as for eth_getLogs.
+func (c *client) resolveLogRange(ctx context.Context, q ethereum.FilterQuery) (from, to uint64, err error) {
+	if q.FromBlock != nil && q.FromBlock.Sign() < 0 || q.ToBlock != nil && q.ToBlock.Sign() < 0 {
+		return 0, 0, errors.New("block tags can't be split")
+	}
+	if q.FromBlock == nil || q.ToBlock == nil {
+		head, err := c.BlockNumber(ctx)
+		if err != nil {
+			return 0, 0, err
+		}
+		from, to = head, head
+	}
+	if q.FromBlock != nil {
+		from = q.FromBlock.Uint64()
+	}
+	if q.ToBlock != nil {
+		to = q.ToBlock.Uint64()
+	}
+	return from, to, nil
+}
+
+// logSplitter queries the halves of a log query, with up to cap(slots)
+// queries at once.
+type logSplitter struct {
+	c      *client
+	q      ethereum.FilterQuery
+	slots  chan struct{}
+	cancel context.CancelFunc // of the other queries, on failure
+
+	mu  sync.Mutex
+	err error // first failure
+}
+
+// fail records the first failed query and cancels the others.
+func (s *logSplitter) fail(err error) {
+	s.mu.Lock()
+	if s.err == nil {
+		s.err = err
+	}
+	s.mu.Unlock()
+	s.cancel()
+}
+
+func (s *logSplitter) failure() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// split returns the logs of blocks from to to, queried in two halves.
+func (s *logSplitter) split(ctx context.Context, from, to uint64) ([]types.Log, error) {
+	mid := from + (to-from)/2
+	var left []types.Log
+	var leftErr error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		left, leftErr = s.query(ctx, from, mid)
+	}()
+	right, err := s.query(ctx, mid+1, to)
+	wg.Wait()
+	if leftErr != nil {
+		return nil, leftErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(left, right...), nil
+}
+
+// query returns the logs of blocks from to to, split again if the
+// provider rejects their range.
+func (s *logSplitter) query(ctx context.Context, from, to uint64) ([]types.Log, error) {
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		s.fail(ctx.Err())
+		return nil, ctx.Err()
+	}
+	logs, err := s.c.filterLogs(ctx, chunkQuery(s.q, from, to))
+	<-s.slots
+	if errors.Is(err, ErrLogRangeExceeded) && from < to {
+		return s.split(ctx, from, to)
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to filter logs of blocks %d to %d: %w", from, to, err)
+		s.fail(err)
+		return nil, err
+	}
+	return logs, nil
+}
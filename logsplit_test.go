@@ -0,0 +1,122 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// testRangeLogService returns a log per block, and rejects queries of more
+// than maxRange blocks like Infura does.
+type testRangeLogService struct {
+	maxRange  uint64
+	calls     atomic.Int64
+	active    atomic.Int64
+	maxActive atomic.Int64
+}
+
+func (s *testRangeLogService) GetLogs(f testFilter) ([]types.Log, error) {
+	s.calls.Add(1)
+	n := s.active.Add(1)
+	defer s.active.Add(-1)
+	for {
+		max := s.maxActive.Load()
+		if n <= max || s.maxActive.CompareAndSwap(max, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	if uint64(f.ToBlock-f.FromBlock)+1 > s.maxRange {
+		return nil, fmt.Errorf("query returned more than %d results", 10000)
+	}
+	var logs []types.Log
+	for b := uint64(f.FromBlock); b <= uint64(f.ToBlock); b++ {
+		logs = append(logs, types.Log{BlockNumber: b, Topics: []common.Hash{}, Data: []byte{}})
+	}
+	return logs, nil
+}
+
+func TestFilterLogsSplitsRange(t *testing.T) {
+	main, failover := &testRangeLogService{maxRange: 8}, &testRangeLogService{maxRange: 1000}
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1}, main),
+		newTestNodeWith(t, &testEthService{chainID: 1}, failover),
+	)
+	cfg.SplitLogQueries = true
+	cfg.LogSplitConcurrency = 2
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	logs, err := c.FilterLogs(context.Background(), ethereum.FilterQuery{FromBlock: big.NewInt(0), ToBlock: big.NewInt(99)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 100 {
+		t.Fatalf("got %d logs, want 100", len(logs))
+	}
+	for i, l := range logs {
+		if l.BlockNumber != uint64(i) {
+			t.Fatalf("log %d of block %d, want in block order", i, l.BlockNumber)
+		}
+	}
+	if n := failover.calls.Load(); n != 0 {
+		t.Fatalf("%d queries failed over, want them split", n)
+	}
+	if n := main.maxActive.Load(); n > 2 {
+		t.Fatalf("%d concurrent queries, want at most 2", n)
+	}
+}
+
+func TestFilterLogsSplitGivesUp(t *testing.T) {
+	main := &testRangeLogService{}
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1}, main),
+		newTestNodeWith(t, &testEthService{chainID: 1}, &testRangeLogService{}),
+	)
+	cfg.SplitLogQueries = true
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	_, err = c.FilterLogs(context.Background(), ethereum.FilterQuery{FromBlock: big.NewInt(0), ToBlock: big.NewInt(3)})
+	if !errors.Is(err, ErrLogRangeExceeded) {
+		t.Fatalf("FilterLogs = %v, want ErrLogRangeExceeded", err)
+	}
+	// the range and its halves, then single blocks until one fails
+	if n := main.calls.Load(); n < 4 || n > 7 {
+		t.Fatalf("%d queries, want the range split down to single blocks", n)
+	}
+}
+
+func TestIsLogRangeError(t *testing.T) {
+	if IsLogRangeError(errors.New("query returned more than 10000 results")) {
+		t.Error("a local error taken for a provider's")
+	}
+	if IsTransientError(testRPCError{code: -32005, msg: "query returned more than 10000 results"}) {
+		t.Error("log range error taken for a rate limit")
+	}
+	if !IsTransientError(testRPCError{code: -32005, msg: "daily request count exceeded"}) {
+		t.Error("rate limit not transient")
+	}
+}
+
+type testRPCError struct {
+	code int
+	msg  string
+}
+
+func (e testRPCError) Error() string  { return e.msg }
+func (e testRPCError) ErrorCode() int { return e.code }
@@ -0,0 +1,197 @@
+package ethclient
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maintenanceCheckInterval = 30 * time.Second
+
+// maintenanceWindow is a recurring period, described by a cron spec and a
+// duration, during which an endpoint is drained.
+type maintenanceWindow struct {
+	spec     string
+	minute   uint64 // bitsets of matching values
+	hour     uint64
+	dom      uint64
+	month    uint64
+	dow      uint64
+	anyDom   bool
+	anyDow   bool
+	duration time.Duration
+}
+
+// parseMaintenanceWindows parses ';' separated "<cron spec> <duration>"
+// windows, e.g. "0 3 * * 0 2h" for Sundays from 03:00 to 05:00 UTC.
+func parseMaintenanceWindows(specs string) ([]*maintenanceWindow, error) {
+	var windows []*maintenanceWindow
+	for _, spec := range strings.Split(specs, ";") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		w, err := parseMaintenanceWindow(spec)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+func parseMaintenanceWindow(spec string) (*maintenanceWindow, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("invalid maintenance window %q: expected 5 cron fields and a duration", spec)
+	}
+	w := &maintenanceWindow{
+		spec:   spec,
+		anyDom: strings.HasPrefix(fields[2], "*"),
+		anyDow: strings.HasPrefix(fields[4], "*"),
+	}
+	var err error
+	for i, f := range []struct {
+		dst      *uint64
+		min, max int
+	}{
+		{&w.minute, 0, 59},
+		{&w.hour, 0, 23},
+		{&w.dom, 1, 31},
+		{&w.month, 1, 12},
+		{&w.dow, 0, 7},
+	} {
+		if *f.dst, err = parseCronField(fields[i], f.min, f.max); err != nil {
+			return nil, fmt.Errorf("invalid maintenance window %q: %w", spec, err)
+		}
+	}
+	// both 0 and 7 mean sunday
+	if w.dow&(1<<7) != 0 {
+		w.dow |= 1
+	}
+	if w.duration, err = time.ParseDuration(fields[5]); err != nil || w.duration <= 0 {
+		return nil, fmt.Errorf("invalid maintenance window %q: bad duration %s", spec, fields[5])
+	}
+	return w, nil
+}
+
+// parseCronField parses a cron field ("*", "a", "a-b", "*/n", "a/n",
+// "a-b/n" and comma separated lists of those) into a bitset. Like cron,
+// "a/n" stands for "a-max/n".
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		i := strings.Index(part, "/")
+		if i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("bad step in %q", part)
+			}
+			rng, step = part[:i], n
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("bad value in %q", part)
+			}
+			hi = lo
+			if i >= 0 {
+				hi = max
+			}
+			if len(bounds) == 2 {
+				if hi, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("bad value in %q", part)
+				}
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q out of range [%d-%d]", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// matches reports whether the window starts at the minute of t.
+func (w *maintenanceWindow) matches(t time.Time) bool {
+	if w.minute&(1<<uint(t.Minute())) == 0 ||
+		w.hour&(1<<uint(t.Hour())) == 0 ||
+		w.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	dom := w.dom&(1<<uint(t.Day())) != 0
+	dow := w.dow&(1<<uint(t.Weekday())) != 0
+	// like cron, a restricted day of month and day of week match either
+	if !w.anyDom && !w.anyDow {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// active reports whether now falls into an occurrence of the window.
+func (w *maintenanceWindow) active(now time.Time) bool {
+	now = now.UTC()
+	for t := now.Truncate(time.Minute); now.Sub(t) < w.duration; t = t.Add(-time.Minute) {
+		if w.matches(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// setupMaintenance parses the endpoints' maintenance windows and starts a
+// background loop draining and restoring them on schedule.
+func (c *client) setupMaintenance() error {
+	var err error
+	if c.m.maintenance, err = parseMaintenanceWindows(c.cfg.RpcMaintenanceWindows); err != nil {
+		return err
+	}
+	if c.b.maintenance, err = parseMaintenanceWindows(c.cfg.FailoverRpcMaintenanceWindows); err != nil {
+		return err
+	}
+	if len(c.m.maintenance) == 0 && len(c.b.maintenance) == 0 {
+		return nil
+	}
+
+	c.updateMaintenance(time.Now())
+	c.goBackground(func() {
+		ticker := time.NewTicker(maintenanceCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.done:
+				return
+			case now := <-ticker.C:
+				c.updateMaintenance(now)
+			}
+		}
+	})
+	return nil
+}
+
+func (c *client) updateMaintenance(now time.Time) {
+	for _, e := range c.endpoints() {
+		var active *maintenanceWindow
+		for _, w := range e.maintenance {
+			if w.active(now) {
+				active = w
+				break
+			}
+		}
+		draining := active != nil
+		if e.draining.Swap(draining) == draining {
+			continue
+		}
+		if draining {
+			c.logger.Info().Msgf("draining rpc %s for maintenance window %q", e.name, active.spec)
+		} else {
+			c.logger.Info().Msgf("restoring rpc %s after maintenance", e.name)
+		}
+	}
+}
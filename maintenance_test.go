@@ -0,0 +1,129 @@
+package ethclient
+
+import (
+	"testing"
+	"time"
+)
+
+func bitsOf(values ...int) uint64 {
+	var bits uint64
+	for _, v := range values {
+		bits |= 1 << uint(v)
+	}
+	return bits
+}
+
+func TestParseCronField(t *testing.T) {
+	for _, tt := range []struct {
+		field    string
+		min, max int
+		want     uint64
+		wantErr  bool
+	}{
+		{field: "*", min: 0, max: 6, want: bitsOf(0, 1, 2, 3, 4, 5, 6)},
+		{field: "3", min: 0, max: 59, want: bitsOf(3)},
+		{field: "1-3", min: 0, max: 59, want: bitsOf(1, 2, 3)},
+		{field: "1,5,7", min: 0, max: 59, want: bitsOf(1, 5, 7)},
+		{field: "*/15", min: 0, max: 59, want: bitsOf(0, 15, 30, 45)},
+		{field: "5/10", min: 0, max: 59, want: bitsOf(5, 15, 25, 35, 45, 55)},
+		{field: "20/1", min: 0, max: 23, want: bitsOf(20, 21, 22, 23)},
+		{field: "10-20/5", min: 0, max: 59, want: bitsOf(10, 15, 20)},
+		{field: "1-2,*/6", min: 1, max: 12, want: bitsOf(1, 2, 7)},
+		{field: "60", min: 0, max: 59, wantErr: true},
+		{field: "0", min: 1, max: 31, wantErr: true},
+		{field: "5-3", min: 0, max: 59, wantErr: true},
+		{field: "*/0", min: 0, max: 59, wantErr: true},
+		{field: "*/x", min: 0, max: 59, wantErr: true},
+		{field: "a", min: 0, max: 59, wantErr: true},
+		{field: "", min: 0, max: 59, wantErr: true},
+	} {
+		got, err := parseCronField(tt.field, tt.min, tt.max)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseCronField(%q) error = %v, wantErr %v", tt.field, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseCronField(%q) = %b, want %b", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestMaintenanceWindowMatches(t *testing.T) {
+	// 2024-01-07 is a sunday
+	sunday := time.Date(2024, 1, 7, 3, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		spec string
+		at   time.Time
+		want bool
+	}{
+		{"0 3 * * 0 1h", sunday, true},
+		{"0 3 * * 7 1h", sunday, true},
+		{"0 3 * * 1 1h", sunday, false},
+		{"30 3 * * 0 1h", sunday, false},
+		{"0 4 * * * 1h", sunday, false},
+		{"0 3 * 2 * 1h", sunday, false},
+		// restricted day of month and day of week match either
+		{"0 3 1 * 0 1h", sunday, true},
+		{"0 3 7 * 1 1h", sunday, true},
+		{"0 3 1 * 1 1h", sunday, false},
+		// a stepped '*' is still unrestricted, so both have to match
+		{"0 3 */2 * 0 1h", sunday, true},
+		{"0 3 */2 * 1 1h", sunday, false},
+		{"0 3 2 * */2 1h", sunday, false},
+		{"*/15 * * * * 1m", sunday.Add(45 * time.Minute), true},
+		{"5/10 * * * * 1m", sunday.Add(25 * time.Minute), true},
+	} {
+		w, err := parseMaintenanceWindow(tt.spec)
+		if err != nil {
+			t.Fatalf("parseMaintenanceWindow(%q): %v", tt.spec, err)
+		}
+		if got := w.matches(tt.at); got != tt.want {
+			t.Errorf("%q matches(%s) = %v, want %v", tt.spec, tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestMaintenanceWindowActive(t *testing.T) {
+	w, err := parseMaintenanceWindow("0 3 * * 0 2h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 1, 7, 3, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		at   time.Time
+		want bool
+	}{
+		{start.Add(-time.Second), false},
+		{start, true},
+		{start.Add(59 * time.Minute), true},
+		{start.Add(2*time.Hour - time.Second), true},
+		{start.Add(2 * time.Hour), false},
+		{start.Add(24 * time.Hour), false},
+		// evaluated in UTC whatever the location
+		{start.In(time.FixedZone("UTC+2", 2*60*60)), true},
+	} {
+		if got := w.active(tt.at); got != tt.want {
+			t.Errorf("active(%s) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestParseMaintenanceWindows(t *testing.T) {
+	windows, err := parseMaintenanceWindows("0 3 * * 0 2h; 30 1 1 * * 15m ;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 2 {
+		t.Fatalf("got %d windows, want 2", len(windows))
+	}
+	for _, spec := range []string{
+		"0 3 * * 0",
+		"0 3 * * 0 0s",
+		"0 3 * * 0 soon",
+		"0 24 * * 0 1h",
+	} {
+		if _, err := parseMaintenanceWindows(spec); err == nil {
+			t.Errorf("parseMaintenanceWindows(%q) succeeded, want error", spec)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package ethclient
+
+import "strings"
+
+// methodList is the allow and deny lists of the methods of an endpoint,
+// see Config.RpcAllowMethods.
+type methodList struct {
+	allow []string // any method when empty
+	deny  []string
+}
+
+// allows reports whether method may be sent to the endpoint.
+func (l methodList) allows(method string) bool {
+	for _, pattern := range l.deny {
+		if matchMethod(pattern, method) {
+			return false
+		}
+	}
+	if len(l.allow) == 0 {
+		return true
+	}
+	for _, pattern := range l.allow {
+		if matchMethod(pattern, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchMethod reports whether method is pattern, or starts with it when it
+// ends with *.
+func matchMethod(pattern, method string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(method, prefix)
+	}
+	return pattern == method
+}
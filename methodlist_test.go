@@ -0,0 +1,64 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMethodListAllows(t *testing.T) {
+	l := methodList{allow: []string{"Balance*", "BlockNumber", "debug_*"}, deny: []string{"debug_traceCall"}}
+	for method, want := range map[string]bool{
+		"BalanceAt":         true,
+		"BlockNumber":       true,
+		"BlockNumberAt":     false,
+		"FilterLogs":        false,
+		"debug_traceBlock":  true,
+		"debug_traceCall":   false,
+		"optimism_rollupOk": false,
+	} {
+		if got := l.allows(method); got != want {
+			t.Errorf("allows(%s) = %v, want %v", method, got, want)
+		}
+	}
+	if !(methodList{}).allows("FilterLogs") {
+		t.Error("empty lists don't allow every method")
+	}
+}
+
+func TestMethodListsRouteCalls(t *testing.T) {
+	cfg := testConfig(newTestNodeWith(t, &testEthService{chainID: 1, head: 1}), newTestNodeWith(t, &testEthService{chainID: 1, head: 2}))
+	cfg.RpcDenyMethods = []string{"BlockNumber"}
+	cfg.FailoverRpcAllowMethods = []string{"Block*", "ChainID"}
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.(*client).Close()
+	ctx := context.Background()
+
+	if n, err := c.(*client).BlockNumber(ctx); err != nil || n != 2 {
+		t.Fatalf("BlockNumber = %d, %v, want 2 from the failover", n, err)
+	}
+	if _, err := c.BalanceAt(ctx, [20]byte{}, nil); err != nil {
+		t.Fatalf("BalanceAt = %v, want served by main", err)
+	}
+	if _, err := c.(*client).BlockNumber(WithEndpoint(ctx, "main")); !errors.Is(err, ErrMethodNotSupported) {
+		t.Fatalf("BlockNumber on main = %v, want ErrMethodNotSupported", err)
+	}
+
+	cfg.RpcDenyMethods = []string{"Balance*"}
+	c2, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c2.(*client).Close()
+	_, err = c2.BalanceAt(ctx, [20]byte{}, nil)
+	if !errors.Is(err, ErrMethodNotSupported) {
+		t.Fatalf("BalanceAt = %v, want ErrMethodNotSupported", err)
+	}
+	// a denied method isn't worth retrying later
+	if after, ok := RetryAfter(err); ok {
+		t.Fatalf("RetryAfter = %s, true for a denied method, want false", after)
+	}
+}
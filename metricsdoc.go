@@ -0,0 +1,157 @@
+package ethclient
+
+// MetricDescription describes a metric emitted by the client, e.g. for
+// tooling generating dashboards and alerts. Every metric also has the app
+// and chain const labels.
+type MetricDescription struct {
+	Name   string   `json:"name"`
+	Type   string   `json:"type"` // counter, gauge or histogram
+	Unit   string   `json:"unit,omitempty"`
+	Labels []string `json:"labels"`
+	Help   string   `json:"help"`
+	// what an observation means and how to use the metric
+	Description string `json:"description"`
+}
+
+var metricDescriptions = []MetricDescription{
+	{
+		Name:        "rpc_request_total",
+		Type:        "counter",
+		Labels:      []string{labelMethod, labelClient, labelSuccess},
+		Help:        "RPC requests counts",
+		Description: "Attempts of calls against an endpoint (client), each retry and failover counting once. success is false for failed attempts, NotFound results count as successes for NotFoundSuccessMethods or as classified by SuccessClassifier. Alert on the ratio of failed attempts per client.",
+	},
+	{
+		Name:        "rpc_latency_milliseconds",
+		Type:        "histogram",
+		Unit:        "milliseconds",
+		Labels:      []string{labelMethod, labelClient, labelSuccess},
+		Help:        "RPC request latency in milliseconds",
+		Description: "Duration of the attempts counted in rpc_request_total, buckets set by LatencyBucketsMs. With a Tracer, observations of sampled spans carry their trace_id as exemplar, exposed in the OpenMetrics format.",
+	},
+	{
+		Name:        "rpc_open_connections",
+		Type:        "gauge",
+		Labels:      []string{labelClient},
+		Help:        "Open connections per RPC endpoint",
+		Description: "Connections currently open to the endpoint, including retired ones finishing calls after a url rotation. 0 while idle connections are reaped or the endpoint isn't dialed yet.",
+	},
+	{
+		Name:        "rpc_active_subscriptions",
+		Type:        "gauge",
+		Labels:      []string{labelClient},
+		Help:        "Active subscriptions per RPC endpoint",
+		Description: "Subscriptions currently served by the endpoint.",
+	},
+	{
+		Name:        "rpc_client_goroutines",
+		Type:        "gauge",
+		Labels:      []string{},
+		Help:        "Background goroutines owned by the RPC client",
+		Description: "Goroutines of the head watcher, verification, reaper and other background loops, a steady increase points to a leak.",
+	},
+	{
+		Name:        "rpc_result_mismatch_total",
+		Type:        "counter",
+		Labels:      []string{labelMethod},
+		Help:        "Consensus reads where the RPC endpoints disagreed",
+		Description: "Consensus reads (ConsensusMethods), log backfills and finalized block polls (CheckFinality, method FinalizedPoll) where the endpoints returned different results, a sign that one of the providers serves stale or wrong data.",
+	},
+	{
+		Name:        "rpc_promotions_total",
+		Type:        "counter",
+		Labels:      []string{labelClient},
+		Help:        "Promotions of an RPC endpoint to primary",
+		Description: "Times the endpoint was promoted to primary on latency or by adaptive routing. Frequent promotions mean the endpoints perform alike.",
+	},
+	{
+		Name:        "rpc_cache_requests_total",
+		Type:        "counter",
+		Labels:      []string{labelMethod, labelResult},
+		Help:        "Lookups of the response cache by result (hit or miss), and stale answers of the failsafe cache",
+		Description: "Lookups of the response cache with result hit or miss, and answers of the failsafe cache during an outage of every endpoint with result stale. Alert on any stale answer.",
+	},
+	{
+		Name:        "rpc_coalesced_requests_total",
+		Type:        "counter",
+		Labels:      []string{labelMethod},
+		Help:        "Calls served by an identical call already in flight",
+		Description: "Calls that shared the upstream call of an identical call in flight (CoalesceCalls) instead of calling an endpoint.",
+	},
+	{
+		Name:        "rpc_preferred_endpoint",
+		Type:        "gauge",
+		Labels:      []string{labelClient},
+		Help:        "1 for the RPC endpoint tried first, 0 for the others",
+		Description: "The endpoint tried first by calls, which changes with promotions, adaptive routing and UpdateEndpoints.",
+	},
+	{
+		Name:        "rpc_transaction_inclusion_seconds",
+		Type:        "histogram",
+		Unit:        "seconds",
+		Labels:      []string{},
+		Help:        "Time from sending to the first receipt of transactions of SendTransactionAndWait",
+		Description: "Time for transactions sent with SendTransactionAndWait to be included in a block, without the confirmations waited for.",
+	},
+	{
+		Name:        "rpc_transaction_rebroadcasts_total",
+		Type:        "counter",
+		Labels:      []string{labelClient},
+		Help:        "Transactions broadcast again after no RPC endpoint knew them",
+		Description: "Transactions of SendTransactionAndWait sent again to the endpoint after every endpoint forgot them, e.g. dropped from the mempools.",
+	},
+	{
+		Name:        "rpc_endpoint_block_height",
+		Type:        "gauge",
+		Labels:      []string{labelClient},
+		Help:        "Last block height polled from the RPC endpoint",
+		Description: "Block height of the endpoint polled by the head watcher (HeadPollIntervalMs), not exported without it.",
+	},
+	{
+		Name:        "rpc_endpoint_block_lag",
+		Type:        "gauge",
+		Labels:      []string{labelClient},
+		Help:        "Blocks the RPC endpoint is behind the highest polled block height",
+		Description: "Blocks between the endpoint's height and the highest height among the endpoints, polled by the head watcher. Alert on a lagging primary before it serves stale data.",
+	},
+	{
+		Name:        "rpc_endpoint_healthy",
+		Type:        "gauge",
+		Labels:      []string{labelClient},
+		Help:        "1 if the RPC endpoint is available and its last call succeeded, 0 otherwise",
+		Description: "Health of the endpoint as reported by Status, updated on every call and head poll: 0 when it is skipped by routing (drained, sidelined, serving another chain, failing to dial) or its last call failed.",
+	},
+	{
+		Name:        "rpc_failover_total",
+		Type:        "counter",
+		Labels:      []string{labelMethod, labelClient},
+		Help:        "Calls failing over to the RPC endpoint after the previous one failed",
+		Description: "Calls tried on the endpoint (client) after the endpoint before it in routing order failed, the failover rate of a method.",
+	},
+	{
+		Name:        "rpc_response_bytes_total",
+		Type:        "counter",
+		Unit:        "bytes",
+		Labels:      []string{labelMethod, labelClient},
+		Help:        "Bytes of the HTTP responses of the RPC endpoint",
+		Description: "Decoded bytes of the HTTP response bodies of the attempts of a method against the endpoint (client), to see which methods dominate the bandwidth billed by a provider. Websocket endpoints and rpc clients of WithRPCClients aren't measured.",
+	},
+	{
+		Name:        "rpc_stale_reads_total",
+		Type:        "counter",
+		Labels:      []string{labelMethod, labelClient},
+		Help:        "Reads of the latest state kept from the RPC endpoint for lagging behind the head",
+		Description: "Reads of the latest state or block that skipped the endpoint (client) for being more than MaxHeadLagBlocks behind the highest polled height, whether another endpoint served them or they failed with ErrStaleEndpoint.",
+	},
+}
+
+// MetricDescriptions describes every metric the client emits, in a form
+// suited to generate dashboards and alert templates (e.g. encoded as JSON).
+func MetricDescriptions() []MetricDescription {
+	descriptions := make([]MetricDescription, len(metricDescriptions))
+	for i, d := range metricDescriptions {
+		d.Labels = append([]string{}, d.Labels...)
+		descriptions[i] = d
+	}
+	return descriptions
+}
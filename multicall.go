@@ -0,0 +1,155 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	// DefaultMulticallAddress is where Multicall3 is deployed on most chains.
+	DefaultMulticallAddress = "0xcA11bde05977b3631167028862bE2a173976CA11"
+	defaultMulticallSize    = 500
+)
+
+// ErrMulticallReverted is returned for the calls of MulticallContract
+// that reverted.
+var ErrMulticallReverted = errors.New("call reverted")
+
+var multicallABI = func() abi.ABI {
+	a, err := abi.JSON(strings.NewReader(`[{"name":"aggregate3","type":"function","stateMutability":"payable",
+		"inputs":[{"name":"calls","type":"tuple[]","components":[
+			{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}]}],
+		"outputs":[{"name":"returnData","type":"tuple[]","components":[
+			{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}]}]}]`))
+	if err != nil {
+		panic(err)
+	}
+	return a
+}()
+
+// multicall3Call and multicall3Result mirror the Call3 and Result structs
+// of Multicall3.
+type multicall3Call struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type multicall3Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// MulticallContract executes the read-only calls at blockNumber, the
+// latest block when nil, packed in aggregate3 calls to the Multicall3
+// contract of Config.MulticallAddress, with the retries and failover of
+// single calls. The results are in the order of calls, those of failed
+// calls are nil and their errors, ErrMulticallReverted for reverts, joined
+// in the returned error. The calls are made by the multicall contract:
+// their From, Gas and Value are ignored. Where the contract isn't
+// deployed, the calls are made one by one with CallContract.
+func (c *client) MulticallContract(ctx context.Context, calls []ethereum.CallMsg, blockNumber *big.Int) ([][]byte, error) {
+	for i, call := range calls {
+		if call.To == nil {
+			return nil, fmt.Errorf("call %d: no contract address", i)
+		}
+	}
+	results := make([][]byte, len(calls))
+	errs := make([]error, len(calls))
+	size := c.multicallSize()
+	for start := 0; start < len(calls); start += size {
+		end := start + size
+		if end > len(calls) {
+			end = len(calls)
+		}
+		if !c.multicallAbsent.Load() {
+			err := c.multicall(ctx, calls[start:end], blockNumber, results[start:end], errs[start:end])
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, errMulticallAbsent) {
+				return nil, err
+			}
+			// a contract deployed later may be missing at past blocks only
+			if blockNumber == nil {
+				c.multicallAbsent.Store(true)
+			}
+			c.logger.Warn().Msgf("no multicall contract at %s, making calls one by one", c.multicallAddress())
+		}
+		for i := start; i < end; i++ {
+			results[i], errs[i] = c.CallContract(ctx, calls[i], blockNumber)
+		}
+	}
+	var joined []error
+	for i, err := range errs {
+		if err != nil {
+			joined = append(joined, fmt.Errorf("call %d to %s: %w", i, calls[i].To, err))
+		}
+	}
+	return results, errors.Join(joined...)
+}
+
+// errMulticallAbsent is returned by multicall when the multicall contract
+// has no code at the block of the calls.
+var errMulticallAbsent = errors.New("multicall contract not deployed")
+
+// multicall executes calls in a single aggregate3 call, and sets their
+// results and errors.
+func (c *client) multicall(ctx context.Context, calls []ethereum.CallMsg, blockNumber *big.Int, results [][]byte, errs []error) error {
+	packed := make([]multicall3Call, len(calls))
+	for i, call := range calls {
+		packed[i] = multicall3Call{Target: *call.To, AllowFailure: true, CallData: call.Data}
+	}
+	input, err := multicallABI.Pack("aggregate3", packed)
+	if err != nil {
+		return err
+	}
+	to := c.multicallAddress()
+	msg := ethereum.CallMsg{To: &to, Data: input}
+	output, err := do(c.withStateBlock(ctx, blockNumber), c, "MulticallContract", func(ctx context.Context, ec *ethclient.Client) ([]byte, error) {
+		return ec.CallContract(ctx, msg, blockNumber)
+	})
+	if err != nil {
+		return err
+	}
+	if len(output) == 0 {
+		return errMulticallAbsent
+	}
+	var decoded []multicall3Result
+	if err := multicallABI.UnpackIntoInterface(&decoded, "aggregate3", output); err != nil {
+		return fmt.Errorf("invalid multicall output: %w", err)
+	}
+	if len(decoded) != len(calls) {
+		return fmt.Errorf("invalid multicall output: %d results for %d calls", len(decoded), len(calls))
+	}
+	for i, r := range decoded {
+		if r.Success {
+			results[i] = r.ReturnData
+		} else {
+			errs[i] = ErrMulticallReverted
+		}
+	}
+	return nil
+}
+
+func (c *client) multicallAddress() common.Address {
+	if c.cfg.MulticallAddress != "" {
+		return common.HexToAddress(c.cfg.MulticallAddress)
+	}
+	return common.HexToAddress(DefaultMulticallAddress)
+}
+
+func (c *client) multicallSize() int {
+	if c.cfg.MulticallSize > 0 {
+		return c.cfg.MulticallSize
+	}
+	return defaultMulticallSize
+}
@@ -0,0 +1,125 @@
+package ethclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// testCallService serves eth_call: contracts return their call data
+// reversed, and revert on empty call data. The multicall contract, when
+// deployed, runs aggregate3.
+type testCallService struct {
+	multicall bool
+	calls     atomic.Int64
+}
+
+type testCallArgs struct {
+	To    *common.Address `json:"to"`
+	Data  hexutil.Bytes   `json:"data"`
+	Input hexutil.Bytes   `json:"input"`
+}
+
+func (s *testCallService) Call(args testCallArgs, _ string) (hexutil.Bytes, error) {
+	s.calls.Add(1)
+	data := args.Data
+	if len(args.Input) > 0 {
+		data = args.Input
+	}
+	if *args.To != common.HexToAddress(DefaultMulticallAddress) {
+		return testContractCall(data)
+	}
+	if !s.multicall {
+		return nil, nil
+	}
+	in, err := multicallABI.Methods["aggregate3"].Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	var calls []multicall3Call
+	if err := multicallABI.Methods["aggregate3"].Inputs.Copy(&calls, in); err != nil {
+		return nil, err
+	}
+	results := make([]multicall3Result, len(calls))
+	for i, call := range calls {
+		out, err := testContractCall(call.CallData)
+		results[i] = multicall3Result{Success: err == nil, ReturnData: out}
+	}
+	return multicallABI.Methods["aggregate3"].Outputs.Pack(results)
+}
+
+func testContractCall(data []byte) (hexutil.Bytes, error) {
+	if len(data) == 0 {
+		return nil, errors.New("execution reverted")
+	}
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[len(data)-1-i] = b
+	}
+	return out, nil
+}
+
+func TestMulticallContract(t *testing.T) {
+	for _, deployed := range []bool{true, false} {
+		svc := &testCallService{multicall: deployed}
+		cfg := testConfig(newTestNodeWith(t, &testEthService{chainID: 1}, svc), newTestNode(t, 1))
+		cfg.MulticallSize = 2
+		c, err := New("test", "eth", cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.(*client).Close()
+
+		to := common.HexToAddress("0x01")
+		calls := []ethereum.CallMsg{
+			{To: &to, Data: []byte{1, 2}},
+			{To: &to},
+			{To: &to, Data: []byte{3}},
+		}
+		results, err := c.MulticallContract(context.Background(), calls, nil)
+		if deployed && !errors.Is(err, ErrMulticallReverted) {
+			t.Fatalf("deployed: error = %v, want ErrMulticallReverted", err)
+		}
+		if err == nil {
+			t.Fatalf("deployed %v: no error for the reverted call", deployed)
+		}
+		if !bytes.Equal(results[0], []byte{2, 1}) || results[1] != nil || !bytes.Equal(results[2], []byte{3}) {
+			t.Fatalf("deployed %v: results = %x", deployed, results)
+		}
+		// the calls go in 2 aggregate3 calls, or one by one after the
+		// multicall contract was found missing
+		want := int64(2)
+		if !deployed {
+			want = 1 + 3
+		}
+		if n := svc.calls.Load(); n != want {
+			t.Fatalf("deployed %v: %d eth_call, want %d", deployed, n, want)
+		}
+		if _, err := c.MulticallContract(context.Background(), calls[:1], big.NewInt(1)); err != nil {
+			t.Fatal(err)
+		}
+		if !deployed {
+			if n := svc.calls.Load(); n != want+1 {
+				t.Fatalf("multicall contract tried again after it was found missing")
+			}
+		}
+	}
+}
+
+func TestMulticallContractRequiresAddress(t *testing.T) {
+	c, err := New("test", "eth", testConfig(newTestNode(t, 1), newTestNode(t, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.(*client).Close()
+	if _, err := c.MulticallContract(context.Background(), []ethereum.CallMsg{{}}, nil); err == nil {
+		t.Fatal("no error for a call without contract address")
+	}
+}
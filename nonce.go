@@ -0,0 +1,159 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// NonceStatus is the view of every endpoint on the nonces of an account.
+type NonceStatus struct {
+	Account   common.Address
+	Endpoints []EndpointNonces
+}
+
+// EndpointNonces are the latest (mined) and pending nonces of an account
+// according to an endpoint, or the error that endpoint returned.
+type EndpointNonces struct {
+	Endpoint string
+	Latest   uint64
+	Pending  uint64
+	Err      error
+}
+
+// Stuck returns the range [from, to) of the nonces of transactions pending
+// but not mined, according to the endpoints furthest ahead.
+func (s *NonceStatus) Stuck() (from, to uint64) {
+	for _, e := range s.Endpoints {
+		if e.Err != nil {
+			continue
+		}
+		if e.Latest > from {
+			from = e.Latest
+		}
+		if e.Pending > to {
+			to = e.Pending
+		}
+	}
+	if to < from {
+		to = from
+	}
+	return from, to
+}
+
+// Diverged reports whether the endpoints disagree on the pending nonce,
+// i.e. some of them miss pending transactions of the account.
+func (s *NonceStatus) Diverged() bool {
+	var pending []uint64
+	for _, e := range s.Endpoints {
+		if e.Err == nil {
+			pending = append(pending, e.Pending)
+		}
+	}
+	for _, p := range pending {
+		if p != pending[0] {
+			return true
+		}
+	}
+	return false
+}
+
+// InspectNonces fetches the latest and pending nonces of account from every
+// available endpoint, both in one batch request per endpoint.
+func (c *client) InspectNonces(ctx context.Context, account common.Address) (*NonceStatus, error) {
+	endpoints := c.availableEndpoints()
+	status := &NonceStatus{Account: account, Endpoints: make([]EndpointNonces, len(endpoints))}
+	var wg sync.WaitGroup
+	for i, e := range endpoints {
+		wg.Add(1)
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+			n := &status.Endpoints[i]
+			n.Endpoint = e.name
+			n.Err = c.try(ctx, "InspectNonces", e, false, func(ctx context.Context, _ *ethclient.Client) error {
+				var latest, pending hexutil.Uint64
+				batch := []rpc.BatchElem{
+					{Method: "eth_getTransactionCount", Args: []interface{}{account, "latest"}, Result: &latest},
+					{Method: "eth_getTransactionCount", Args: []interface{}{account, "pending"}, Result: &pending},
+				}
+				if err := rpcClientFromContext(ctx).BatchCallContext(ctx, batch); err != nil {
+					return err
+				}
+				for _, elem := range batch {
+					if elem.Error != nil {
+						return elem.Error
+					}
+				}
+				n.Latest, n.Pending = uint64(latest), uint64(pending)
+				return nil
+			})
+		}(i, e)
+	}
+	wg.Wait()
+	for _, n := range status.Endpoints {
+		if n.Err == nil {
+			return status, nil
+		}
+	}
+	return status, fmt.Errorf("failed to inspect nonces of %s on all rpc endpoints", account)
+}
+
+// CancelStuckTransactions replaces the stuck transactions of status, see
+// NonceStatus.Stuck, with empty transfers of the account to itself, signed
+// by sign. Their fees are the suggested ones raised by feeBumpPercent, which
+// has to outbid the stuck transactions by at least 10% to replace them. The
+// transactions sent are returned, along with the first error.
+func (c *client) CancelStuckTransactions(ctx context.Context, status *NonceStatus, sign bind.SignerFn, feeBumpPercent int) ([]*types.Transaction, error) {
+	from, to := status.Stuck()
+	if from == to {
+		return nil, nil
+	}
+	chainID, err := c.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts := &bind.TransactOpts{From: status.Account}
+	if err := c.fillFees(ctx, opts); err != nil {
+		return nil, err
+	}
+	bump := func(x *big.Int) *big.Int {
+		if x == nil {
+			return nil
+		}
+		x = new(big.Int).Mul(x, big.NewInt(int64(100+feeBumpPercent)))
+		return x.Div(x, big.NewInt(100))
+	}
+	var sent []*types.Transaction
+	for nonce := from; nonce < to; nonce++ {
+		var tx *types.Transaction
+		if opts.GasPrice != nil {
+			tx = types.NewTx(&types.LegacyTx{Nonce: nonce, To: &status.Account, Gas: 21000, GasPrice: bump(opts.GasPrice)})
+		} else {
+			tx = types.NewTx(&types.DynamicFeeTx{
+				ChainID:   chainID,
+				Nonce:     nonce,
+				To:        &status.Account,
+				Gas:       21000,
+				GasTipCap: bump(opts.GasTipCap),
+				GasFeeCap: bump(opts.GasFeeCap),
+			})
+		}
+		signed, err := sign(status.Account, tx)
+		if err != nil {
+			return sent, fmt.Errorf("failed to sign cancellation of nonce %d: %w", nonce, err)
+		}
+		if err := c.SendTransaction(ctx, signed); err != nil {
+			return sent, fmt.Errorf("failed to cancel nonce %d: %w", nonce, err)
+		}
+		sent = append(sent, signed)
+	}
+	return sent, nil
+}
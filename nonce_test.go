@@ -0,0 +1,96 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testNonceService reports fixed latest and pending nonces, and records
+// the transactions sent.
+type testNonceService struct {
+	latest, pending uint64
+
+	mu   sync.Mutex
+	sent []*types.Transaction
+}
+
+func (s *testNonceService) GetTransactionCount(_ common.Address, block string) hexutil.Uint64 {
+	if block == "pending" {
+		return hexutil.Uint64(s.pending)
+	}
+	return hexutil.Uint64(s.latest)
+}
+
+func (s *testNonceService) SendRawTransaction(data hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return common.Hash{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, tx)
+	return tx.Hash(), nil
+}
+
+func TestNonceStatus(t *testing.T) {
+	s := &NonceStatus{Endpoints: []EndpointNonces{
+		{Endpoint: "main", Latest: 5, Pending: 8},
+		{Endpoint: "failover", Latest: 6, Pending: 6},
+	}}
+	if from, to := s.Stuck(); from != 6 || to != 8 {
+		t.Fatalf("Stuck() = [%d, %d), want [6, 8)", from, to)
+	}
+	if !s.Diverged() {
+		t.Fatal("Diverged() = false with different pending nonces")
+	}
+}
+
+func TestCancelStuckTransactions(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	opts, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	primary := &testNonceService{latest: 3, pending: 5}
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1}, primary, testFeeService{baseFee: big.NewInt(10)}),
+		newTestNodeWith(t, &testEthService{chainID: 1}, &testNonceService{latest: 3, pending: 3}),
+	)
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	status, err := c.InspectNonces(context.Background(), account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Endpoints) != 2 || !status.Diverged() {
+		t.Fatalf("status = %+v, want both endpoints, diverged", status)
+	}
+	sent, err := c.CancelStuckTransactions(context.Background(), status, opts.Signer, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 2 || len(primary.sent) != 2 {
+		t.Fatalf("sent %d cancellations, %d received, want 2", len(sent), len(primary.sent))
+	}
+	for i, tx := range primary.sent {
+		if tx.Nonce() != uint64(3+i) || *tx.To() != account || tx.GasTipCap().Int64() != 2 || tx.GasFeeCap().Int64() != 26 {
+			t.Errorf("cancellation %d: nonce %d, to %s, tip %s, fee cap %s", i, tx.Nonce(), tx.To(), tx.GasTipCap(), tx.GasFeeCap())
+		}
+	}
+}
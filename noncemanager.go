@@ -0,0 +1,175 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// nonceManager assigns the nonces of accounts locally, so that a burst of
+// transactions doesn't depend on the pending nonce of whichever endpoint
+// serves each of them. Accounts are reconciled with the endpoints on first
+// use and after nonce errors.
+type nonceManager struct {
+	mu       sync.Mutex
+	accounts map[common.Address]*managedNonce
+}
+
+type managedNonce struct {
+	mu       sync.Mutex
+	synced   bool
+	next     uint64
+	released []uint64 // assigned but not sent, reused first
+}
+
+func newNonceManager() *nonceManager {
+	return &nonceManager{accounts: make(map[common.Address]*managedNonce)}
+}
+
+func (m *nonceManager) account(account common.Address) *managedNonce {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := m.accounts[account]
+	if n == nil {
+		n = &managedNonce{}
+		m.accounts[account] = n
+	}
+	return n
+}
+
+// NextNonce returns the nonce of the next transaction of account. With
+// Config.ManageNonces, nonces are assigned locally from the highest pending
+// nonce of the endpoints, otherwise it is the pending nonce of the primary.
+func (c *client) NextNonce(ctx context.Context, account common.Address) (uint64, error) {
+	if c.nonces == nil {
+		return c.PendingNonceAt(ctx, account)
+	}
+	n := c.nonces.account(account)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.synced {
+		pending, err := c.reconcileNonce(ctx, account)
+		if err != nil {
+			return 0, err
+		}
+		// transactions still in flight may not be pending anywhere yet
+		if pending > n.next {
+			n.next = pending
+		}
+		n.released = n.released[:0]
+		n.synced = true
+	}
+	if len(n.released) > 0 {
+		nonce := n.released[0]
+		n.released = n.released[1:]
+		return nonce, nil
+	}
+	nonce := n.next
+	n.next++
+	return nonce, nil
+}
+
+// reconcileNonce returns the highest pending nonce of account among the
+// endpoints, a lagging endpoint may not have seen its latest transactions.
+func (c *client) reconcileNonce(ctx context.Context, account common.Address) (uint64, error) {
+	status, err := c.InspectNonces(ctx, account)
+	if err != nil {
+		return 0, err
+	}
+	var pending uint64
+	for _, e := range status.Endpoints {
+		if e.Err == nil && e.Pending > pending {
+			pending = e.Pending
+		}
+	}
+	return pending, nil
+}
+
+// SendTransactionWithNonce sends the transaction built by build, which
+// should sign it, with the next nonce of from.
+func (c *client) SendTransactionWithNonce(ctx context.Context, from common.Address, build func(nonce uint64) (*types.Transaction, error)) (*types.Transaction, error) {
+	nonce, err := c.NextNonce(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonce: %w", err)
+	}
+	tx, err := build(nonce)
+	if err != nil {
+		c.nonces.release(from, nonce)
+		return nil, err
+	}
+	if tx.Nonce() != nonce {
+		c.nonces.release(from, nonce)
+		return nil, fmt.Errorf("built transaction has nonce %d instead of %d", tx.Nonce(), nonce)
+	}
+	return tx, c.SendTransaction(ctx, tx)
+}
+
+// observeSend updates the nonces of the sender of tx after it was sent:
+// the nonce is released when no endpoint accepted the transaction, and the
+// account is reconciled again when its nonce was off or it is unclear
+// whether the transaction went through.
+func (m *nonceManager) observeSend(ctx context.Context, tx *types.Transaction, err error) {
+	if m == nil || err == nil || isKnownTxError(err) {
+		return
+	}
+	sender, serr := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if serr != nil {
+		return
+	}
+	if ctx.Err() != nil || errors.Is(err, ErrAttemptTimeout) || isNonceError(err) {
+		m.resync(sender)
+		return
+	}
+	m.release(sender, tx.Nonce())
+}
+
+// release makes nonce, assigned to account but not sent, available again.
+func (m *nonceManager) release(account common.Address, nonce uint64) {
+	if m == nil {
+		return
+	}
+	n := m.account(account)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.synced || nonce >= n.next {
+		return
+	}
+	if nonce == n.next-1 {
+		n.next--
+		return
+	}
+	for _, r := range n.released {
+		if r == nonce {
+			return
+		}
+	}
+	n.released = append(n.released, nonce)
+	sort.Slice(n.released, func(i, j int) bool { return n.released[i] < n.released[j] })
+}
+
+func (m *nonceManager) resync(account common.Address) {
+	n := m.account(account)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.synced = false
+}
+
+func isNonceError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "nonce too low") ||
+		strings.Contains(msg, "replacement transaction underpriced") ||
+		strings.Contains(msg, "nonce too high")
+}
+
+// isKnownTxError reports whether err means the transaction is already in
+// the pool, i.e. it was sent.
+func isKnownTxError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "already known") || strings.Contains(msg, "known transaction")
+}
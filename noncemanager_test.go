@@ -0,0 +1,91 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestNonceManagerRelease(t *testing.T) {
+	m := newNonceManager()
+	n := m.account(common.Address{1})
+	n.synced, n.next = true, 10
+	m.release(common.Address{1}, 9)
+	m.release(common.Address{1}, 5)
+	m.release(common.Address{1}, 3)
+	m.release(common.Address{1}, 5)
+	m.release(common.Address{1}, 12)
+	if n.next != 9 || len(n.released) != 2 || n.released[0] != 3 || n.released[1] != 5 {
+		t.Fatalf("next = %d, released = %v, want 9 and [3 5]", n.next, n.released)
+	}
+}
+
+func TestSendTransactionWithNonce(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	build := func(nonce uint64) (*types.Transaction, error) {
+		return types.SignNewTx(key, signer, &types.LegacyTx{Nonce: nonce, To: &from, Gas: 21000, GasPrice: big.NewInt(1)})
+	}
+
+	main, backup := &testNonceService{pending: 5}, &testNonceService{pending: 7}
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1}, main),
+		newTestNodeWith(t, &testEthService{chainID: 1}, backup),
+	)
+	cfg.ManageNonces = true
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for want := uint64(7); want < 10; want++ {
+		tx, err := c.SendTransactionWithNonce(context.Background(), from, build)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tx.Nonce() != want {
+			t.Fatalf("nonce = %d, want %d from the backup's pending nonce", tx.Nonce(), want)
+		}
+	}
+	if len(main.sent) != 3 {
+		t.Fatalf("main got %d transactions, want 3", len(main.sent))
+	}
+}
+
+func TestSendTransactionWithNonceReleases(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1}, &testTxPool{reject: true}),
+		newTestNodeWith(t, &testEthService{chainID: 1}, &testTxPool{reject: true}),
+	)
+	cfg.ManageNonces = true
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	_, err = c.SendTransactionWithNonce(context.Background(), from, func(nonce uint64) (*types.Transaction, error) {
+		return types.SignNewTx(key, signer, &types.LegacyTx{Nonce: nonce, To: &from, Gas: 21000, GasPrice: big.NewInt(1)})
+	})
+	if err == nil {
+		t.Fatal("rejected transaction was sent")
+	}
+	if nonce, err := c.NextNonce(context.Background(), from); err != nil || nonce != 0 {
+		t.Fatalf("NextNonce = %d, %v, want the released 0", nonce, err)
+	}
+}
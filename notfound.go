@@ -0,0 +1,61 @@
+package ethclient
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+// notFoundMessages are substrings of the errors some providers return
+// instead of a null result for missing blocks, headers, transactions or
+// receipts.
+var notFoundMessages = []string{
+	"block not found",
+	"header not found",
+	"transaction not found",
+	"receipt not found",
+	"unknown block",
+}
+
+// normalizeNotFound turns the "not found" errors of providers into
+// ethereum.NotFound, as returned by go-ethereum for a null result, so that
+// callers see the same error whichever endpoint answered. Messages from
+// Config.NotFoundErrors are matched too.
+func (c *client) normalizeNotFound(err error) error {
+	if err == nil || errors.Is(err, ethereum.NotFound) || isMethodNotFound(err) {
+		return err
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range notFoundMessages {
+		if strings.Contains(msg, m) {
+			return ethereum.NotFound
+		}
+	}
+	for _, m := range c.cfg.NotFoundErrors {
+		if strings.Contains(msg, strings.ToLower(m)) {
+			return ethereum.NotFound
+		}
+	}
+	return err
+}
+
+// successful classifies the outcome of an attempt for metrics and request
+// statistics: NotFound counts as a success for Config.NotFoundSuccessMethods,
+// e.g. receipts of pending transactions, and Config.SuccessClassifier has
+// the last word.
+func (c *client) successful(method string, err error) bool {
+	ok := err == nil
+	if !ok && errors.Is(err, ethereum.NotFound) {
+		for _, m := range c.cfg.NotFoundSuccessMethods {
+			if m == method {
+				ok = true
+				break
+			}
+		}
+	}
+	if c.cfg.SuccessClassifier != nil {
+		return c.cfg.SuccessClassifier(method, err, ok)
+	}
+	return ok
+}
@@ -0,0 +1,136 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestNormalizeNotFound(t *testing.T) {
+	c := &client{cfg: &Config{NotFoundErrors: []string{"No Such Tx"}}}
+	for _, tt := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{ethereum.NotFound, true},
+		{errors.New("header not found"), true},
+		{errors.New("Transaction Not Found"), true},
+		{errors.New("no such tx in pool"), true},
+		{codeError{-32601}, false},
+		{errors.New("execution reverted"), false},
+	} {
+		if got := errors.Is(c.normalizeNotFound(tt.err), ethereum.NotFound); got != tt.want {
+			t.Errorf("normalizeNotFound(%v) is NotFound = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+// testMissingBlocks has no block, reported as an error rather than null
+// when err is set.
+type testMissingBlocks struct {
+	err error
+}
+
+func (s testMissingBlocks) GetBlockByNumber(_ string, _ bool) (*types.Header, error) {
+	return nil, s.err
+}
+
+func TestNotFoundAcrossEndpoints(t *testing.T) {
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1}, testMissingBlocks{err: errors.New("header not found")}),
+		newTestNodeWith(t, &testEthService{chainID: 1}, testMissingBlocks{}),
+	)
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	_, err = c.HeaderByNumber(context.Background(), big.NewInt(100))
+	var fe *FailoverError
+	if !errors.As(err, &fe) {
+		t.Fatalf("HeaderByNumber error = %v, want a FailoverError", err)
+	}
+	for _, a := range fe.Attempts {
+		if a.Err != ethereum.NotFound {
+			t.Errorf("rpc %s error = %v, want ethereum.NotFound", a.Endpoint, a.Err)
+		}
+	}
+}
+
+func TestSuccessClassification(t *testing.T) {
+	c := &client{cfg: &Config{NotFoundSuccessMethods: []string{"TransactionReceipt"}}}
+	for _, tt := range []struct {
+		method string
+		err    error
+		want   bool
+	}{
+		{"TransactionReceipt", nil, true},
+		{"TransactionReceipt", ethereum.NotFound, true},
+		{"TransactionReceipt", errors.New("internal error"), false},
+		{"BlockByHash", ethereum.NotFound, false},
+	} {
+		if got := c.successful(tt.method, tt.err); got != tt.want {
+			t.Errorf("successful(%s, %v) = %v, want %v", tt.method, tt.err, got, tt.want)
+		}
+	}
+
+	c.cfg.SuccessClassifier = func(method string, err error, successful bool) bool {
+		return successful || errors.Is(err, ErrRateLimited)
+	}
+	if !c.successful("BlockNumber", ErrRateLimited) || c.successful("BlockByHash", ethereum.NotFound) {
+		t.Error("SuccessClassifier not applied")
+	}
+}
+
+func TestNotFoundObservedAsSuccess(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1}, testFlakyBalances{}),
+		newTestNodeWith(t, &testEthService{chainID: 1}, testFlakyBalances{}),
+	)
+	cfg.EnablePrometheus = true
+	cfg.PrometheusRegisterer = reg
+	cfg.NotFoundSuccessMethods = []string{"TransactionReceipt"}
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err := c.TransactionReceipt(context.Background(), common.Hash{1}); !errors.Is(err, ethereum.NotFound) {
+		t.Fatalf("TransactionReceipt = %v, want NotFound", err)
+	}
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var observed bool
+	for _, f := range families {
+		if f.GetName() != "rpc_request_total" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels[labelMethod] == "TransactionReceipt" {
+				observed = true
+				if labels[labelSuccess] != "true" {
+					t.Fatalf("NotFound receipt observed with labels %v", labels)
+				}
+			}
+		}
+	}
+	if !observed {
+		t.Fatal("TransactionReceipt not observed")
+	}
+}
@@ -0,0 +1,104 @@
+package ethclient
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+)
+
+// Option customizes a client created by New beyond its Config.
+type Option func(*options)
+
+type options struct {
+	ctx                 context.Context
+	logger              *zerolog.Logger
+	httpClient          *http.Client
+	endpointHTTPClients [2]*http.Client
+	dialOptions         []rpc.ClientOption
+	rpcClients          [2]*rpc.Client
+	pool                *Pool
+	apply               []func(*Config)
+}
+
+// WithContext ties the lifetime of the client to ctx: once ctx is done,
+// the background goroutines stop and the client is closed, as by Close.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// WithLogger sets the logger of the client, it defaults to the context
+// logger of zerolog or the global one.
+func WithLogger(logger *zerolog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithMetrics enables metrics and registers them with registerer.
+func WithMetrics(registerer prometheus.Registerer) Option {
+	return func(o *options) {
+		o.apply = append(o.apply, func(cfg *Config) {
+			cfg.EnablePrometheus = true
+			cfg.PrometheusRegisterer = registerer
+		})
+	}
+}
+
+// WithHTTPClient dials the endpoints with hc, e.g. to set custom transport
+// timeouts or proxies. It also applies when connections are dialed again.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = hc
+	}
+}
+
+// WithEndpointHTTPClients dials the primary and the failover endpoint with
+// their own http client, e.g. to go through a proxy or trust a private CA
+// for one provider only. A nil client falls back to WithHTTPClient.
+func WithEndpointHTTPClients(primary, failover *http.Client) Option {
+	return func(o *options) {
+		o.endpointHTTPClients = [2]*http.Client{primary, failover}
+	}
+}
+
+// WithPool makes the client share the connections of p with the other
+// clients using it. WithHTTPClient takes precedence for http endpoints.
+func WithPool(p *Pool) Option {
+	return func(o *options) {
+		o.pool = p
+	}
+}
+
+// WithDialOptions passes go-ethereum rpc client options (e.g. headers or
+// http auth) used whenever an endpoint is dialed.
+func WithDialOptions(opts ...rpc.ClientOption) Option {
+	return func(o *options) {
+		o.dialOptions = append(o.dialOptions, opts...)
+	}
+}
+
+// WithEndpoints overrides the names and urls of the endpoints from Config.
+func WithEndpoints(name, url, failoverName, failoverURL string) Option {
+	return func(o *options) {
+		o.apply = append(o.apply, func(cfg *Config) {
+			cfg.RpcName, cfg.RpcUrl = name, url
+			cfg.FailoverRpcName, cfg.FailoverRpcUrl = failoverName, failoverURL
+		})
+	}
+}
+
+// WithRPCClients makes the client use already dialed rpc clients instead
+// of dialing the urls from Config, e.g. clients with custom transports or
+// in-process clients of an rpc.Server in tests. The client owns them from
+// then on and closes them on Close. Their connections are never reaped, as
+// they can't be dialed again.
+func WithRPCClients(primary, failover *rpc.Client) Option {
+	return func(o *options) {
+		o.rpcClients = [2]*rpc.Client{primary, failover}
+	}
+}
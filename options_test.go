@@ -0,0 +1,65 @@
+package ethclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+)
+
+func newInProcClient(t *testing.T, svc *testEthService) *rpc.Client {
+	t.Helper()
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", svc); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Stop)
+	return rpc.DialInProc(srv)
+}
+
+func TestNewWithRPCClients(t *testing.T) {
+	logger := zerolog.Nop()
+	cfg := testConfig("", "")
+	c, err := New("test", "eth", cfg,
+		WithLogger(&logger),
+		WithRPCClients(
+			newInProcClient(t, &testEthService{chainID: 1, head: 10}),
+			newInProcClient(t, &testEthService{chainID: 1, head: 11}),
+		))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.(*client).Close()
+	if c.(*client).logger != &logger {
+		t.Fatal("logger option not applied")
+	}
+	if n, err := c.(*client).BlockNumber(context.Background()); err != nil || n != 10 {
+		t.Fatalf("BlockNumber = %d, %v, want 10", n, err)
+	}
+}
+
+func TestNewWithEndpointsAndMetrics(t *testing.T) {
+	cfg := testConfig("http://127.0.0.1:1", "http://127.0.0.1:1")
+	reg := prometheus.NewRegistry()
+	url := newTestNodeWith(t, &testEthService{chainID: 1, head: 3})
+	c, err := New("test", "eth", cfg,
+		WithEndpoints("a", url, "b", url),
+		WithMetrics(reg),
+		WithHTTPClient(http.DefaultClient))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.(*client).Close()
+	if cfg.RpcUrl != "http://127.0.0.1:1" || cfg.EnablePrometheus {
+		t.Fatal("options modified the caller's config")
+	}
+	if n, err := c.(*client).BlockNumber(context.Background()); err != nil || n != 3 {
+		t.Fatalf("BlockNumber = %d, %v, want 3", n, err)
+	}
+	if families, err := reg.Gather(); err != nil || len(families) == 0 {
+		t.Fatalf("no metrics registered: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package ethclient
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// readPins remembers which endpoint accepted a transaction, so that reads
+// of the transaction and of its sender's nonce are served by that endpoint
+// until the other one has most likely seen the transaction too.
+type readPins struct {
+	grace time.Duration
+
+	mu   sync.Mutex
+	pins map[any]readPin // keyed by tx hash or sender address
+}
+
+type readPin struct {
+	e     *endpoint
+	until time.Time
+}
+
+func newReadPins(grace time.Duration) *readPins {
+	return &readPins{grace: grace, pins: make(map[any]readPin)}
+}
+
+// pinTx pins the hash and the sender of tx to e.
+func (p *readPins) pinTx(tx *types.Transaction, e *endpoint) {
+	if p == nil || e == nil {
+		return
+	}
+	now := time.Now()
+	pin := readPin{e: e, until: now.Add(p.grace)}
+	sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for k, pin := range p.pins {
+		if now.After(pin.until) {
+			delete(p.pins, k)
+		}
+	}
+	p.pins[tx.Hash()] = pin
+	if err == nil {
+		p.pins[sender] = pin
+	}
+}
+
+// pinned returns the endpoint key is pinned to, if any.
+func (p *readPins) pinned(key any) *endpoint {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pin, ok := p.pins[key]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(pin.until) {
+		delete(p.pins, key)
+		return nil
+	}
+	return pin.e
+}
+
+type pinnedKey struct{}
+
+// withPin makes the call in ctx try the endpoint key is pinned to first.
+func (c *client) withPin(ctx context.Context, key any) context.Context {
+	if e := c.pins.pinned(key); e != nil {
+		return context.WithValue(ctx, pinnedKey{}, e)
+	}
+	return ctx
+}
+
+// pinFirst moves the endpoint the call in ctx is pinned to at the front of
+// routes, unless it is unavailable.
+func pinFirst(ctx context.Context, routes []*endpoint) []*endpoint {
+	e, _ := ctx.Value(pinnedKey{}).(*endpoint)
+	if e == nil || e.unavailable() || len(routes) == 0 || routes[0] == e {
+		return routes
+	}
+	pinned := []*endpoint{e}
+	for _, r := range routes {
+		if r != e {
+			pinned = append(pinned, r)
+		}
+	}
+	if len(pinned) > len(routes) {
+		// e isn't routable for this method
+		return routes
+	}
+	return pinned
+}
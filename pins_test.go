@@ -0,0 +1,94 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testTxPool accepts raw transactions, unless rejecting, and counts them in
+// the pending nonce.
+type testTxPool struct {
+	reject bool
+	sent   atomic.Uint64
+}
+
+func (p *testTxPool) SendRawTransaction(data hexutil.Bytes) (common.Hash, error) {
+	if p.reject {
+		return common.Hash{}, errors.New("upstream unavailable")
+	}
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return common.Hash{}, err
+	}
+	p.sent.Add(1)
+	return tx.Hash(), nil
+}
+
+func (p *testTxPool) GetTransactionCount(_ common.Address, _ string) hexutil.Uint64 {
+	return hexutil.Uint64(p.sent.Load())
+}
+
+func TestReadAfterWritePinning(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), Gas: 21000}), types.LatestSignerForChainID(big.NewInt(1)), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, grace := range []int{0, 60} {
+		primary := &testTxPool{reject: true}
+		cfg := testConfig(
+			newTestNodeWith(t, &testEthService{chainID: 1}, primary),
+			newTestNodeWith(t, &testEthService{chainID: 1}, &testTxPool{}),
+		)
+		cfg.ReadAfterWriteGraceSec = grace
+		c, err := New("test", "eth", cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.SendTransaction(context.Background(), tx); err != nil {
+			t.Fatal(err)
+		}
+		// the primary is healthy again but hasn't seen the transaction
+		primary.reject = false
+
+		want := uint64(0)
+		if grace > 0 {
+			want = 1
+		}
+		if n, err := c.PendingNonceAt(context.Background(), sender); err != nil || n != want {
+			t.Errorf("grace %ds: PendingNonceAt = %d, %v, want %d", grace, n, err, want)
+		}
+		if grace > 0 && c.(*client).pins.pinned(tx.Hash()) != c.(*client).b {
+			t.Errorf("transaction not pinned to the endpoint that accepted it")
+		}
+		c.Close()
+	}
+}
+
+func TestReadPinsExpire(t *testing.T) {
+	p := newReadPins(10 * time.Millisecond)
+	e := &endpoint{name: "main"}
+	tx := types.NewTx(&types.LegacyTx{})
+	p.pinTx(tx, e)
+	if p.pinned(tx.Hash()) != e {
+		t.Fatal("transaction not pinned")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if p.pinned(tx.Hash()) != nil {
+		t.Fatal("pin did not expire")
+	}
+}
@@ -0,0 +1,106 @@
+package ethclient
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// poolMaxIdleConnsPerHost is the number of idle http connections a Pool
+// keeps per host, enough for the clients of a few chains on one provider.
+const poolMaxIdleConnsPerHost = 64
+
+// Pool shares connections between clients, e.g. the clients of several
+// chains or subsystems pointing at the same provider, see WithPool. HTTP
+// endpoints share a transport, and with it idle connections and TLS
+// sessions per host. Websocket endpoints with the same url share a single
+// connection, dialed with the options of the first client dialing it.
+// Each client keeps its own metrics, rate limits and routing.
+type Pool struct {
+	transport *http.Transport
+
+	mu    sync.Mutex
+	conns map[string]*pooledConn // websocket connections by url
+}
+
+type pooledConn struct {
+	rc   *rpc.Client
+	refs int
+}
+
+// NewPool creates an empty pool, to be closed once its clients are.
+func NewPool() *Pool {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConnsPerHost = poolMaxIdleConnsPerHost
+	return &Pool{transport: t, conns: make(map[string]*pooledConn)}
+}
+
+// Close closes the idle http connections of the pool. Websocket
+// connections are closed by their last client.
+func (p *Pool) Close() {
+	p.transport.CloseIdleConnections()
+}
+
+// httpClient returns the http client of the endpoints using the pool.
+func (p *Pool) httpClient() *http.Client {
+	return &http.Client{Transport: p.transport}
+}
+
+// dial connects to url, sharing the connection to websocket urls with the
+// other clients of the pool. Without a pool, it is rpc.DialOptions.
+func (p *Pool) dial(ctx context.Context, url string, opts []rpc.ClientOption) (*rpc.Client, error) {
+	if p == nil || !isWebsocketURL(url) {
+		return rpc.DialOptions(ctx, url, opts...)
+	}
+	p.mu.Lock()
+	if c, ok := p.conns[url]; ok {
+		c.refs++
+		p.mu.Unlock()
+		return c.rc, nil
+	}
+	p.mu.Unlock()
+
+	rc, err := rpc.DialOptions(ctx, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c, ok := p.conns[url]; ok {
+		// lost a race with another client dialing it
+		rc.Close()
+		c.refs++
+		return c.rc, nil
+	}
+	p.conns[url] = &pooledConn{rc: rc, refs: 1}
+	return rc, nil
+}
+
+// release closes rc, once no other client of the pool uses it.
+func (p *Pool) release(rc *rpc.Client) {
+	if p == nil {
+		rc.Close()
+		return
+	}
+	p.mu.Lock()
+	for url, c := range p.conns {
+		if c.rc != rc {
+			continue
+		}
+		if c.refs--; c.refs > 0 {
+			p.mu.Unlock()
+			return
+		}
+		delete(p.conns, url)
+		break
+	}
+	p.mu.Unlock()
+	rc.Close()
+}
+
+func isWebsocketURL(url string) bool {
+	return strings.HasPrefix(url, "ws://") || strings.HasPrefix(url, "wss://")
+}
@@ -0,0 +1,59 @@
+package ethclient
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newTestWSNode serves svc and extra receivers over websocket.
+func newTestWSNode(t *testing.T, svc *testEthService, extra ...interface{}) string {
+	t.Helper()
+	srv := rpc.NewServer()
+	for _, rcvr := range append([]interface{}{svc}, extra...) {
+		if err := srv.RegisterName("eth", rcvr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hs := httptest.NewServer(srv.WebsocketHandler([]string{"*"}))
+	t.Cleanup(func() {
+		hs.Close()
+		srv.Stop()
+	})
+	return "ws" + strings.TrimPrefix(hs.URL, "http")
+}
+
+func TestPoolSharesWebsocketConnections(t *testing.T) {
+	ws := newTestWSNode(t, &testEthService{chainID: 1, head: 9})
+	pool := NewPool()
+	defer pool.Close()
+
+	var clients []*client
+	for _, chain := range []string{"a", "b"} {
+		c, err := New("test", chain, testConfig(ws, newTestNode(t, 1)), WithPool(pool))
+		if err != nil {
+			t.Fatal(err)
+		}
+		clients = append(clients, c.(*client))
+	}
+	if connOf(clients[0].m) != connOf(clients[1].m) {
+		t.Fatal("websocket connection not shared")
+	}
+	if connOf(clients[0].b) == connOf(clients[1].b) {
+		t.Fatal("http endpoints share an rpc client")
+	}
+
+	clients[0].Close()
+	if n, err := clients[1].BlockNumber(context.Background()); err != nil || n != 9 {
+		t.Fatalf("BlockNumber after the other client closed = %d, %v, want 9", n, err)
+	}
+	clients[1].Close()
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	if len(pool.conns) != 0 {
+		t.Fatalf("%d connections left in the pool", len(pool.conns))
+	}
+}
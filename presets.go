@@ -0,0 +1,82 @@
+package ethclient
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ProviderPreset holds the limits and capabilities of a well-known rpc
+// provider, applied to its endpoints with Config.ProviderPresets. The
+// built-in ones are conservative, matching the entry plans.
+type ProviderPreset struct {
+	Name string
+	// calls per second and burst, see Config.RpcRateLimit
+	RateLimit float64
+	RateBurst int
+	// maximum elements per batch request, 0 when unlimited
+	MaxBatchSize int
+	// maximum block range of an eth_getLogs query, 0 when unlimited
+	MaxLogRange uint64
+	// serves the state of every block
+	Archive bool
+	// offers the debug and trace namespaces
+	Trace bool
+}
+
+var providerPresets = struct {
+	sync.RWMutex
+	byHost map[string]ProviderPreset // by host suffix
+}{
+	byHost: map[string]ProviderPreset{
+		"infura.io":          {Name: "infura", RateLimit: 10, RateBurst: 20, MaxBatchSize: 100, MaxLogRange: 10000, Archive: true},
+		"alchemy.com":        {Name: "alchemy", RateLimit: 25, RateBurst: 50, MaxBatchSize: 1000, MaxLogRange: 2000, Archive: true, Trace: true},
+		"quiknode.pro":       {Name: "quicknode", RateLimit: 15, RateBurst: 15, MaxBatchSize: 100, MaxLogRange: 10000, Archive: true, Trace: true},
+		"ankr.com":           {Name: "ankr", RateLimit: 30, RateBurst: 30, MaxBatchSize: 1000, MaxLogRange: 3000, Archive: true, Trace: true},
+		"cloudflare-eth.com": {Name: "cloudflare", RateLimit: 5, RateBurst: 5, MaxBatchSize: 10, MaxLogRange: 800},
+		"llamarpc.com":       {Name: "llamarpc", RateLimit: 5, RateBurst: 10, MaxBatchSize: 10, MaxLogRange: 1000},
+		"publicnode.com":     {Name: "publicnode", RateLimit: 5, RateBurst: 10, MaxBatchSize: 10, MaxLogRange: 1000},
+	},
+}
+
+// RegisterProviderPreset sets the preset of the provider serving the hosts
+// ending with hostSuffix, replacing the built-in one if any.
+func RegisterProviderPreset(hostSuffix string, preset ProviderPreset) {
+	providerPresets.Lock()
+	defer providerPresets.Unlock()
+	providerPresets.byHost[strings.ToLower(hostSuffix)] = preset
+}
+
+// LookupProviderPreset returns the preset of the provider serving rawURL,
+// matched by host suffix, the longest suffix winning.
+func LookupProviderPreset(rawURL string) (ProviderPreset, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return ProviderPreset{}, false
+	}
+	host := strings.ToLower(u.Hostname())
+	providerPresets.RLock()
+	defer providerPresets.RUnlock()
+	var found ProviderPreset
+	var best string
+	for suffix, preset := range providerPresets.byHost {
+		if (host == suffix || strings.HasSuffix(host, "."+suffix)) && len(suffix) > len(best) {
+			found, best = preset, suffix
+		}
+	}
+	return found, best != ""
+}
+
+// applyPreset fills the limits of e left unset in Config from the preset of
+// its provider. A provider that isn't archive is assumed to keep the state
+// of the last 128 blocks, like a geth full node.
+func (e *endpoint) applyPreset(p ProviderPreset) {
+	e.preset = &p
+	if e.limiter == nil {
+		e.limiter = newTokenBucket(p.RateLimit, p.RateBurst)
+	}
+	if e.stateHistory == 0 && !p.Archive {
+		e.stateHistory = 128
+	}
+	e.trace = e.trace || p.Trace
+}
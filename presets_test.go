@@ -0,0 +1,63 @@
+package ethclient
+
+import (
+	"testing"
+)
+
+func TestLookupProviderPreset(t *testing.T) {
+	for _, tt := range []struct {
+		url  string
+		want string
+	}{
+		{"https://mainnet.infura.io/v3/key", "infura"},
+		{"wss://eth-mainnet.g.alchemy.com/v2/key", "alchemy"},
+		{"https://rpc.ankr.com/eth", "ankr"},
+		{"https://ethereum-rpc.publicnode.com", "publicnode"},
+		{"https://notinfura.io", ""},
+		{"http://127.0.0.1:8545", ""},
+		{"::", ""},
+	} {
+		p, ok := LookupProviderPreset(tt.url)
+		if ok != (tt.want != "") || p.Name != tt.want {
+			t.Errorf("LookupProviderPreset(%q) = %q, %v, want %q", tt.url, p.Name, ok, tt.want)
+		}
+	}
+}
+
+func TestProviderPresets(t *testing.T) {
+	RegisterProviderPreset("node.test", ProviderPreset{Name: "test", RateLimit: 100, MaxBatchSize: 2})
+	RegisterProviderPreset("archive.node.test", ProviderPreset{Name: "archive", Archive: true})
+	defer func() {
+		providerPresets.Lock()
+		delete(providerPresets.byHost, "node.test")
+		delete(providerPresets.byHost, "archive.node.test")
+		providerPresets.Unlock()
+	}()
+	if p, _ := LookupProviderPreset("https://x.archive.node.test"); p.Name != "archive" {
+		t.Fatalf("longest suffix not preferred, got %q", p.Name)
+	}
+
+	cfg := testConfig(newTestNode(t, 1), newTestNode(t, 1))
+	cfg.RpcUrl = "http://eth.node.test"
+	cfg.ProviderPresets = true
+	cfg.FailoverRpcStateHistoryBlocks = 64
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	cl := c.(*client)
+	if cl.m.preset == nil || cl.m.limiter == nil || cl.m.stateHistory != 128 {
+		t.Fatalf("preset not applied to the primary: %+v", cl.m.preset)
+	}
+	if cl.b.preset != nil || cl.b.stateHistory != 64 {
+		t.Fatal("preset applied to an unknown provider")
+	}
+	if size := cl.batchSize(); size != 2 {
+		t.Fatalf("batch size = %d, want the preset's 2", size)
+	}
+	cl.cfg.BatchSize = 10
+	if size := cl.batchSize(); size != 10 {
+		t.Fatalf("batch size = %d, want the configured 10", size)
+	}
+}
@@ -1,15 +1,36 @@
 package ethclient
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type metrics struct {
-	req     *prometheus.CounterVec
-	latency *prometheus.HistogramVec
+	registerer   prometheus.Registerer
+	req          *prometheus.CounterVec
+	latency      *prometheus.HistogramVec
+	conns        *prometheus.GaugeVec
+	subs         *prometheus.GaugeVec
+	goroutines   prometheus.Gauge
+	mismatch     *prometheus.CounterVec
+	promotions   *prometheus.CounterVec
+	cache        *prometheus.CounterVec
+	coalesced    *prometheus.CounterVec
+	preferred    *prometheus.GaugeVec
+	inclusion    prometheus.Histogram
+	rebroadcasts *prometheus.CounterVec
+	height       *prometheus.GaugeVec
+	lag          *prometheus.GaugeVec
+	healthy      *prometheus.GaugeVec
+	failovers    *prometheus.CounterVec
+	respBytes    *prometheus.CounterVec
+	staleReads   *prometheus.CounterVec
 }
 
 const (
@@ -18,6 +39,7 @@ const (
 	labelSuccess = "success"
 	labelMethod  = "method"
 	labelClient  = "client"
+	labelResult  = "result"
 )
 
 var (
@@ -25,10 +47,24 @@ var (
 	latencyBucket = []float64{
 		2, 4, 8, 16, 32, 64, 128, 256, 512, 1024, 2048,
 	}
+	// in seconds, from a fast L2 block to a long wait on L1
+	inclusionBuckets = []float64{
+		1, 2, 5, 10, 15, 30, 60, 120, 300, 600, 1800,
+	}
 )
 
-func newMetrics(appName string, chainName string) *metrics {
+// newMetrics creates the client's collectors, to be registered with
+// registerer (the default registry when nil). Latency buckets are in
+// milliseconds, latencyBucket is used when none are given.
+func newMetrics(appName string, chainName string, registerer prometheus.Registerer, buckets []float64) *metrics {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+	if len(buckets) == 0 {
+		buckets = latencyBucket
+	}
 	return &metrics{
+		registerer: registerer,
 		req: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "rpc_request_total",
@@ -42,33 +78,414 @@ func newMetrics(appName string, chainName string) *metrics {
 			prometheus.HistogramOpts{
 				Name:    "rpc_latency_milliseconds",
 				Help:    "RPC request latency in milliseconds",
-				Buckets: latencyBucket,
+				Buckets: buckets,
 				ConstLabels: prometheus.Labels{
 					labelApp:   appName,
 					labelChain: chainName},
 			}, labels),
+		conns: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "rpc_open_connections",
+				Help: "Open connections per RPC endpoint",
+				ConstLabels: prometheus.Labels{
+					labelApp:   appName,
+					labelChain: chainName,
+				},
+			}, []string{labelClient}),
+		subs: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "rpc_active_subscriptions",
+				Help: "Active subscriptions per RPC endpoint",
+				ConstLabels: prometheus.Labels{
+					labelApp:   appName,
+					labelChain: chainName,
+				},
+			}, []string{labelClient}),
+		goroutines: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "rpc_client_goroutines",
+				Help: "Background goroutines owned by the RPC client",
+				ConstLabels: prometheus.Labels{
+					labelApp:   appName,
+					labelChain: chainName,
+				},
+			}),
+		mismatch: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rpc_result_mismatch_total",
+				Help: "Consensus reads where the RPC endpoints disagreed",
+				ConstLabels: prometheus.Labels{
+					labelApp:   appName,
+					labelChain: chainName,
+				},
+			}, []string{labelMethod}),
+		promotions: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rpc_promotions_total",
+				Help: "Promotions of an RPC endpoint to primary",
+				ConstLabels: prometheus.Labels{
+					labelApp:   appName,
+					labelChain: chainName,
+				},
+			}, []string{labelClient}),
+		cache: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rpc_cache_requests_total",
+				Help: "Lookups of the response cache by result (hit or miss), and stale answers of the failsafe cache",
+				ConstLabels: prometheus.Labels{
+					labelApp:   appName,
+					labelChain: chainName,
+				},
+			}, []string{labelMethod, labelResult}),
+		coalesced: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rpc_coalesced_requests_total",
+				Help: "Calls served by an identical call already in flight",
+				ConstLabels: prometheus.Labels{
+					labelApp:   appName,
+					labelChain: chainName,
+				},
+			}, []string{labelMethod}),
+		preferred: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "rpc_preferred_endpoint",
+				Help: "1 for the RPC endpoint tried first, 0 for the others",
+				ConstLabels: prometheus.Labels{
+					labelApp:   appName,
+					labelChain: chainName,
+				},
+			}, []string{labelClient}),
+		inclusion: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "rpc_transaction_inclusion_seconds",
+				Help:    "Time from sending to the first receipt of transactions of SendTransactionAndWait",
+				Buckets: inclusionBuckets,
+				ConstLabels: prometheus.Labels{
+					labelApp:   appName,
+					labelChain: chainName,
+				},
+			}),
+		rebroadcasts: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rpc_transaction_rebroadcasts_total",
+				Help: "Transactions broadcast again after no RPC endpoint knew them",
+				ConstLabels: prometheus.Labels{
+					labelApp:   appName,
+					labelChain: chainName,
+				},
+			}, []string{labelClient}),
+		height: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "rpc_endpoint_block_height",
+				Help: "Last block height polled from the RPC endpoint",
+				ConstLabels: prometheus.Labels{
+					labelApp:   appName,
+					labelChain: chainName,
+				},
+			}, []string{labelClient}),
+		lag: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "rpc_endpoint_block_lag",
+				Help: "Blocks the RPC endpoint is behind the highest polled block height",
+				ConstLabels: prometheus.Labels{
+					labelApp:   appName,
+					labelChain: chainName,
+				},
+			}, []string{labelClient}),
+		healthy: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "rpc_endpoint_healthy",
+				Help: "1 if the RPC endpoint is available and its last call succeeded, 0 otherwise",
+				ConstLabels: prometheus.Labels{
+					labelApp:   appName,
+					labelChain: chainName,
+				},
+			}, []string{labelClient}),
+		failovers: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rpc_failover_total",
+				Help: "Calls failing over to the RPC endpoint after the previous one failed",
+				ConstLabels: prometheus.Labels{
+					labelApp:   appName,
+					labelChain: chainName,
+				},
+			}, []string{labelMethod, labelClient}),
+		respBytes: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rpc_response_bytes_total",
+				Help: "Bytes of the HTTP responses of the RPC endpoint",
+				ConstLabels: prometheus.Labels{
+					labelApp:   appName,
+					labelChain: chainName,
+				},
+			}, []string{labelMethod, labelClient}),
+		staleReads: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rpc_stale_reads_total",
+				Help: "Reads of the latest state kept from the RPC endpoint for lagging behind the head",
+				ConstLabels: prometheus.Labels{
+					labelApp:   appName,
+					labelChain: chainName,
+				},
+			}, []string{labelMethod, labelClient}),
+	}
+}
+
+// Register registers the collectors with the registerer. Collectors are
+// scoped by app and chain through their const labels, so clients for
+// different chains can live in the same process. If an identical collector
+// is already registered (e.g. a second client for the same app and chain),
+// the existing one is reused. Conflicting collectors, e.g. with other
+// buckets, are reported as an error, after unregistering the collectors
+// registered so far.
+func (m *metrics) Register() error {
+	var added []prometheus.Collector
+	for _, register := range []func() (prometheus.Collector, bool, error){
+		registerField(m.registerer, &m.req),
+		registerField(m.registerer, &m.latency),
+		registerField(m.registerer, &m.conns),
+		registerField(m.registerer, &m.subs),
+		registerField(m.registerer, &m.goroutines),
+		registerField(m.registerer, &m.mismatch),
+		registerField(m.registerer, &m.promotions),
+		registerField(m.registerer, &m.cache),
+		registerField(m.registerer, &m.coalesced),
+		registerField(m.registerer, &m.preferred),
+		registerField(m.registerer, &m.inclusion),
+		registerField(m.registerer, &m.rebroadcasts),
+		registerField(m.registerer, &m.height),
+		registerField(m.registerer, &m.lag),
+		registerField(m.registerer, &m.healthy),
+		registerField(m.registerer, &m.failovers),
+		registerField(m.registerer, &m.respBytes),
+		registerField(m.registerer, &m.staleReads),
+	} {
+		c, isNew, err := register()
+		if err != nil {
+			// leave the registerer as it was
+			for _, c := range added {
+				m.registerer.Unregister(c)
+			}
+			return err
+		}
+		if isNew {
+			added = append(added, c)
+		}
+	}
+	return nil
+}
+
+// registerField returns the registration of the collector *c, which sets
+// *c to the existing collector if an identical one is registered, and
+// reports whether *c was registered instead.
+func registerField[T prometheus.Collector](r prometheus.Registerer, c *T) func() (prometheus.Collector, bool, error) {
+	return func() (prometheus.Collector, bool, error) {
+		registered, err := register(r, *c)
+		if err != nil {
+			return nil, false, err
+		}
+		isNew := prometheus.Collector(registered) == prometheus.Collector(*c)
+		*c = registered
+		return registered, isNew, nil
 	}
 }
 
-func (m *metrics) Register() {
-	prometheus.MustRegister(m.req)
-	prometheus.MustRegister(m.latency)
+func register[T prometheus.Collector](r prometheus.Registerer, c T) (T, error) {
+	if err := r.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing, nil
+			}
+		}
+		return c, fmt.Errorf("failed to register rpc metrics: %w", err)
+	}
+	return c, nil
 }
 
 func (m *metrics) Unregister() {
-	prometheus.Unregister(m.req)
-	prometheus.Unregister(m.latency)
+	for _, c := range m.collectors() {
+		m.registerer.Unregister(c)
+	}
+}
+
+// collectors returns every collector of the client, see Register.
+func (m *metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.req,
+		m.latency,
+		m.conns,
+		m.subs,
+		m.goroutines,
+		m.mismatch,
+		m.promotions,
+		m.cache,
+		m.coalesced,
+		m.preferred,
+		m.inclusion,
+		m.rebroadcasts,
+		m.height,
+		m.lag,
+		m.healthy,
+		m.failovers,
+		m.respBytes,
+		m.staleReads,
+	}
 }
 
 func (s *metrics) Observe(method string, startedAt time.Time, client string, successful bool) {
+	s.ObserveCall(context.Background(), method, startedAt, client, successful)
+}
+
+// ObserveCall observes a call like Observe, with the trace id of the span
+// of ctx, if sampled, as exemplar of its latency.
+func (s *metrics) ObserveCall(ctx context.Context, method string, startedAt time.Time, client string, successful bool) {
+	if s == nil {
+		return
+	}
 	s.req.With(prometheus.Labels{
 		labelMethod:  method,
 		labelClient:  client,
 		labelSuccess: strconv.FormatBool(successful),
 	}).Inc()
-	s.latency.With(prometheus.Labels{
+	latency := s.latency.With(prometheus.Labels{
 		labelMethod:  method,
 		labelClient:  client,
 		labelSuccess: strconv.FormatBool(successful),
-	}).Observe(float64(time.Since(startedAt).Milliseconds()))
+	})
+	ms := float64(time.Since(startedAt).Milliseconds())
+	if sc := trace.SpanContextFromContext(ctx); sc.IsSampled() {
+		if eo, ok := latency.(prometheus.ExemplarObserver); ok {
+			eo.ObserveWithExemplar(ms, prometheus.Labels{"trace_id": sc.TraceID().String()})
+			return
+		}
+	}
+	latency.Observe(ms)
+}
+
+// ObserveResponseBytes adds the size of the responses of a call.
+func (s *metrics) ObserveResponseBytes(method string, client string, n int64) {
+	if s == nil || n <= 0 {
+		return
+	}
+	s.respBytes.With(prometheus.Labels{labelMethod: method, labelClient: client}).Add(float64(n))
+}
+
+// ObserveStaleRead counts a read of method kept from a lagging endpoint.
+func (s *metrics) ObserveStaleRead(method string, client string) {
+	if s == nil {
+		return
+	}
+	s.staleReads.With(prometheus.Labels{labelMethod: method, labelClient: client}).Inc()
+}
+
+func (s *metrics) AddConnections(client string, delta float64) {
+	if s == nil {
+		return
+	}
+	s.conns.With(prometheus.Labels{labelClient: client}).Add(delta)
+}
+
+func (s *metrics) AddSubscriptions(client string, delta float64) {
+	if s == nil {
+		return
+	}
+	s.subs.With(prometheus.Labels{labelClient: client}).Add(delta)
+}
+
+func (s *metrics) AddGoroutines(delta float64) {
+	if s == nil {
+		return
+	}
+	s.goroutines.Add(delta)
+}
+
+func (s *metrics) ObserveMismatch(method string) {
+	if s == nil {
+		return
+	}
+	s.mismatch.With(prometheus.Labels{labelMethod: method}).Inc()
+}
+
+func (s *metrics) ObservePromotion(client string) {
+	if s == nil {
+		return
+	}
+	s.promotions.With(prometheus.Labels{labelClient: client}).Inc()
+}
+
+func (s *metrics) ObserveCache(method string, hit bool) {
+	if s == nil {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	s.cache.With(prometheus.Labels{labelMethod: method, labelResult: result}).Inc()
+}
+
+func (s *metrics) ObserveStale(method string) {
+	if s == nil {
+		return
+	}
+	s.cache.With(prometheus.Labels{labelMethod: method, labelResult: "stale"}).Inc()
+}
+
+func (s *metrics) ObserveCoalesced(method string) {
+	if s == nil {
+		return
+	}
+	s.coalesced.With(prometheus.Labels{labelMethod: method}).Inc()
+}
+
+func (s *metrics) SetPreferred(client string, preferred bool) {
+	if s == nil {
+		return
+	}
+	v := 0.0
+	if preferred {
+		v = 1
+	}
+	s.preferred.With(prometheus.Labels{labelClient: client}).Set(v)
+}
+
+func (s *metrics) ObserveInclusion(took time.Duration) {
+	if s == nil {
+		return
+	}
+	s.inclusion.Observe(took.Seconds())
+}
+
+func (s *metrics) ObserveRebroadcast(client string) {
+	if s == nil {
+		return
+	}
+	s.rebroadcasts.With(prometheus.Labels{labelClient: client}).Inc()
+}
+
+func (s *metrics) SetBlockHeight(client string, height, best uint64) {
+	if s == nil {
+		return
+	}
+	s.height.With(prometheus.Labels{labelClient: client}).Set(float64(height))
+	s.lag.With(prometheus.Labels{labelClient: client}).Set(float64(best - height))
+}
+
+func (s *metrics) SetHealthy(client string, healthy bool) {
+	if s == nil {
+		return
+	}
+	v := 0.0
+	if healthy {
+		v = 1
+	}
+	s.healthy.With(prometheus.Labels{labelClient: client}).Set(v)
+}
+
+func (s *metrics) ObserveFailover(method string, client string) {
+	if s == nil {
+		return
+	}
+	s.failovers.With(prometheus.Labels{labelMethod: method, labelClient: client}).Inc()
 }
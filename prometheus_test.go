@@ -0,0 +1,240 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMetricsRegisterer(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cfg := testConfig(newTestNode(t, 1), newTestNode(t, 1))
+	cfg.EnablePrometheus = true
+	cfg.PrometheusRegisterer = reg
+	cfg.LatencyBucketsMs = []float64{10, 100, 1000}
+
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.(*client).Close()
+	c.(*client).metrics.Observe("BlockNumber", c.(*client).m.lastUsed, "main", true)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, f := range families {
+		found = found || f.GetName() == "rpc_request_total"
+	}
+	if !found {
+		t.Fatal("rpc_request_total not in the registry")
+	}
+
+	// an identical client reuses the collectors
+	c2, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c2.(*client).Close()
+	if c2.(*client).metrics.req != c.(*client).metrics.req {
+		t.Fatal("collectors were not reused")
+	}
+
+	// conflicting collectors are an error rather than a panic
+	conflicting := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:        "rpc_request_total",
+		Help:        "RPC requests counts",
+		ConstLabels: prometheus.Labels{labelApp: "other", labelChain: "eth"},
+	}, []string{labelMethod})
+	cfg.PrometheusRegisterer = prometheus.NewRegistry()
+	if err := cfg.PrometheusRegisterer.Register(conflicting); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New("test", "eth", cfg); err == nil {
+		t.Fatal("registering conflicting collectors succeeded")
+	}
+
+	// a conflict on a later collector leaves the registerer untouched
+	conflicting = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:        "rpc_stale_reads_total",
+		Help:        "Stale reads",
+		ConstLabels: prometheus.Labels{labelApp: "test", labelChain: "eth"},
+	}, []string{labelMethod})
+	cfg.PrometheusRegisterer = prometheus.NewRegistry()
+	if err := cfg.PrometheusRegisterer.Register(conflicting); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New("test", "eth", cfg); err == nil {
+		t.Fatal("registering conflicting collectors succeeded")
+	}
+	if err := cfg.PrometheusRegisterer.Register(newMetrics("test", "eth", cfg.PrometheusRegisterer, cfg.LatencyBucketsMs).req); err != nil {
+		t.Fatalf("collectors left registered after a conflict: %v", err)
+	}
+}
+
+func TestConfigLatencyBuckets(t *testing.T) {
+	cfg := testConfig("http://localhost", "http://localhost")
+	cfg.LatencyBucketsMs = []float64{10, 5}
+	if err := cfg.Valid(); err == nil {
+		t.Fatal("decreasing buckets are valid")
+	}
+}
+
+func TestMetricDescriptions(t *testing.T) {
+	m := newMetrics("test", "eth", prometheus.NewRegistry(), nil)
+	descriptions := MetricDescriptions()
+	documented := make(map[string]bool)
+	for _, c := range m.collectors() {
+		ch := make(chan *prometheus.Desc, 1)
+		c.Describe(ch)
+		desc := (<-ch).String()
+		var found bool
+		for _, d := range descriptions {
+			if !strings.HasPrefix(desc, fmt.Sprintf("Desc{fqName: %q, help: %q,", d.Name, d.Help)) {
+				continue
+			}
+			found, documented[d.Name] = true, true
+			if !strings.HasSuffix(desc, fmt.Sprintf("variableLabels: %v}", d.Labels)) {
+				t.Errorf("labels of %s = %v, described as %v", d.Name, desc, d.Labels)
+			}
+		}
+		if !found {
+			t.Errorf("%s isn't described", desc)
+		}
+	}
+	if len(documented) != len(descriptions) {
+		t.Errorf("%d metrics described, %d emitted", len(descriptions), len(documented))
+	}
+}
+
+// gathered returns the value of the gauge or counter name with labels.
+func gathered(t *testing.T, reg *prometheus.Registry, name string, labels map[string]string) (float64, bool) {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if v, ok := labels[l.GetName()]; ok && v != l.GetValue() {
+					continue metrics
+				}
+			}
+			if m.GetGauge() != nil {
+				return m.GetGauge().GetValue(), true
+			}
+			return m.GetCounter().GetValue(), true
+		}
+	}
+	return 0, false
+}
+
+func TestEndpointMetrics(t *testing.T) {
+	var down, failoverDown atomic.Bool
+	reg := prometheus.NewRegistry()
+	cfg := testConfig(
+		newOutageNode(t, &testEthService{chainID: 1, head: 8}, &down),
+		newOutageNode(t, &testEthService{chainID: 1, head: 9}, &failoverDown),
+	)
+	cfg.EnablePrometheus = true
+	cfg.PrometheusRegisterer = reg
+	cfg.HeadPollIntervalMs = 20
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(5 * time.Millisecond) {
+		if lag, ok := gathered(t, reg, "rpc_endpoint_block_lag", map[string]string{labelClient: "main"}); ok && lag == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("block lag of main not exported")
+		}
+	}
+	if h, _ := gathered(t, reg, "rpc_endpoint_block_height", map[string]string{labelClient: "failover"}); h != 9 {
+		t.Fatalf("block height of failover = %v, want 9", h)
+	}
+
+	down.Store(true)
+	if _, err := c.(*client).BlockNumber(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := gathered(t, reg, "rpc_failover_total", map[string]string{labelMethod: "BlockNumber", labelClient: "failover"}); n != 1 {
+		t.Fatalf("rpc_failover_total = %v, want 1", n)
+	}
+	if v, ok := gathered(t, reg, "rpc_endpoint_healthy", map[string]string{labelClient: "main"}); !ok || v != 0 {
+		t.Fatalf("rpc_endpoint_healthy of main = %v, want 0", v)
+	}
+	if v, _ := gathered(t, reg, "rpc_endpoint_healthy", map[string]string{labelClient: "failover"}); v != 1 {
+		t.Fatalf("rpc_endpoint_healthy of failover = %v, want 1", v)
+	}
+}
+
+// sampledTracer starts spans of a sampled trace.
+type sampledTracer struct {
+	trace.Tracer
+}
+
+func (sampledTracer) Start(ctx context.Context, _ string, _ ...trace.SpanStartOption) (context.Context, trace.Span) {
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	return ctx, trace.SpanFromContext(ctx)
+}
+
+func TestResponseBytesAndExemplars(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cfg := testConfig(newTestNode(t, 1), newTestNode(t, 1))
+	cfg.EnablePrometheus = true
+	cfg.PrometheusRegisterer = reg
+	cfg.Tracer = sampledTracer{}
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err := c.(*client).BlockNumber(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// {"jsonrpc":"2.0","id":..,"result":"0x.."}
+	if n, _ := gathered(t, reg, "rpc_response_bytes_total", map[string]string{labelMethod: "BlockNumber", labelClient: "main"}); n < 30 {
+		t.Fatalf("rpc_response_bytes_total = %v, want the size of the response", n)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var traceID string
+	for _, f := range families {
+		if f.GetName() != "rpc_latency_milliseconds" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			for _, b := range m.GetHistogram().GetBucket() {
+				for _, l := range b.GetExemplar().GetLabel() {
+					traceID = l.GetValue()
+				}
+			}
+		}
+	}
+	if want := (trace.TraceID{1}).String(); traceID != want {
+		t.Fatalf("latency exemplar trace id = %q, want %s", traceID, want)
+	}
+}
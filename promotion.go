@@ -0,0 +1,125 @@
+package ethclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	// promotionProbes is the number of latency probes per endpoint and
+	// promotion window.
+	promotionProbes = 10
+	// promotionProbeMethod labels latency probes in metrics.
+	promotionProbeMethod = "LatencyProbe"
+)
+
+// qualityStats aggregates the latency probes of an endpoint over a window.
+type qualityStats struct {
+	samples int
+	errors  int
+	latency time.Duration // sum over successful probes
+}
+
+func (s *qualityStats) observe(took time.Duration, err error) {
+	s.samples++
+	if err != nil {
+		s.errors++
+		return
+	}
+	s.latency += took
+}
+
+func (s *qualityStats) errorRate() float64 {
+	if s.samples == 0 {
+		return 0
+	}
+	return float64(s.errors) / float64(s.samples)
+}
+
+func (s *qualityStats) avgLatency() time.Duration {
+	if ok := s.samples - s.errors; ok > 0 {
+		return s.latency / time.Duration(ok)
+	}
+	return 0
+}
+
+// outperforms reports whether s is better than o by a clear margin: a
+// lower error rate, or the same error rate and a latency below ratio times
+// the one of o. Windows with too few probes are inconclusive.
+func (s *qualityStats) outperforms(o *qualityStats, ratio float64) bool {
+	if s.samples < promotionProbes/2 || o.samples < promotionProbes/2 || s.errors == s.samples {
+		return false
+	}
+	if s.errorRate() != o.errorRate() {
+		return s.errorRate() < o.errorRate()
+	}
+	return float64(s.avgLatency()) < ratio*float64(o.avgLatency())
+}
+
+// promoteOnLatency probes the latency of every endpoint in the background
+// and swaps the primary and backup endpoints when the backup outperformed
+// the primary over a whole window. Identical probes are used rather than
+// the latency of user calls, which mostly go to the primary and whose cost
+// varies by method.
+func (c *client) promoteOnLatency(window time.Duration, ratio float64) {
+	interval := window / promotionProbes
+	if interval < time.Second {
+		interval = time.Second
+	}
+	c.goBackground(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		stats := make(map[*endpoint]*qualityStats)
+		started := time.Now()
+		for {
+			select {
+			case <-c.done:
+				return
+			case now := <-ticker.C:
+				c.probeLatency(stats, interval)
+				if now.Sub(started) < window {
+					continue
+				}
+				c.evaluatePromotion(stats, ratio)
+				stats = make(map[*endpoint]*qualityStats)
+				started = now
+			}
+		}
+	})
+}
+
+func (c *client) probeLatency(stats map[*endpoint]*qualityStats, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for _, e := range c.endpoints() {
+		if e.unavailable() {
+			continue
+		}
+		t := time.Now()
+		err := e.call(ctx, func(ec *ethclient.Client) error {
+			_, err := ec.BlockNumber(ctx)
+			return err
+		})
+		c.metrics.Observe(promotionProbeMethod, t, e.name, err == nil)
+		if stats[e] == nil {
+			stats[e] = &qualityStats{}
+		}
+		stats[e].observe(time.Since(t), err)
+	}
+}
+
+func (c *client) evaluatePromotion(stats map[*endpoint]*qualityStats, ratio float64) {
+	endpoints := c.endpoints()
+	primary, backup := endpoints[0], endpoints[1]
+	p, b := stats[primary], stats[backup]
+	if p == nil || b == nil || backup.unavailable() || !b.outperforms(p, ratio) {
+		return
+	}
+	c.promoted.Store(!c.promoted.Load())
+	c.metrics.ObservePromotion(backup.name)
+	c.observePreference()
+	c.logger.Warn().Msgf("promoting rpc %s to primary over %s: %s latency, %.0f%% errors against %s, %.0f%%",
+		backup.name, primary.name, b.avgLatency(), 100*b.errorRate(), p.avgLatency(), 100*p.errorRate())
+}
@@ -0,0 +1,76 @@
+package ethclient
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func qualityOf(samples, errs int, avg time.Duration) *qualityStats {
+	s := &qualityStats{}
+	for i := 0; i < samples; i++ {
+		if i < errs {
+			s.observe(0, errTest)
+		} else {
+			s.observe(avg, nil)
+		}
+	}
+	return s
+}
+
+var errTest = errors.New("test")
+
+func TestQualityStatsOutperforms(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		s, o *qualityStats
+		want bool
+	}{
+		{"clearly faster", qualityOf(10, 0, 50*time.Millisecond), qualityOf(10, 0, 100*time.Millisecond), true},
+		{"marginally faster", qualityOf(10, 0, 90*time.Millisecond), qualityOf(10, 0, 100*time.Millisecond), false},
+		{"slower", qualityOf(10, 0, 200*time.Millisecond), qualityOf(10, 0, 100*time.Millisecond), false},
+		{"fewer errors", qualityOf(10, 0, 200*time.Millisecond), qualityOf(10, 2, 100*time.Millisecond), true},
+		{"more errors", qualityOf(10, 2, 10*time.Millisecond), qualityOf(10, 0, 100*time.Millisecond), false},
+		{"always failing", qualityOf(10, 10, 0), qualityOf(10, 10, 0), false},
+		{"too few samples", qualityOf(2, 0, 10*time.Millisecond), qualityOf(10, 0, 100*time.Millisecond), false},
+		{"other too few samples", qualityOf(10, 0, 10*time.Millisecond), qualityOf(2, 0, 100*time.Millisecond), false},
+	} {
+		if got := tt.s.outperforms(tt.o, 0.8); got != tt.want {
+			t.Errorf("%s: outperforms = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluatePromotion(t *testing.T) {
+	logger := zerolog.Nop()
+	c := &client{logger: &logger, m: &endpoint{name: "main"}, b: &endpoint{name: "backup"}}
+
+	slow, fast := qualityOf(10, 0, 100*time.Millisecond), qualityOf(10, 0, 10*time.Millisecond)
+	c.evaluatePromotion(map[*endpoint]*qualityStats{c.m: fast, c.b: slow}, 0.8)
+	if c.endpoints()[0] != c.m {
+		t.Fatal("promoted a slower backup")
+	}
+
+	c.evaluatePromotion(map[*endpoint]*qualityStats{c.m: slow, c.b: fast}, 0.8)
+	if c.endpoints()[0] != c.b {
+		t.Fatal("faster backup not promoted")
+	}
+
+	// the roles are swapped, the former primary has to outperform now
+	c.evaluatePromotion(map[*endpoint]*qualityStats{c.m: slow, c.b: fast}, 0.8)
+	if c.endpoints()[0] != c.b {
+		t.Fatal("slower endpoint promoted back")
+	}
+	c.evaluatePromotion(map[*endpoint]*qualityStats{c.m: fast, c.b: slow}, 0.8)
+	if c.endpoints()[0] != c.m {
+		t.Fatal("faster endpoint not promoted back")
+	}
+
+	c.b.draining.Store(true)
+	c.evaluatePromotion(map[*endpoint]*qualityStats{c.m: slow, c.b: fast}, 0.8)
+	if c.endpoints()[0] != c.m {
+		t.Fatal("promoted a drained backup")
+	}
+}
@@ -0,0 +1,92 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// AccountResult is the result of GetProof, mirroring geth's
+// gethclient.AccountResult.
+type AccountResult struct {
+	Address      common.Address  `json:"address"`
+	AccountProof []string        `json:"accountProof"`
+	Balance      *big.Int        `json:"balance"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	Nonce        uint64          `json:"nonce"`
+	StorageHash  common.Hash     `json:"storageHash"`
+	StorageProof []StorageResult `json:"storageProof"`
+}
+
+// StorageResult is the proof of a storage slot in an AccountResult.
+type StorageResult struct {
+	Key   string   `json:"key"`
+	Value *big.Int `json:"value"`
+	Proof []string `json:"proof"`
+}
+
+// GetProof returns the account and the storage values of keys of account,
+// along with their Merkle proofs (eth_getProof), at blockNumber, the latest
+// block when nil. Like state reads, it skips endpoints that pruned the
+// state of blockNumber.
+func (c *client) GetProof(ctx context.Context, account common.Address, keys []string, blockNumber *big.Int) (*AccountResult, error) {
+	if keys == nil {
+		// null isn't a valid list of keys
+		keys = []string{}
+	}
+	return do(c.withStateBlock(ctx, blockNumber), c, "GetProof", func(ctx context.Context, _ *ethclient.Client) (*AccountResult, error) {
+		var res struct {
+			Address      common.Address `json:"address"`
+			AccountProof []string       `json:"accountProof"`
+			Balance      *hexutil.Big   `json:"balance"`
+			CodeHash     common.Hash    `json:"codeHash"`
+			Nonce        hexutil.Uint64 `json:"nonce"`
+			StorageHash  common.Hash    `json:"storageHash"`
+			StorageProof []struct {
+				Key   string       `json:"key"`
+				Value *hexutil.Big `json:"value"`
+				Proof []string     `json:"proof"`
+			} `json:"storageProof"`
+		}
+		if err := rpcClientFromContext(ctx).CallContext(ctx, &res, "eth_getProof", account, keys, blockNumberArg(blockNumber)); err != nil {
+			return nil, err
+		}
+		r := &AccountResult{
+			Address:      res.Address,
+			AccountProof: res.AccountProof,
+			Balance:      res.Balance.ToInt(),
+			CodeHash:     res.CodeHash,
+			Nonce:        uint64(res.Nonce),
+			StorageHash:  res.StorageHash,
+			StorageProof: make([]StorageResult, len(res.StorageProof)),
+		}
+		for i, st := range res.StorageProof {
+			r.StorageProof[i] = StorageResult{Key: st.Key, Value: st.Value.ToInt(), Proof: st.Proof}
+		}
+		return r, c.validate(ctx, nil, func() error { return checkProof(r, account, keys) })
+	})
+}
+
+// checkProof checks that r proves account and each of keys, in order.
+func checkProof(r *AccountResult, account common.Address, keys []string) error {
+	if r.Address != account {
+		return fmt.Errorf("proof of %s instead of %s", r.Address, account)
+	}
+	if len(r.AccountProof) == 0 {
+		return fmt.Errorf("proof of %s without account proof", account)
+	}
+	if len(r.StorageProof) != len(keys) {
+		return fmt.Errorf("proof of %s with %d storage proofs for %d keys", account, len(r.StorageProof), len(keys))
+	}
+	for i, st := range r.StorageProof {
+		// keys may be left-padded or not, compare their values
+		if common.HexToHash(st.Key) != common.HexToHash(keys[i]) {
+			return fmt.Errorf("storage proof of key %s instead of %s", st.Key, keys[i])
+		}
+	}
+	return nil
+}
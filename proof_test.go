@@ -0,0 +1,92 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// testProofs serves proofs of any account with one storage proof per key,
+// of the wrong account if wrongAccount is set.
+type testProofs struct {
+	wrongAccount bool
+	blocks       chan string
+}
+
+func (s *testProofs) GetProof(account common.Address, keys []string, block string) map[string]interface{} {
+	s.blocks <- block
+	if s.wrongAccount {
+		account = common.Address{0xff}
+	}
+	storage := make([]map[string]interface{}, len(keys))
+	for i, key := range keys {
+		storage[i] = map[string]interface{}{"key": key, "value": (*hexutil.Big)(big.NewInt(int64(i + 1))), "proof": []string{"0x01"}}
+	}
+	return map[string]interface{}{
+		"address":      account,
+		"accountProof": []string{"0xf8"},
+		"balance":      (*hexutil.Big)(big.NewInt(7)),
+		"codeHash":     common.Hash{2},
+		"nonce":        hexutil.Uint64(3),
+		"storageHash":  common.Hash{4},
+		"storageProof": storage,
+	}
+}
+
+func TestGetProof(t *testing.T) {
+	primary, failover := &testProofs{wrongAccount: true, blocks: make(chan string, 1)}, &testProofs{blocks: make(chan string, 1)}
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1}, primary),
+		newTestNodeWith(t, &testEthService{chainID: 1}, failover),
+	)
+	cfg.ValidateResponses = true
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	account := common.Address{1}
+	r, err := c.GetProof(context.Background(), account, []string{"0x01", "0x02"}, big.NewInt(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Address != account || r.Balance.Int64() != 7 || r.Nonce != 3 || len(r.StorageProof) != 2 || r.StorageProof[1].Value.Int64() != 2 {
+		t.Fatalf("GetProof = %+v, want the valid proof of the failover", r)
+	}
+	if block := <-failover.blocks; block != "0x5" {
+		t.Fatalf("proof at block %s, want 0x5", block)
+	}
+	<-primary.blocks
+
+	if r, err := c.GetProof(context.Background(), account, nil, nil); err != nil || len(r.StorageProof) != 0 {
+		t.Fatalf("GetProof without keys = %+v, %v", r, err)
+	}
+	<-primary.blocks
+	if block := <-failover.blocks; block != "latest" {
+		t.Fatalf("proof at block %s, want latest", block)
+	}
+
+	failover.wrongAccount = true
+	if _, err := c.GetProof(context.Background(), account, nil, nil); !errors.Is(err, ErrInvalidResponse) {
+		t.Fatalf("GetProof error = %v, want ErrInvalidResponse", err)
+	}
+}
+
+func TestCheckProof(t *testing.T) {
+	account := common.Address{1}
+	r := &AccountResult{Address: account, AccountProof: []string{"0xf8"}, StorageProof: []StorageResult{{Key: "0x1"}}}
+	if err := checkProof(r, account, []string{"0x0000000000000000000000000000000000000000000000000000000000000001"}); err != nil {
+		t.Errorf("checkProof of a padded key = %v", err)
+	}
+	if err := checkProof(r, account, []string{"0x2"}); err == nil {
+		t.Error("checkProof accepted the proof of another key")
+	}
+	if err := checkProof(r, account, nil); err == nil {
+		t.Error("checkProof accepted a proof of another number of keys")
+	}
+}
@@ -0,0 +1,174 @@
+package ethclient
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// rateLimitPenalty is how long an endpoint is skipped after answering
+	// 429 without a Retry-After header.
+	rateLimitPenalty = time.Second
+	// maxRateLimitPenalty caps the Retry-After of a provider.
+	maxRateLimitPenalty = time.Minute
+)
+
+// ErrRateLimited is returned for calls that an endpoint can't take for now,
+// either because its rate limit from Config is exhausted or because it
+// answered 429. It comes wrapped in a RetryAfterError.
+var ErrRateLimited = errors.New("rpc endpoint rate limited")
+
+// tokenBucket is a client side rate limit of rate calls per second, with
+// bursts of up to burst calls.
+type tokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(rate)))
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// take takes a token, or returns how long until one is available.
+func (b *tokenBucket) take(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// penalty keeps an endpoint that answered 429 out of the way until the
+// time it asked for.
+type penalty struct {
+	until atomic.Int64 // unix nanoseconds
+}
+
+// extend penalizes for d from now, unless already penalized for longer.
+func (p *penalty) extend(d time.Duration) {
+	if d > maxRateLimitPenalty {
+		d = maxRateLimitPenalty
+	}
+	until := time.Now().Add(d).UnixNano()
+	for {
+		cur := p.until.Load()
+		if cur >= until || p.until.CompareAndSwap(cur, until) {
+			return
+		}
+	}
+}
+
+func (p *penalty) remaining(now time.Time) time.Duration {
+	if p == nil {
+		return 0
+	}
+	if d := time.Duration(p.until.Load() - now.UnixNano()); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// admit reports whether e can take a call now, or else for how long it
+// can't.
+func (e *endpoint) admit(now time.Time) time.Duration {
+	if d := e.penalty.remaining(now); d > 0 {
+		return d
+	}
+	if e.limiter == nil {
+		return 0
+	}
+	return e.limiter.take(now)
+}
+
+// rateLimited returns the error of a call e couldn't take for wait.
+func (e *endpoint) rateLimited(wait time.Duration) error {
+	return &RetryAfterError{Err: fmt.Errorf("%w: %s", ErrRateLimited, e.name), RetryAfter: wait}
+}
+
+// penalizeIfRateLimited penalizes e if err is an HTTP 429, a longer
+// Retry-After already honored by the transport is kept. It reports whether
+// err is an HTTP 429.
+func (e *endpoint) penalizeIfRateLimited(err error) bool {
+	var httpErr rpc.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	if e.penalty != nil {
+		e.penalty.extend(rateLimitPenalty)
+	}
+	return true
+}
+
+// retryAfterTransport penalizes an endpoint for the Retry-After of its 429
+// responses.
+type retryAfterTransport struct {
+	base    http.RoundTripper
+	penalty *penalty
+}
+
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			t.penalty.extend(d)
+		}
+	}
+	return resp, err
+}
+
+// withRetryAfter returns a copy of hc whose transport honors Retry-After
+// headers by penalizing p.
+func withRetryAfter(hc *http.Client, p *penalty) *http.Client {
+	if hc == nil {
+		hc = new(http.Client)
+	}
+	cp := *hc
+	base := cp.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	cp.Transport = &retryAfterTransport{base: base, penalty: p}
+	return &cp
+}
+
+// parseRetryAfter parses a Retry-After header, in seconds or as an HTTP
+// date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
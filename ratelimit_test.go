@@ -0,0 +1,132 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	b := newTokenBucket(10, 2)
+	b.last = now
+	for i := 0; i < 2; i++ {
+		if wait := b.take(now); wait != 0 {
+			t.Fatalf("take %d within the burst waits %s", i, wait)
+		}
+	}
+	if wait := b.take(now); wait != 100*time.Millisecond {
+		t.Fatalf("take beyond the burst waits %s, want 100ms", wait)
+	}
+	if wait := b.take(now.Add(100 * time.Millisecond)); wait != 0 {
+		t.Fatalf("take after refill waits %s", wait)
+	}
+	if newTokenBucket(0, 5) != nil {
+		t.Fatal("rate limit without a rate")
+	}
+	if b := newTokenBucket(2.5, 0); b.burst != 3 {
+		t.Fatalf("default burst = %v, want 3", b.burst)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 7, 3, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		v    string
+		want time.Duration
+		ok   bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"Sun, 07 Jan 2024 03:00:30 GMT", 30 * time.Second, true},
+		{"Sun, 07 Jan 2024 02:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	} {
+		if d, ok := parseRetryAfter(tt.v, now); d != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %s, %v, want %s, %v", tt.v, d, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// newThrottledNode serves svc, answering 429 with a Retry-After of 30s once
+// throttled is set.
+func newThrottledNode(t *testing.T, svc *testEthService, throttled *atomic.Bool, calls *atomic.Int64) string {
+	t.Helper()
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", svc); err != nil {
+		t.Fatal(err)
+	}
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if throttled.Load() {
+			w.Header().Set("Retry-After", "30")
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+		srv.ServeHTTP(w, r)
+	}))
+	t.Cleanup(func() {
+		hs.Close()
+		srv.Stop()
+	})
+	return hs.URL
+}
+
+func TestRetryAfterPenalizesEndpoint(t *testing.T) {
+	var throttled atomic.Bool
+	var calls atomic.Int64
+	cfg := testConfig(
+		newThrottledNode(t, &testEthService{chainID: 1, head: 1}, &throttled, &calls),
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 2}),
+	)
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	throttled.Store(true)
+	calls.Store(0)
+	for i := 0; i < 3; i++ {
+		if n, err := c.(*client).BlockNumber(context.Background()); err != nil || n != 2 {
+			t.Fatalf("BlockNumber = %d, %v, want 2 from the failover endpoint", n, err)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("throttled endpoint called %d times, want once", n)
+	}
+	if d := c.(*client).m.penalty.remaining(time.Now()); d < 29*time.Second {
+		t.Fatalf("penalty = %s, want the Retry-After of 30s", d)
+	}
+}
+
+func TestRateLimitFailsOver(t *testing.T) {
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 1}),
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 2}),
+	)
+	cfg.VerifyChainID = false
+	cfg.RpcRateLimit, cfg.RpcRateBurst = 0.1, 1
+	cfg.FailoverRpcRateLimit, cfg.FailoverRpcRateBurst = 0.1, 1
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for _, want := range []uint64{1, 2} {
+		if n, err := c.(*client).BlockNumber(context.Background()); err != nil || n != want {
+			t.Fatalf("BlockNumber = %d, %v, want %d", n, err, want)
+		}
+	}
+	_, err = c.(*client).BlockNumber(context.Background())
+	if after, ok := RetryAfter(err); !errors.Is(err, ErrRateLimited) || !ok || after <= 0 {
+		t.Fatalf("BlockNumber error = %v, want ErrRateLimited with a retry delay", err)
+	}
+}
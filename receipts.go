@@ -0,0 +1,104 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// BlockReceipts returns the receipts of the transactions of a block, in
+// order, with eth_getBlockReceipts. On endpoints lacking it, the block's
+// transactions are fetched and then their receipts in batches; such
+// endpoints are remembered for a while to go straight to that.
+func (c *client) BlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*types.Receipt, error) {
+	return do(ctx, c, "BlockReceipts", func(ctx context.Context, _ *ethclient.Client) ([]*types.Receipt, error) {
+		e := endpointFromContext(ctx)
+		if e.supports("BlockReceipts") {
+			r, err := blockReceipts(ctx, rpcClientFromContext(ctx), blockNrOrHash)
+			if !c.lacks(e, "BlockReceipts", "eth_getBlockReceipts", err) {
+				return r, c.validate(ctx, err, func() error { return checkBlockReceipts(r, blockNrOrHash) })
+			}
+		}
+		r, err := c.receiptsOneByOne(ctx, rpcClientFromContext(ctx), blockNrOrHash)
+		return r, c.validate(ctx, err, func() error { return checkBlockReceipts(r, blockNrOrHash) })
+	})
+}
+
+// blockReceipts calls eth_getBlockReceipts on rc.
+func blockReceipts(ctx context.Context, rc *rpc.Client, blockNrOrHash rpc.BlockNumberOrHash) ([]*types.Receipt, error) {
+	var r []*types.Receipt
+	if err := rc.CallContext(ctx, &r, "eth_getBlockReceipts", blockNrOrHashArg(blockNrOrHash)); err != nil {
+		return nil, err
+	}
+	if r == nil {
+		// null for unknown blocks, [] for blocks without transactions
+		return nil, ethereum.NotFound
+	}
+	return r, nil
+}
+
+// blockNrOrHashArg encodes a block number or hash argument, as a hash or
+// a hex number or tag (its String method encodes numbers in decimal).
+func blockNrOrHashArg(blockNrOrHash rpc.BlockNumberOrHash) interface{} {
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		return hash
+	}
+	number, _ := blockNrOrHash.Number()
+	return number
+}
+
+// receiptsOneByOne fetches the receipts of a block from rc with
+// eth_getTransactionReceipt.
+func (c *client) receiptsOneByOne(ctx context.Context, rc *rpc.Client, blockNrOrHash rpc.BlockNumberOrHash) ([]*types.Receipt, error) {
+	var block *struct {
+		Transactions []common.Hash `json:"transactions"`
+	}
+	var err error
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		err = rc.CallContext(ctx, &block, "eth_getBlockByHash", hash, false)
+	} else {
+		number, _ := blockNrOrHash.Number()
+		err = rc.CallContext(ctx, &block, "eth_getBlockByNumber", number, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, ethereum.NotFound
+	}
+	receipts := make([]*types.Receipt, len(block.Transactions))
+	size := c.batchSize()
+	for start := 0; start < len(receipts); start += size {
+		end := start + size
+		if end > len(receipts) {
+			end = len(receipts)
+		}
+		elems := make([]rpc.BatchElem, 0, end-start)
+		for i := start; i < end; i++ {
+			elems = append(elems, rpc.BatchElem{
+				Method: "eth_getTransactionReceipt",
+				Args:   []interface{}{block.Transactions[i]},
+				Result: &receipts[i],
+			})
+		}
+		if err := rc.BatchCallContext(ctx, elems); err != nil {
+			return nil, err
+		}
+		for j, elem := range elems {
+			if elem.Error != nil {
+				return nil, fmt.Errorf("receipt of %s: %w", block.Transactions[start+j], elem.Error)
+			}
+			if receipts[start+j] == nil {
+				// the block is known but not its receipts, e.g. a node
+				// still indexing them
+				return nil, fmt.Errorf("receipt of %s: %w", block.Transactions[start+j], ethereum.NotFound)
+			}
+		}
+	}
+	return receipts, nil
+}
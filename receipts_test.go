@@ -0,0 +1,108 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testBlock serves block 3 with two transactions and their receipts,
+// without eth_getBlockReceipts.
+type testBlock struct {
+	receiptCalls atomic.Int32
+}
+
+var testBlockHash = common.Hash{3}
+
+func (s *testBlock) GetBlockByNumber(number rpc.BlockNumber, _ bool) map[string]interface{} {
+	if number != 3 {
+		return nil
+	}
+	return map[string]interface{}{"hash": testBlockHash, "transactions": []common.Hash{{1}, {2}}}
+}
+
+func (s *testBlock) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	s.receiptCalls.Add(1)
+	return &types.Receipt{
+		Status:           1,
+		TxHash:           hash,
+		BlockHash:        testBlockHash,
+		BlockNumber:      big.NewInt(3),
+		TransactionIndex: uint(hash[0] - 1),
+		Logs:             []*types.Log{},
+	}
+}
+
+// testBlockWithReceipts serves eth_getBlockReceipts as well.
+type testBlockWithReceipts struct {
+	testBlock
+	calls atomic.Int32
+}
+
+func (s *testBlockWithReceipts) GetBlockReceipts(number rpc.BlockNumberOrHash) []*types.Receipt {
+	s.calls.Add(1)
+	if n, ok := number.Number(); !ok || n != 3 {
+		return nil
+	}
+	return []*types.Receipt{s.GetTransactionReceipt(common.Hash{1}), s.GetTransactionReceipt(common.Hash{2})}
+}
+
+func TestBlockReceipts(t *testing.T) {
+	legacy, recent := &testBlock{}, &testBlockWithReceipts{}
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1}, legacy),
+		newTestNodeWith(t, &testEthService{chainID: 1}, recent),
+	)
+	cfg.ValidateResponses = true
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// the primary lacks eth_getBlockReceipts, receipts come one by one
+	for i := 0; i < 2; i++ {
+		r, err := c.BlockReceipts(context.Background(), rpc.BlockNumberOrHashWithNumber(3))
+		if err != nil || len(r) != 2 || r[0].TxHash != (common.Hash{1}) || r[1].TxHash != (common.Hash{2}) {
+			t.Fatalf("BlockReceipts = %v, %v, want the receipts of both transactions", r, err)
+		}
+	}
+	if n := legacy.receiptCalls.Load(); n != 4 {
+		t.Fatalf("receipts fetched one by one %d times, want 4", n)
+	}
+	if n := recent.calls.Load(); n != 0 {
+		t.Fatalf("eth_getBlockReceipts called %d times on the failover, want 0", n)
+	}
+
+	c.(*client).promoted.Store(true)
+	if r, err := c.BlockReceipts(context.Background(), rpc.BlockNumberOrHashWithNumber(3)); err != nil || len(r) != 2 {
+		t.Fatalf("BlockReceipts = %v, %v", r, err)
+	}
+	if n := recent.calls.Load(); n != 1 {
+		t.Fatalf("eth_getBlockReceipts called %d times, want 1", n)
+	}
+}
+
+func TestCheckBlockReceipts(t *testing.T) {
+	receipt := func(index uint, block common.Hash) *types.Receipt {
+		return &types.Receipt{BlockHash: block, BlockNumber: big.NewInt(3), TransactionIndex: index}
+	}
+	byNumber := rpc.BlockNumberOrHashWithNumber(3)
+	if err := checkBlockReceipts([]*types.Receipt{receipt(0, testBlockHash), receipt(1, testBlockHash)}, byNumber); err != nil {
+		t.Errorf("checkBlockReceipts = %v", err)
+	}
+	if err := checkBlockReceipts([]*types.Receipt{receipt(1, testBlockHash), receipt(0, testBlockHash)}, byNumber); err == nil {
+		t.Error("checkBlockReceipts accepted receipts out of order")
+	}
+	if err := checkBlockReceipts([]*types.Receipt{receipt(0, common.Hash{4})}, rpc.BlockNumberOrHashWithHash(testBlockHash, false)); err == nil {
+		t.Error("checkBlockReceipts accepted receipts of another block")
+	}
+	if err := checkBlockReceipts([]*types.Receipt{receipt(0, testBlockHash)}, rpc.BlockNumberOrHashWithNumber(4)); err == nil {
+		t.Error("checkBlockReceipts accepted receipts of another number")
+	}
+}
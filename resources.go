@@ -0,0 +1,170 @@
+package ethclient
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	maxReapInterval = 30 * time.Second
+	redialInterval  = 5 * time.Second
+)
+
+// goBackground runs fn in a goroutine owned by the client. Close waits for
+// it to return, fn is expected to exit once c.done is closed.
+func (c *client) goBackground(fn func()) {
+	c.wg.Add(1)
+	c.metrics.AddGoroutines(1)
+	go func() {
+		defer c.wg.Done()
+		defer c.metrics.AddGoroutines(-1)
+		fn()
+	}()
+}
+
+// reapIdleConnections periodically closes websocket connections that have
+// not been used for timeout. They are dialed again on next use.
+func (c *client) reapIdleConnections(timeout time.Duration) {
+	interval := timeout / 2
+	if interval > maxReapInterval {
+		interval = maxReapInterval
+	}
+	if interval < time.Second {
+		interval = time.Second
+	}
+	c.goBackground(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.done:
+				return
+			case <-ticker.C:
+				for _, e := range c.allEndpoints() {
+					if e.isWebsocket() && e.reapIfIdle(timeout) {
+						c.logger.Debug().Msgf("closed idle connection to rpc %s", e.name)
+					}
+				}
+			}
+		}
+	})
+}
+
+// redialFailed periodically dials the endpoints whose last dial failed,
+// they are only used as a last resort until then.
+func (c *client) redialFailed(interval time.Duration) {
+	c.goBackground(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.done:
+				return
+			case <-ticker.C:
+				for _, e := range c.endpoints() {
+					if !e.dialFailed.Load() {
+						continue
+					}
+					ctx, cancel := context.WithTimeout(context.Background(), interval)
+					if err := e.call(ctx, func(*ethclient.Client) error { return nil }); err == nil {
+						c.logger.Info().Msgf("connected to rpc %s", e.name)
+					}
+					cancel()
+				}
+			}
+		}
+	})
+}
+
+// trackedSubscription counts as an active subscription on its endpoint
+// until it ends, which keeps the connection from being reaped.
+//
+// The caller's context only bounds establishing the subscription (retries
+// and failover included). Unless SubscriptionFollowsContext is set, the
+// subscription then outlives it, so a short-lived setup context doesn't
+// later tear down a healthy subscription.
+type trackedSubscription struct {
+	sub  ethereum.Subscription
+	err  chan error
+	once sync.Once
+}
+
+// callKey holds the context of the call an attempt context is derived
+// from, see try.
+type callKey struct{}
+
+// callContext returns the context of the call ctx is an attempt of, which
+// isn't ended by the attempt timeout.
+func callContext(ctx context.Context) context.Context {
+	if call, ok := ctx.Value(callKey{}).(context.Context); ok {
+		return call
+	}
+	return ctx
+}
+
+// subscriptionContext returns the context a subscription made with ctx
+// subscribes again with: ctx when SubscriptionFollowsContext is set, else
+// one with the values of ctx, the hints among them, that never ends.
+func (c *client) subscriptionContext(ctx context.Context) context.Context {
+	if c.cfg.SubscriptionFollowsContext {
+		return ctx
+	}
+	return detachedContext{ctx}
+}
+
+// detachedContext keeps the values of its parent but not its deadline and
+// cancellation.
+type detachedContext struct{ context.Context }
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+func (c *client) trackSubscription(ctx context.Context, e *endpoint, sub ethereum.Subscription) ethereum.Subscription {
+	if e == nil || sub == nil {
+		return sub
+	}
+	// the attempt context is cancelled once the subscription is set up
+	ctx = callContext(ctx)
+	e.subs.Add(1)
+	c.metrics.AddSubscriptions(e.name, 1)
+	s := &trackedSubscription{
+		sub: sub,
+		err: make(chan error, 1),
+	}
+	var ctxDone <-chan struct{}
+	if c.cfg.SubscriptionFollowsContext {
+		ctxDone = ctx.Done()
+	}
+	c.subscriptions.add()
+	c.goBackground(func() {
+		defer close(s.err)
+		defer c.subscriptions.done()
+		defer c.metrics.AddSubscriptions(e.name, -1)
+		defer e.subs.Add(-1)
+		select {
+		case err, ok := <-sub.Err():
+			if ok {
+				s.err <- err
+			}
+		case <-ctxDone:
+			sub.Unsubscribe()
+			s.err <- ctx.Err()
+		case <-c.done:
+			sub.Unsubscribe()
+		}
+	})
+	return s
+}
+
+func (s *trackedSubscription) Unsubscribe() {
+	s.once.Do(s.sub.Unsubscribe)
+}
+
+func (s *trackedSubscription) Err() <-chan error {
+	return s.err
+}
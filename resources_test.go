@@ -0,0 +1,48 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/event"
+)
+
+func TestTrackSubscriptionFollowsContext(t *testing.T) {
+	for _, follows := range []bool{false, true} {
+		cfg := testConfig(newTestNode(t, 1), newTestNode(t, 1))
+		cfg.SubscriptionFollowsContext = follows
+		c, err := New("test", "eth", cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cl := c.(*client)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		upstream := event.NewSubscription(func(quit <-chan struct{}) error {
+			<-quit
+			return nil
+		})
+		sub := cl.trackSubscription(ctx, cl.m, upstream)
+		cancel()
+		select {
+		case err := <-sub.Err():
+			if !follows {
+				t.Fatalf("subscription ended with its setup context: %v", err)
+			}
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("subscription error = %v, want context.Canceled", err)
+			}
+		case <-time.After(100 * time.Millisecond):
+			if follows {
+				t.Fatal("subscription outlived its context with SubscriptionFollowsContext")
+			}
+			if n := cl.m.subs.Load(); n != 1 {
+				t.Fatalf("%d subscriptions on main, want 1", n)
+			}
+		}
+		sub.Unsubscribe()
+		c.Close()
+	}
+}
@@ -0,0 +1,55 @@
+package ethclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+type responseSizeKey struct{}
+
+// withResponseSize returns a context in which the bytes of the HTTP
+// responses read are added to n.
+func withResponseSize(ctx context.Context, n *atomic.Int64) context.Context {
+	return context.WithValue(ctx, responseSizeKey{}, n)
+}
+
+// responseSizeTransport counts the bytes of the response bodies of the
+// requests made in a context of withResponseSize.
+type responseSizeTransport struct {
+	base http.RoundTripper
+}
+
+func (t *responseSizeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if n, ok := req.Context().Value(responseSizeKey{}).(*atomic.Int64); ok && err == nil {
+		resp.Body = &countingBody{ReadCloser: resp.Body, n: n}
+	}
+	return resp, err
+}
+
+type countingBody struct {
+	io.ReadCloser
+	n *atomic.Int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	return n, err
+}
+
+// withResponseSizes returns a copy of hc counting the bytes of responses.
+func withResponseSizes(hc *http.Client) *http.Client {
+	if hc == nil {
+		hc = new(http.Client)
+	}
+	cp := *hc
+	base := cp.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	cp.Transport = &responseSizeTransport{base: base}
+	return &cp
+}
@@ -0,0 +1,205 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	defaultRetryInitialBackoff = 100 * time.Millisecond
+	defaultRetryMaxBackoff     = 2 * time.Second
+)
+
+// RetryPolicy controls how a call is retried against a single endpoint
+// before the client fails over to the next one.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts per endpoint, values
+	// below 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the base delay before the first retry, it doubles
+	// on every subsequent retry up to MaxBackoff. Full jitter is applied.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// RetryOn decides whether an error is worth retrying on the same
+	// endpoint, IsTransientError is used when nil.
+	RetryOn func(err error) bool
+}
+
+// backoff returns the delay to wait before the given retry (starting at 1).
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	initial, max := p.InitialBackoff, p.MaxBackoff
+	if initial <= 0 {
+		initial = defaultRetryInitialBackoff
+	}
+	if max <= 0 {
+		max = defaultRetryMaxBackoff
+	}
+	d := initial
+	for i := 1; i < retry && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	// full jitter keeps concurrent callers from retrying in lockstep
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+func (p *RetryPolicy) shouldRetry(err error) bool {
+	if p.RetryOn != nil {
+		return p.RetryOn(err)
+	}
+	return IsTransientError(err)
+}
+
+// IsTransientError reports whether err looks like a temporary condition
+// (rate limiting, gateway errors, network blips) that is likely to succeed
+// on an immediate retry against the same endpoint.
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		switch httpErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32005 {
+		// limit exceeded, a rate limit unless about the size of a query
+		return !IsLogRangeError(err)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "rate limit") || strings.Contains(msg, "too many requests")
+}
+
+// nonIdempotentMethods are not retried by default, as a retry after a
+// timeout could submit the same request twice.
+var nonIdempotentMethods = map[string]bool{
+	"SendTransaction": true,
+}
+
+// ErrAttemptTimeout is returned when an attempt exceeded the method timeout
+// from Config, unlike the caller's deadline it triggers a failover.
+var ErrAttemptTimeout = errors.New("rpc call timed out")
+
+// methodTimeout returns the timeout of a single attempt of method, 0 if
+// none applies.
+func (c *client) methodTimeout(method string) time.Duration {
+	ms, ok := c.cfg.MethodTimeoutsMs[method]
+	if !ok {
+		ms = c.cfg.MethodTimeoutMs
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// retryPolicy returns the policy for method, falling back to the client
+// wide defaults from Config. Non idempotent methods only get retried when
+// configured explicitly per method.
+func (c *client) retryPolicy(method string) *RetryPolicy {
+	if p, ok := c.cfg.RetryPolicies[method]; ok {
+		return p
+	}
+	maxAttempts := c.cfg.RetryMaxAttempts
+	if nonIdempotentMethods[method] {
+		maxAttempts = 1
+	}
+	p := &RetryPolicy{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: time.Duration(c.cfg.RetryInitialBackoffMs) * time.Millisecond,
+		MaxBackoff:     time.Duration(c.cfg.RetryMaxBackoffMs) * time.Millisecond,
+	}
+	if n, ok := c.cfg.RetryMethodMaxAttempts[method]; ok {
+		p.MaxAttempts = n
+	}
+	return p
+}
+
+// try runs fn against a single endpoint, retrying transient errors with
+// exponential backoff.
+func (c *client) try(ctx context.Context, method string, e *endpoint, failover bool, fn func(context.Context, *ethclient.Client) error) error {
+	policy := c.retryPolicy(method)
+	for attempt := 1; ; attempt++ {
+		actx, span := c.startSpan(ctx, "ethclient."+method+".attempt",
+			attrMethod.String(method),
+			attrEndpoint.String(e.name),
+			attrAttempt.Int(attempt),
+			attrFailover.Bool(failover))
+		actx = context.WithValue(actx, endpointKey{}, e)
+		actx = context.WithValue(actx, callKey{}, ctx)
+		var size atomic.Int64
+		actx = withResponseSize(actx, &size)
+		timeout := c.methodTimeout(method)
+		cancel := func() {}
+		if timeout > 0 {
+			actx, cancel = context.WithTimeout(actx, timeout)
+		}
+		t := time.Now()
+		c.attemptWindow.add(t)
+		if wait := e.admit(t); wait > 0 {
+			c.rateLimitWindow.add(t)
+			err := e.rateLimited(wait)
+			cancel()
+			endSpan(span, err)
+			return err
+		}
+		err := e.callRPC(actx, func(ec *ethclient.Client, rc *rpc.Client) error {
+			return fn(context.WithValue(actx, rpcClientKey{}, rc), ec)
+		})
+		if e.penalizeIfRateLimited(err) {
+			c.rateLimitWindow.add(t)
+		}
+		err = c.normalizeNotFound(err)
+		if err != nil && ctx.Err() == nil && errors.Is(actx.Err(), context.DeadlineExceeded) {
+			// only the attempt timed out, let the caller fail over
+			err = fmt.Errorf("%w: %s on rpc %s after %s", ErrAttemptTimeout, method, e.name, timeout)
+		}
+		cancel()
+		ok := c.successful(method, err)
+		c.metrics.ObserveCall(actx, method, t, e.name, ok)
+		c.metrics.ObserveResponseBytes(method, e.name, size.Load())
+		c.logCall(method, e, attempt, failover, time.Since(t), err)
+		if ok {
+			c.stats.observe(method, e.name, time.Since(t), nil)
+		} else {
+			c.stats.observe(method, e.name, time.Since(t), err)
+		}
+		c.observeQuality(ctx, method, e, time.Since(t), err)
+		e.outcome.observe(ctx, err, ok)
+		c.metrics.SetHealthy(e.name, e.health() == HealthHealthy)
+		endSpan(span, err)
+		if err == nil || attempt >= policy.MaxAttempts || !policy.shouldRetry(err) {
+			return err
+		}
+
+		timer := time.NewTimer(policy.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for _, tt := range []struct {
+		retry int
+		max   time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{10, time.Second},
+	} {
+		for i := 0; i < 100; i++ {
+			if d := p.backoff(tt.retry); d < 0 || d > tt.max {
+				t.Fatalf("backoff(%d) = %s, want within [0, %s]", tt.retry, d, tt.max)
+			}
+		}
+	}
+}
+
+func TestRetryPolicyBackoffDefaults(t *testing.T) {
+	p := &RetryPolicy{}
+	for i := 0; i < 100; i++ {
+		if d := p.backoff(100); d > defaultRetryMaxBackoff {
+			t.Fatalf("backoff = %s, want at most %s", d, defaultRetryMaxBackoff)
+		}
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsTransientError(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{context.Canceled, false},
+		{context.DeadlineExceeded, false},
+		{rpc.HTTPError{StatusCode: 429}, true},
+		{rpc.HTTPError{StatusCode: 502}, true},
+		{rpc.HTTPError{StatusCode: 503}, true},
+		{rpc.HTTPError{StatusCode: 504}, true},
+		{rpc.HTTPError{StatusCode: 400}, false},
+		{rpc.HTTPError{StatusCode: 500}, false},
+		{codeError{-32005}, true},
+		{codeError{-32000}, false},
+		{timeoutError{}, true},
+		{io.EOF, true},
+		{fmt.Errorf("read: %w", io.ErrUnexpectedEOF), true},
+		{fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{errors.New("Rate limit exceeded"), true},
+		{errors.New("429 Too Many Requests"), true},
+		{errors.New("execution reverted"), false},
+	} {
+		if got := IsTransientError(tt.err); got != tt.want {
+			t.Errorf("IsTransientError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyNonIdempotent(t *testing.T) {
+	c := &client{cfg: &Config{RetryMaxAttempts: 3}}
+	if n := c.retryPolicy("BlockNumber").MaxAttempts; n != 3 {
+		t.Errorf("BlockNumber MaxAttempts = %d, want 3", n)
+	}
+	if n := c.retryPolicy("SendTransaction").MaxAttempts; n != 1 {
+		t.Errorf("SendTransaction MaxAttempts = %d, want 1", n)
+	}
+	c.cfg.RetryMethodMaxAttempts = map[string]int{"SendTransaction": 2}
+	if n := c.retryPolicy("SendTransaction").MaxAttempts; n != 2 {
+		t.Errorf("configured SendTransaction MaxAttempts = %d, want 2", n)
+	}
+}
+
+func TestMethodTimeoutFailsOver(t *testing.T) {
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 1, delay: 300 * time.Millisecond}),
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 2}),
+	)
+	cfg.MethodTimeoutMs = 50
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.(*client).Close()
+
+	start := time.Now()
+	n, err := c.(*client).BlockNumber(context.Background())
+	if err != nil || n != 2 {
+		t.Fatalf("BlockNumber = %d, %v, want 2 from the failover endpoint", n, err)
+	}
+	if took := time.Since(start); took > 200*time.Millisecond {
+		t.Fatalf("failover took %s", took)
+	}
+
+	cfg.MethodTimeoutsMs = map[string]int{"BlockNumber": 0}
+	if d := c.(*client).methodTimeout("BlockNumber"); d != 0 {
+		t.Fatalf("overridden timeout = %s, want none", d)
+	}
+	if d := c.(*client).methodTimeout("ChainID"); d != 50*time.Millisecond {
+		t.Fatalf("default timeout = %s, want 50ms", d)
+	}
+}
+
+func TestMethodTimeoutAllEndpoints(t *testing.T) {
+	slow := &testEthService{chainID: 1, head: 1, delay: 200 * time.Millisecond}
+	cfg := testConfig(newTestNodeWith(t, slow), newTestNodeWith(t, slow))
+	cfg.MethodTimeoutMs = 20
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.(*client).Close()
+	if _, err := c.(*client).BlockNumber(context.Background()); !errors.Is(err, ErrAttemptTimeout) {
+		t.Fatalf("BlockNumber error = %v, want ErrAttemptTimeout", err)
+	}
+}
@@ -0,0 +1,141 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// RotateEndpointURL points the endpoint called name to newURL without
+// downtime, e.g. to rotate an API key embedded in the url. The new url is
+// dialed and checked to serve the expected chain and answer requests while
+// the current connection keeps serving calls. It is then swapped in, and
+// the previous connection is closed once its calls in flight are done.
+// The endpoint is left untouched if the new url fails the checks.
+func (c *client) RotateEndpointURL(ctx context.Context, name string, newURL string) error {
+	var e *endpoint
+	for _, candidate := range c.endpoints() {
+		if candidate.name == name {
+			e = candidate
+		}
+	}
+	if e == nil {
+		return fmt.Errorf("unknown rpc endpoint %q", name)
+	}
+
+	rc, err := c.dialVerified(ctx, e, newURL)
+	if err != nil {
+		return err
+	}
+	c.rotate(e, newURL, rc)
+	return nil
+}
+
+// UpdateEndpoints applies the endpoints of cfg to the running client, e.g.
+// reloaded from the environment: the urls that changed, websocket ones
+// included, are checked and rotated as by RotateEndpointURL, and the
+// endpoint named cfg.RpcName becomes the primary (until promoted over
+// again with adaptive routing or promotion). Endpoints are matched by
+// name, the client serving exactly a primary and a failover, so none can
+// be added or removed. Nothing changes if a new url fails the checks.
+func (c *client) UpdateEndpoints(ctx context.Context, cfg *Config) error {
+	if err := cfg.Valid(); err != nil {
+		return err
+	}
+	urls := map[string]string{
+		cfg.RpcName:                 cfg.RpcUrl,
+		cfg.FailoverRpcName:         cfg.FailoverRpcUrl,
+		cfg.RpcName + "-ws":         cfg.WsUrl,
+		cfg.FailoverRpcName + "-ws": cfg.FailoverWsUrl,
+	}
+	_, known := urls[c.m.name]
+	if _, ok := urls[c.b.name]; !ok || !known || cfg.RpcName == cfg.FailoverRpcName {
+		return fmt.Errorf("endpoints %s and %s don't match rpc %s and %s, endpoints can't be added at runtime",
+			cfg.RpcName, cfg.FailoverRpcName, c.m.name, c.b.name)
+	}
+	ws := 0
+	for _, url := range []string{cfg.WsUrl, cfg.FailoverWsUrl} {
+		if url != "" {
+			ws++
+		}
+	}
+	endpoints := append([]*endpoint{c.m, c.b}, c.ws...)
+	for _, e := range c.ws {
+		if urls[e.name] == "" {
+			ws = -1
+		}
+	}
+	if ws != len(c.ws) {
+		return fmt.Errorf("websocket endpoints can't be added or removed at runtime")
+	}
+
+	type rotation struct {
+		e   *endpoint
+		url string
+		rc  *rpc.Client
+	}
+	var rotations []rotation
+	for _, e := range endpoints {
+		url := urls[e.name]
+		if url == e.currentURL() {
+			continue
+		}
+		rc, err := c.dialVerified(ctx, e, url)
+		if err != nil {
+			for _, r := range rotations {
+				r.e.pool.release(r.rc)
+			}
+			return err
+		}
+		rotations = append(rotations, rotation{e, url, rc})
+	}
+	for _, r := range rotations {
+		c.rotate(r.e, r.url, r.rc)
+	}
+	promoted := c.m.name == cfg.FailoverRpcName
+	if c.promoted.Swap(promoted) != promoted {
+		c.observePreference()
+		c.logger.Info().Msgf("rpc %s is now the primary", cfg.RpcName)
+	}
+	return nil
+}
+
+// dialVerified dials url for e and checks the connection with
+// checkRotation.
+func (c *client) dialVerified(ctx context.Context, e *endpoint, url string) (*rpc.Client, error) {
+	rc, err := e.pool.dial(ctx, url, e.dialOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial new url of rpc %s: %w", e.name, err)
+	}
+	if err := c.checkRotation(ctx, rc); err != nil {
+		e.pool.release(rc)
+		return nil, fmt.Errorf("new url of rpc %s failed verification: %w", e.name, err)
+	}
+	return rc, nil
+}
+
+// rotate swaps rc, connected to url, in for the connection of e.
+func (c *client) rotate(e *endpoint, url string, rc *rpc.Client) {
+	e.rotate(url, rc)
+	e.chainMismatch.Store(false)
+	c.logger.Info().Msgf("rotated url of rpc %s", e.name)
+}
+
+// checkRotation makes sure a connection is healthy and serves the chain of
+// the client before it is swapped in.
+func (c *client) checkRotation(ctx context.Context, rc *rpc.Client) error {
+	ec := ethclient.NewClient(rc)
+	id, err := ec.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+	if expected := c.chainID.Load(); expected != nil && id.Cmp(expected) != 0 {
+		return fmt.Errorf("%w: serves chain %s, expected %s", ErrChainIDMismatch, id, expected)
+	}
+	if _, err := ec.BlockNumber(ctx); err != nil {
+		return err
+	}
+	return nil
+}
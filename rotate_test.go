@@ -0,0 +1,134 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestRotateEndpointURL(t *testing.T) {
+	c, err := New("test", "eth", testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 1}),
+		newTestNode(t, 1),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.(*client).Close()
+	ctx := context.Background()
+
+	if err := c.RotateEndpointURL(ctx, "unknown", newTestNode(t, 1)); err == nil {
+		t.Fatal("rotating an unknown endpoint succeeded")
+	}
+	if err := c.RotateEndpointURL(ctx, "main", newTestNode(t, 5)); !errors.Is(err, ErrChainIDMismatch) {
+		t.Fatalf("rotating to another chain error = %v, want ErrChainIDMismatch", err)
+	}
+	if err := c.RotateEndpointURL(ctx, "main", "http://127.0.0.1:1"); err == nil {
+		t.Fatal("rotating to an unreachable url succeeded")
+	}
+	if n, err := c.(*client).BlockNumber(ctx); err != nil || n != 1 {
+		t.Fatalf("BlockNumber after failed rotations = %d, %v, want 1", n, err)
+	}
+
+	url := newTestNodeWith(t, &testEthService{chainID: 1, head: 2})
+	if err := c.RotateEndpointURL(ctx, "main", url); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.(*client).m.currentURL(); got != url {
+		t.Fatalf("url = %s, want %s", got, url)
+	}
+	if n, err := c.(*client).BlockNumber(ctx); err != nil || n != 2 {
+		t.Fatalf("BlockNumber after rotation = %d, %v, want 2", n, err)
+	}
+}
+
+func TestEndpointRotateKeepsInflightConnection(t *testing.T) {
+	oldURL, newURL := newTestNode(t, 1), newTestNode(t, 1)
+	rc, err := rpc.Dial(oldURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := newEndpoint("test", oldURL, rc, nil)
+	defer e.Close()
+
+	old, _, err := e.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := rpc.Dial(newURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.rotate(newURL, next)
+
+	// the call in flight on the old connection still works
+	if _, err := old.ChainID(context.Background()); err != nil {
+		t.Fatalf("in flight call failed after rotation: %v", err)
+	}
+	if len(e.retired) != 1 {
+		t.Fatalf("got %d retired connections, want 1", len(e.retired))
+	}
+	e.release(old)
+	if len(e.retired) != 0 {
+		t.Fatal("retired connection not closed once released")
+	}
+	if e.inflight != 0 {
+		t.Fatalf("inflight = %d, want 0", e.inflight)
+	}
+}
+
+func TestUpdateEndpoints(t *testing.T) {
+	mainURL, failoverURL := newTestNodeWith(t, &testEthService{chainID: 1, head: 1}), newTestNodeWith(t, &testEthService{chainID: 1, head: 2})
+	c, err := New("test", "eth", testConfig(mainURL, failoverURL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	if err := c.UpdateEndpoints(ctx, testConfig(mainURL, failoverURL)); err != nil {
+		t.Fatalf("UpdateEndpoints without changes = %v", err)
+	}
+	cfg := testConfig(mainURL, failoverURL)
+	cfg.FailoverRpcName = "other"
+	if err := c.UpdateEndpoints(ctx, cfg); err == nil {
+		t.Fatal("adding an endpoint succeeded")
+	}
+	cfg = testConfig(mainURL, failoverURL)
+	cfg.WsUrl = "ws://127.0.0.1:1"
+	if err := c.UpdateEndpoints(ctx, cfg); err == nil {
+		t.Fatal("adding a websocket endpoint succeeded")
+	}
+
+	// the failover becomes the primary, with a new url, the main url is
+	// unreachable: nothing changes
+	newURL := newTestNodeWith(t, &testEthService{chainID: 1, head: 3})
+	cfg = testConfig(newURL, "http://127.0.0.1:1")
+	cfg.RpcName, cfg.FailoverRpcName = "failover", "main"
+	if err := c.UpdateEndpoints(ctx, cfg); err == nil {
+		t.Fatal("updating to an unreachable url succeeded")
+	}
+	if got := c.(*client).b.currentURL(); got != failoverURL {
+		t.Fatalf("failover url = %s after a failed update, want %s", got, failoverURL)
+	}
+	if n, err := c.(*client).BlockNumber(ctx); err != nil || n != 1 {
+		t.Fatalf("BlockNumber after a failed update = %d, %v, want 1", n, err)
+	}
+
+	cfg = testConfig(newURL, mainURL)
+	cfg.RpcName, cfg.FailoverRpcName = "failover", "main"
+	if err := c.UpdateEndpoints(ctx, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.(*client).b.currentURL(); got != newURL {
+		t.Fatalf("failover url = %s, want %s", got, newURL)
+	}
+	if n, err := c.(*client).BlockNumber(ctx); err != nil || n != 3 {
+		t.Fatalf("BlockNumber after the update = %d, %v, want 3 from the new primary", n, err)
+	}
+	if s := c.Status(); s.Endpoints[0].Name != "failover" || !s.Endpoints[0].Preferred {
+		t.Fatalf("Status = %+v, want failover preferred", s)
+	}
+}
@@ -0,0 +1,142 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrShuttingDown is returned by calls started after Shutdown, unless they
+// are critical.
+var ErrShuttingDown = errors.New("client is shutting down")
+
+// criticalMethods keep being served during Shutdown: they poll the outcome
+// of transactions already broadcast.
+var criticalMethods = map[string]bool{
+	"TransactionReceipt": true,
+	"TransactionByHash":  true,
+}
+
+type criticalKey struct{}
+
+// Critical marks the calls made with the returned context as critical: they
+// are still served while the client shuts down, e.g. to learn the outcome
+// of a transaction sent just before.
+func Critical(ctx context.Context) context.Context {
+	return context.WithValue(ctx, criticalKey{}, true)
+}
+
+// critical reports whether the call to method in ctx is served during
+// Shutdown.
+func (c *client) critical(ctx context.Context, method string) bool {
+	if critical, _ := ctx.Value(criticalKey{}).(bool); critical {
+		return true
+	}
+	if criticalMethods[method] {
+		return true
+	}
+	for _, m := range c.cfg.CriticalMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// inflightCalls counts the calls (or subscriptions) in flight, for Shutdown
+// to wait for them.
+type inflightCalls struct {
+	mu   sync.Mutex
+	n    int
+	idle chan struct{} // closed once n drops to 0, nil if nobody waits
+}
+
+func (f *inflightCalls) add() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.n++
+}
+
+func (f *inflightCalls) done() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.n--
+	if f.n == 0 && f.idle != nil {
+		close(f.idle)
+		f.idle = nil
+	}
+}
+
+// wait blocks until no call is in flight or ctx is done.
+func (f *inflightCalls) wait(ctx context.Context) error {
+	f.mu.Lock()
+	if f.n == 0 {
+		f.mu.Unlock()
+		return nil
+	}
+	if f.idle == nil {
+		f.idle = make(chan struct{})
+	}
+	idle := f.idle
+	f.mu.Unlock()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// enter registers a call to method in flight, to be ended by calling
+// leave. Once the client is shutting down, only critical calls enter.
+func (c *client) enter(ctx context.Context, method string) (leave func(), err error) {
+	if c.shuttingDown.Load() && !c.critical(ctx, method) {
+		return nil, ErrShuttingDown
+	}
+	c.inflight.add()
+	return c.inflight.done, nil
+}
+
+// Shutdown stops accepting new calls but critical ones (receipt polls and
+// calls made with a Critical context), waits for the calls in flight,
+// including transactions waited for by SendTransactionAndWait, and for the
+// subscriptions to be unsubscribed by their owners. It then stops the
+// background loops (head watcher, verification, ...), waits for them and
+// closes the connections. If ctx is done first, the remaining steps are
+// taken without waiting and the error of ctx returned.
+func (c *client) Shutdown(ctx context.Context) error {
+	c.shuttingDown.Store(true)
+	c.logger.Info().Msg("shutting down rpc client, waiting for calls in flight and subscriptions")
+	err := c.inflight.wait(ctx)
+	if err == nil {
+		err = c.subscriptions.wait(ctx)
+	}
+	if err != nil {
+		c.logger.Warn().Msgf("closing rpc client with calls or subscriptions in flight: %s", err)
+	}
+	c.stop()
+	if werr := waitGroup(ctx, &c.wg); werr != nil {
+		// loops stuck in a call exit once its connection is closed
+		c.logger.Warn().Msgf("closing rpc connections with background loops running: %s", werr)
+		if err == nil {
+			err = werr
+		}
+	}
+	c.closeConnections()
+	return err
+}
+
+// waitGroup waits for wg until ctx is done.
+func waitGroup(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,204 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testPendingTx keeps the transactions sent pending until mined is set.
+type testPendingTx struct {
+	testMempool
+	mined atomic.Bool
+}
+
+func (p *testPendingTx) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	if !p.mined.Load() {
+		return nil
+	}
+	return p.testMempool.GetTransactionReceipt(hash)
+}
+
+// shutdownStarted waits for Shutdown to reject new calls.
+func shutdownStarted(t *testing.T, c *client) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !c.shuttingDown.Load(); {
+		if time.Now().After(deadline) {
+			t.Fatal("Shutdown didn't start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestShutdownWaitsForSentTransactions(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := common.Address{1}
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.LegacyTx{To: &to, Gas: 21000, GasPrice: big.NewInt(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := &testPendingTx{}
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 5}, pool),
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 5}),
+	)
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	type result struct {
+		receipt *types.Receipt
+		err     error
+	}
+	waited := make(chan result, 1)
+	go func() {
+		r, err := c.(*client).SendTransactionAndWait(context.Background(), tx, &WaitOpts{PollInterval: 10 * time.Millisecond})
+		waited <- result{r, err}
+	}()
+	for deadline := time.Now().Add(time.Second); pool.GetTransactionByHash(tx.Hash()) == nil; {
+		if time.Now().After(deadline) {
+			t.Fatal("transaction not sent")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- c.Shutdown(context.Background())
+	}()
+	shutdownStarted(t, c.(*client))
+	if _, err := c.BalanceAt(context.Background(), common.Address{}, nil); !errors.Is(err, ErrShuttingDown) {
+		t.Fatalf("BalanceAt = %v during Shutdown, want ErrShuttingDown", err)
+	}
+	if _, err := c.(*client).SendTransactionAndWait(context.Background(), tx, nil); !errors.Is(err, ErrShuttingDown) {
+		t.Fatalf("SendTransactionAndWait = %v during Shutdown, want ErrShuttingDown", err)
+	}
+	if _, err := c.(*client).BlockNumber(Critical(context.Background())); err != nil {
+		t.Fatalf("critical BlockNumber = %v during Shutdown", err)
+	}
+	if got, pending, err := c.TransactionByHash(context.Background(), tx.Hash()); err != nil || got.Hash() != tx.Hash() || !pending {
+		t.Fatalf("TransactionByHash = %v, %v, %v during Shutdown, want the pending transaction", got, pending, err)
+	}
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown = %v before the transaction was mined", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	pool.mined.Store(true)
+	if r := <-waited; r.err != nil || r.receipt.TxHash != tx.Hash() {
+		t.Fatalf("SendTransactionAndWait = %v, %v, want the receipt", r.receipt, r.err)
+	}
+	if err := <-shutdown; err != nil {
+		t.Fatalf("Shutdown = %v", err)
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1, delay: 500 * time.Millisecond}),
+		newTestNodeWith(t, &testEthService{chainID: 1}),
+	)
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	go c.(*client).BlockNumber(context.Background())
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		c.(*client).inflight.mu.Lock()
+		n := c.(*client).inflight.n
+		c.(*client).inflight.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("BlockNumber not in flight")
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown = %v, want the deadline of its context", err)
+	}
+}
+
+func TestShutdownWaitsForSubscriptions(t *testing.T) {
+	mainURL, _, _ := newTestHeadFeedNode(t)
+	failoverURL, _, _ := newTestHeadFeedNode(t)
+	cfg := testConfig(newTestNode(t, 1), newTestNode(t, 1))
+	cfg.WsUrl, cfg.FailoverWsUrl = mainURL, failoverURL
+	cfg.HeadPollIntervalMs = 10
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	sub, err := c.SubscribeNewHead(context.Background(), make(chan *types.Header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- c.Shutdown(context.Background())
+	}()
+	shutdownStarted(t, c.(*client))
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown = %v with a subscription active", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	select {
+	case <-c.(*client).done:
+		t.Fatal("background loops stopped with a subscription active")
+	default:
+	}
+
+	sub.Unsubscribe()
+	if err := <-shutdown; err != nil {
+		t.Fatalf("Shutdown = %v", err)
+	}
+	if connOf(c.(*client).m) != nil {
+		t.Fatal("connections of main left open")
+	}
+}
+
+func TestShutdownDeadlineEndsSubscriptions(t *testing.T) {
+	mainURL, _, _ := newTestHeadFeedNode(t)
+	cfg := testConfig(newTestNode(t, 1), newTestNode(t, 1))
+	cfg.WsUrl = mainURL
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	sub, err := c.SubscribeNewHead(context.Background(), make(chan *types.Header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown = %v, want the deadline of its context", err)
+	}
+	select {
+	case <-sub.Err():
+	case <-time.After(time.Second):
+		t.Fatal("subscription still active after Shutdown")
+	}
+}
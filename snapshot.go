@@ -0,0 +1,103 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// StateSnapshot is the state of accounts at a block, returned by Snapshot.
+type StateSnapshot struct {
+	Block    uint64
+	Accounts map[common.Address]*AccountState
+}
+
+// AccountState is the state of an account in a StateSnapshot. Storage only
+// has the slots asked for.
+type AccountState struct {
+	Balance *big.Int
+	Nonce   uint64
+	Code    []byte
+	Storage map[common.Hash]common.Hash
+}
+
+// Snapshot returns the code, balance, nonce and the given storage slots of
+// accounts, and of the accounts of slots, at block, the latest one when
+// nil, fetched with BatchCall. Tags are resolved to a block number first so
+// that every read is of the same block. Unlike the other batch helpers, it
+// fails if any read failed, a partial state being of no use to simulate.
+func (c *client) Snapshot(ctx context.Context, accounts []common.Address, slots map[common.Address][]common.Hash, block *big.Int) (*StateSnapshot, error) {
+	if block == nil || block.Sign() < 0 {
+		h, err := c.HeaderByNumber(ctx, block)
+		if err != nil {
+			return nil, fmt.Errorf("resolving block %s: %w", blockNumberArg(block), err)
+		}
+		block = h.Number
+	}
+	s := &StateSnapshot{Block: block.Uint64(), Accounts: make(map[common.Address]*AccountState)}
+	type accountResults struct {
+		balance hexutil.Big
+		nonce   hexutil.Uint64
+		code    hexutil.Bytes
+		storage []common.Hash
+	}
+	results := make(map[common.Address]*accountResults)
+	order := make([]common.Address, 0, len(accounts)+len(slots))
+	for _, account := range accounts {
+		if results[account] == nil {
+			results[account] = &accountResults{}
+			order = append(order, account)
+		}
+	}
+	for account := range slots {
+		if results[account] == nil {
+			results[account] = &accountResults{}
+			order = append(order, account)
+		}
+	}
+	number := hexutil.EncodeBig(block)
+	var elems []rpc.BatchElem
+	for _, account := range order {
+		r := results[account]
+		r.storage = make([]common.Hash, len(slots[account]))
+		elems = append(elems,
+			rpc.BatchElem{Method: "eth_getBalance", Args: []interface{}{account, number}, Result: &r.balance},
+			rpc.BatchElem{Method: "eth_getTransactionCount", Args: []interface{}{account, number}, Result: &r.nonce},
+			rpc.BatchElem{Method: "eth_getCode", Args: []interface{}{account, number}, Result: &r.code},
+		)
+		for i, slot := range slots[account] {
+			elems = append(elems, rpc.BatchElem{Method: "eth_getStorageAt", Args: []interface{}{account, slot, number}, Result: &r.storage[i]})
+		}
+	}
+	if err := c.BatchCall(c.withStateBlock(ctx, block), elems); err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, elem := range elems {
+		if elem.Error != nil {
+			errs = append(errs, fmt.Errorf("%s of %s: %w", elem.Method, elem.Args[0], elem.Error))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	for _, account := range order {
+		r := results[account]
+		state := &AccountState{
+			Balance: r.balance.ToInt(),
+			Nonce:   uint64(r.nonce),
+			Code:    r.code,
+			Storage: make(map[common.Hash]common.Hash, len(r.storage)),
+		}
+		for i, slot := range slots[account] {
+			state.Storage[slot] = r.storage[i]
+		}
+		s.Accounts[account] = state
+	}
+	return s, nil
+}
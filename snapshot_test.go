@@ -0,0 +1,69 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// testState serves the nonce, code and storage of accounts, the block read
+// being recorded.
+type testState struct {
+	blocks chan string
+}
+
+func (s *testState) GetTransactionCount(account common.Address, block string) hexutil.Uint64 {
+	s.blocks <- block
+	return hexutil.Uint64(account[0])
+}
+
+func (s *testState) GetCode(account common.Address, _ string) hexutil.Bytes {
+	return hexutil.Bytes{account[0]}
+}
+
+func (s *testState) GetStorageAt(_ common.Address, slot common.Hash, _ string) (common.Hash, error) {
+	if slot == (common.Hash{0xff}) {
+		return common.Hash{}, errors.New("internal error")
+	}
+	return common.Hash{slot[0] + 1}, nil
+}
+
+func TestSnapshot(t *testing.T) {
+	state := &testState{blocks: make(chan string, 10)}
+	c, err := New("test", "eth", testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1, balance: 5}, state),
+		newTestNode(t, 1),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	a, b := common.Address{1}, common.Address{2}
+	s, err := c.Snapshot(context.Background(), []common.Address{a, a}, map[common.Address][]common.Hash{b: {{7}}}, big.NewInt(9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Block != 9 || len(s.Accounts) != 2 {
+		t.Fatalf("Snapshot = %+v, want both accounts at block 9", s)
+	}
+	if got := s.Accounts[a]; got.Balance.Int64() != 5 || got.Nonce != 1 || len(got.Code) != 1 || got.Code[0] != 1 || len(got.Storage) != 0 {
+		t.Fatalf("state of %s = %+v", a, got)
+	}
+	if got := s.Accounts[b].Storage[common.Hash{7}]; got != (common.Hash{8}) {
+		t.Fatalf("slot of %s = %s, want 0x08...", b, got)
+	}
+	for i := 0; i < 2; i++ {
+		if block := <-state.blocks; block != "0x9" {
+			t.Fatalf("read block %s, want 0x9", block)
+		}
+	}
+
+	if _, err := c.Snapshot(context.Background(), nil, map[common.Address][]common.Hash{b: {{0xff}}}, big.NewInt(9)); err == nil {
+		t.Fatal("Snapshot didn't fail on a failed read")
+	}
+}
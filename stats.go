@@ -0,0 +1,162 @@
+package ethclient
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// statsMaxSamples bounds the latency samples kept per method and endpoint
+// within an interval, quantiles are estimated from a uniform sample beyond.
+const statsMaxSamples = 1024
+
+// StatsRecord holds the request statistics of a method against an endpoint
+// over one interval. It is flat so it loads as is into a table.
+type StatsRecord struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Method   string    `json:"method"`
+	Endpoint string    `json:"endpoint"`
+	Count    int       `json:"count"`
+	Errors   int       `json:"errors"`
+	P50Ms    float64   `json:"p50_ms"`
+	P90Ms    float64   `json:"p90_ms"`
+	P99Ms    float64   `json:"p99_ms"`
+	MaxMs    float64   `json:"max_ms"`
+}
+
+type statsKey struct {
+	method, endpoint string
+}
+
+type statsEntry struct {
+	count, errors int
+	samples       []float64 // latencies in milliseconds
+	max           float64
+}
+
+// requestStats aggregates requests between two snapshots.
+type requestStats struct {
+	mu      sync.Mutex
+	start   time.Time
+	entries map[statsKey]*statsEntry
+}
+
+func newRequestStats() *requestStats {
+	return &requestStats{start: time.Now(), entries: make(map[statsKey]*statsEntry)}
+}
+
+func (s *requestStats) observe(method, endpoint string, took time.Duration, err error) {
+	if s == nil {
+		return
+	}
+	ms := float64(took) / float64(time.Millisecond)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := statsKey{method, endpoint}
+	e := s.entries[k]
+	if e == nil {
+		e = &statsEntry{}
+		s.entries[k] = e
+	}
+	e.count++
+	if err != nil {
+		e.errors++
+	}
+	if ms > e.max {
+		e.max = ms
+	}
+	if len(e.samples) < statsMaxSamples {
+		e.samples = append(e.samples, ms)
+	} else if i := rand.Intn(e.count); i < statsMaxSamples {
+		// reservoir sampling
+		e.samples[i] = ms
+	}
+}
+
+// snapshot returns the records since the previous snapshot and resets the
+// statistics.
+func (s *requestStats) snapshot(now time.Time) []StatsRecord {
+	s.mu.Lock()
+	entries, start := s.entries, s.start
+	s.entries, s.start = make(map[statsKey]*statsEntry), now
+	s.mu.Unlock()
+
+	records := make([]StatsRecord, 0, len(entries))
+	for k, e := range entries {
+		sort.Float64s(e.samples)
+		records = append(records, StatsRecord{
+			Start:    start,
+			End:      now,
+			Method:   k.method,
+			Endpoint: k.endpoint,
+			Count:    e.count,
+			Errors:   e.errors,
+			P50Ms:    quantile(e.samples, 0.5),
+			P90Ms:    quantile(e.samples, 0.9),
+			P99Ms:    quantile(e.samples, 0.99),
+			MaxMs:    e.max,
+		})
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Method != records[j].Method {
+			return records[i].Method < records[j].Method
+		}
+		return records[i].Endpoint < records[j].Endpoint
+	})
+	return records
+}
+
+// quantile returns the q quantile of sorted using the nearest rank.
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(q*float64(len(sorted))+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
+
+// exportStats delivers a snapshot of the request statistics every interval
+// to Config.StatsHandler and, as JSON lines, to Config.StatsWriter. A last
+// snapshot is delivered on Close.
+func (c *client) exportStats(interval time.Duration) {
+	c.goBackground(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.done:
+				c.deliverStats(c.stats.snapshot(time.Now()))
+				return
+			case now := <-ticker.C:
+				c.deliverStats(c.stats.snapshot(now))
+			}
+		}
+	})
+}
+
+func (c *client) deliverStats(records []StatsRecord) {
+	if len(records) == 0 {
+		return
+	}
+	if c.cfg.StatsHandler != nil {
+		c.cfg.StatsHandler(records)
+	}
+	if c.cfg.StatsWriter != nil {
+		enc := json.NewEncoder(c.cfg.StatsWriter)
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				c.logger.Warn().Msgf("failed to write rpc stats: %s", err)
+				return
+			}
+		}
+	}
+}
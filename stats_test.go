@@ -0,0 +1,90 @@
+package ethclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQuantile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	for _, tt := range []struct {
+		q    float64
+		want float64
+	}{
+		{0, 1},
+		{0.5, 5},
+		{0.9, 9},
+		{0.99, 10},
+		{1, 10},
+	} {
+		if got := quantile(sorted, tt.q); got != tt.want {
+			t.Errorf("quantile(%v) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+	if got := quantile(nil, 0.5); got != 0 {
+		t.Errorf("quantile of no samples = %v, want 0", got)
+	}
+}
+
+func TestRequestStatsSnapshot(t *testing.T) {
+	s := newRequestStats()
+	for i := 1; i <= 100; i++ {
+		s.observe("BlockNumber", "main", time.Duration(i)*time.Millisecond, nil)
+	}
+	s.observe("BlockNumber", "backup", time.Millisecond, errors.New("down"))
+	s.observe("ChainID", "main", time.Millisecond, nil)
+
+	records := s.snapshot(time.Now())
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+	r := records[1]
+	if r.Method != "BlockNumber" || r.Endpoint != "main" || r.Count != 100 || r.Errors != 0 || r.P50Ms != 50 || r.P99Ms != 99 || r.MaxMs != 100 {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if records[0].Endpoint != "backup" || records[0].Errors != 1 {
+		t.Errorf("unexpected record %+v", records[0])
+	}
+	if again := s.snapshot(time.Now()); len(again) != 0 {
+		t.Errorf("snapshot not reset, got %d records", len(again))
+	}
+}
+
+func TestRequestStatsBoundsSamples(t *testing.T) {
+	s := newRequestStats()
+	for i := 0; i < 10*statsMaxSamples; i++ {
+		s.observe("BlockNumber", "main", time.Millisecond, nil)
+	}
+	if n := len(s.entries[statsKey{"BlockNumber", "main"}].samples); n != statsMaxSamples {
+		t.Fatalf("kept %d samples, want %d", n, statsMaxSamples)
+	}
+}
+
+func TestExportStatsOnClose(t *testing.T) {
+	var buf bytes.Buffer
+	var handled []StatsRecord
+	cfg := testConfig(newTestNode(t, 1), newTestNode(t, 1))
+	cfg.StatsIntervalSec = 3600
+	cfg.StatsWriter = &buf
+	cfg.StatsHandler = func(records []StatsRecord) { handled = append(handled, records...) }
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.(*client).ChainID(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	c.(*client).Close()
+
+	if len(handled) != 1 || handled[0].Method != "ChainID" || handled[0].Count != 1 {
+		t.Fatalf("unexpected records %+v", handled)
+	}
+	var r StatsRecord
+	if err := json.Unmarshal(buf.Bytes(), &r); err != nil || r.Method != "ChainID" {
+		t.Fatalf("unexpected JSON %q: %v", buf.String(), err)
+	}
+}
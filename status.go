@@ -0,0 +1,137 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Health of an endpoint in Status.
+const (
+	HealthHealthy     = "healthy"
+	HealthDegraded    = "degraded" // its last call failed
+	HealthUnavailable = "unavailable"
+)
+
+// outcome is the last outcome of the calls to an endpoint.
+type outcome struct {
+	mu        sync.Mutex
+	lastErr   error
+	lastErrAt time.Time
+	lastOKAt  time.Time
+}
+
+// observe records the outcome of a call made in ctx, calls the caller gave
+// up on don't tell anything about the endpoint.
+func (o *outcome) observe(ctx context.Context, err error, ok bool) {
+	if !ok && ctx.Err() != nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if ok {
+		o.lastOKAt = time.Now()
+		return
+	}
+	o.lastErr, o.lastErrAt = err, time.Now()
+}
+
+func (o *outcome) last() (err error, errAt, okAt time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.lastErr, o.lastErrAt, o.lastOKAt
+}
+
+// EndpointStatus is the routing state and health of an endpoint.
+type EndpointStatus struct {
+	Name string `json:"name"`
+	// first in routing order
+	Preferred bool `json:"preferred"`
+	// not drained, sidelined, excluded or failing to dial
+	Available bool   `json:"available"`
+	Health    string `json:"health"`
+	// moving averages of the calls to the endpoint
+	LatencyMs float64 `json:"latency_ms"`
+	ErrorRate float64 `json:"error_rate"`
+	Samples   int     `json:"samples"`
+	// last polled block height, 0 without the head watcher
+	Head uint64 `json:"head"`
+	// last polled finalized block height, 0 without CheckFinality
+	Finalized uint64 `json:"finalized,omitempty"`
+	// last failed call, if any
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
+
+// Status is the routing state of the client.
+type Status struct {
+	Endpoints []EndpointStatus `json:"endpoints"`
+	// approximate head, see ApproxHead
+	Head uint64 `json:"head"`
+	// calls that failed over to another endpoint, in total and in flight
+	Failovers       uint64 `json:"failovers"`
+	ActiveFailovers int64  `json:"active_failovers"`
+}
+
+// Status returns the routing state and health of the endpoints, the
+// preferred one first, followed by the websocket endpoints.
+func (c *client) Status() Status {
+	s := Status{
+		Head:            c.ApproxHead(),
+		Failovers:       c.failovers.Load(),
+		ActiveFailovers: c.activeFailovers.Load(),
+	}
+	for _, endpoints := range [][]*endpoint{c.endpoints(), c.ws} {
+		for i, e := range endpoints {
+			s.Endpoints = append(s.Endpoints, e.status(i == 0))
+		}
+	}
+	return s
+}
+
+func (e *endpoint) status(preferred bool) EndpointStatus {
+	latencyMs, errorRate, samples := e.quality.averages()
+	s := EndpointStatus{
+		Name:      e.name,
+		Preferred: preferred,
+		Available: !e.unavailable(),
+		Health:    e.health(),
+		LatencyMs: latencyMs,
+		ErrorRate: errorRate,
+		Samples:   samples,
+		Head:      e.head.Load(),
+	}
+	if h := e.finalized.Load(); h != nil {
+		s.Finalized = h.Number.Uint64()
+	}
+	err, errAt, _ := e.outcome.last()
+	if err != nil {
+		s.LastError, s.LastErrorAt = err.Error(), &errAt
+	}
+	return s
+}
+
+// health returns the Health of the endpoint.
+func (e *endpoint) health() string {
+	_, errAt, okAt := e.outcome.last()
+	switch {
+	case e.unavailable():
+		return HealthUnavailable
+	case errAt.After(okAt):
+		return HealthDegraded
+	}
+	return HealthHealthy
+}
+
+// StatusHandler serves the Status of c as JSON, e.g. mounted on
+// /ethclient/status of an admin server.
+func StatusHandler(c Client) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(c.Status()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
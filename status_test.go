@@ -0,0 +1,55 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStatus(t *testing.T) {
+	var down, failoverDown atomic.Bool
+	cfg := testConfig(
+		newOutageNode(t, &testEthService{chainID: 1, head: 8}, &down),
+		newOutageNode(t, &testEthService{chainID: 1, head: 9}, &failoverDown),
+	)
+	cfg.HeadPollIntervalMs = 20
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	for deadline := time.Now().Add(time.Second); c.Status().Endpoints[0].Head == 0; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("heads not polled")
+		}
+	}
+
+	down.Store(true)
+	if n, err := c.(*client).BlockNumber(context.Background()); err != nil || n != 9 {
+		t.Fatalf("BlockNumber = %d, %v, want 9 from the failover", n, err)
+	}
+	s := c.Status()
+	if s.Failovers != 1 || s.ActiveFailovers != 0 || s.Head < 9 {
+		t.Fatalf("Status = %+v, want a failover past head 9", s)
+	}
+	main, failover := s.Endpoints[0], s.Endpoints[1]
+	if main.Name != "main" || main.Health != HealthDegraded || main.LastError == "" || main.LastErrorAt == nil || main.Head != 8 {
+		t.Fatalf("main status = %+v, want degraded with its last error", main)
+	}
+	if failover.Health != HealthHealthy || failover.LastError != "" || failover.Head != 9 {
+		t.Fatalf("failover status = %+v, want healthy", failover)
+	}
+
+	rec := httptest.NewRecorder()
+	StatusHandler(c).ServeHTTP(rec, httptest.NewRequest("GET", "/ethclient/status", nil))
+	var served Status
+	if err := json.Unmarshal(rec.Body.Bytes(), &served); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Header().Get("Content-Type") != "application/json" || len(served.Endpoints) != 2 || served.Endpoints[0].Health != HealthDegraded {
+		t.Fatalf("served status = %s", rec.Body)
+	}
+}
@@ -0,0 +1,85 @@
+package ethclient
+
+import (
+	"sync"
+	"time"
+)
+
+// windowMinutes is the span of a window.
+const windowMinutes = 60
+
+// window counts events over the last hour, in one minute buckets.
+type window struct {
+	mu      sync.Mutex
+	buckets [windowMinutes]struct {
+		minute int64
+		n      uint64
+	}
+}
+
+func (w *window) add(now time.Time) {
+	minute := now.Unix() / 60
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	b := &w.buckets[minute%windowMinutes]
+	if b.minute != minute {
+		b.minute, b.n = minute, 0
+	}
+	b.n++
+}
+
+// sum returns the events of the last hour.
+func (w *window) sum(now time.Time) uint64 {
+	minute := now.Unix() / 60
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var n uint64
+	for _, b := range w.buckets {
+		if minute-b.minute < windowMinutes {
+			n += b.n
+		}
+	}
+	return n
+}
+
+// StatusSnapshot sums up the state of the client in a few figures, e.g. to
+// embed in the /status JSON of a service, without Prometheus.
+type StatusSnapshot struct {
+	// endpoints, websocket ones included, and those of them healthy
+	Endpoints        int `json:"endpoints"`
+	HealthyEndpoints int `json:"healthy_endpoints"`
+	// endpoint tried first by calls
+	Preferred string `json:"preferred"`
+	// approximate head, see ApproxHead
+	Head uint64 `json:"head"`
+	// calls that failed over to another endpoint in the last hour
+	FailoversLastHour uint64 `json:"failovers_last_hour"`
+	// share of the attempts of the last hour refused by a rate limit, from
+	// Config or an HTTP 429 of the provider, from 0 to 1
+	RateLimitPressure float64 `json:"rate_limit_pressure"`
+	// endpoints currently skipped after answering HTTP 429
+	RateLimitedEndpoints int `json:"rate_limited_endpoints"`
+}
+
+// StatusSnapshot returns a summary of Status and of the last hour of calls.
+func (c *client) StatusSnapshot() StatusSnapshot {
+	now := time.Now()
+	s := StatusSnapshot{
+		Preferred:         c.endpoints()[0].name,
+		Head:              c.ApproxHead(),
+		FailoversLastHour: c.failoverWindow.sum(now),
+	}
+	if attempts := c.attemptWindow.sum(now); attempts > 0 {
+		s.RateLimitPressure = float64(c.rateLimitWindow.sum(now)) / float64(attempts)
+	}
+	for _, e := range c.allEndpoints() {
+		s.Endpoints++
+		if e.health() == HealthHealthy {
+			s.HealthyEndpoints++
+		}
+		if e.penalty.remaining(now) > 0 {
+			s.RateLimitedEndpoints++
+		}
+	}
+	return s
+}
@@ -0,0 +1,62 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestWindow(t *testing.T) {
+	var w window
+	now := time.Unix(1_700_000_000, 0)
+	w.add(now.Add(-90 * time.Minute))
+	w.add(now.Add(-30 * time.Minute))
+	w.add(now)
+	w.add(now)
+	if n := w.sum(now); n != 3 {
+		t.Fatalf("sum = %d, want the 3 events of the last hour", n)
+	}
+	// the bucket of an hour ago is reused
+	w.add(now.Add(30 * time.Minute))
+	if n := w.sum(now.Add(30 * time.Minute)); n != 3 {
+		t.Fatalf("sum half an hour later = %d, want 3", n)
+	}
+	if n := w.sum(now.Add(2 * time.Hour)); n != 0 {
+		t.Fatalf("sum two hours later = %d, want 0", n)
+	}
+}
+
+func TestStatusSnapshot(t *testing.T) {
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 1}),
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 2}),
+	)
+	cfg.VerifyChainID = false
+	cfg.RpcRateLimit, cfg.RpcRateBurst = 0.1, 1
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.(*client).BlockNumber(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := c.StatusSnapshot()
+	if s.Endpoints != 2 || s.HealthyEndpoints != 2 || s.Preferred != "main" {
+		t.Fatalf("StatusSnapshot = %+v, want 2 healthy endpoints", s)
+	}
+	if s.FailoversLastHour != 1 {
+		t.Fatalf("failovers = %d, want the rate limited call", s.FailoversLastHour)
+	}
+	// the primary refused one of the three attempts
+	if s.RateLimitPressure < 0.3 || s.RateLimitPressure > 0.34 {
+		t.Fatalf("rate limit pressure = %v, want 1/3", s.RateLimitPressure)
+	}
+	if _, err := json.Marshal(s); err != nil {
+		t.Fatal(err)
+	}
+}
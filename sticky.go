@@ -0,0 +1,56 @@
+package ethclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// recordOutcome tracks consecutive failures of endpoints for sticky
+// failover: failed endpoints were tried before succeeded served the call.
+// Only failures another endpoint recovered from count, so errors that any
+// endpoint would return (e.g. reverts) don't sideline the primary.
+func (c *client) recordOutcome(failed []*endpoint, succeeded *endpoint, gated bool) {
+	succeeded.failures.Store(0)
+	if c.cfg.StickyFailoverThreshold <= 0 || gated {
+		return
+	}
+	for _, e := range failed {
+		if e.failures.Add(1) >= int64(c.cfg.StickyFailoverThreshold) && e.sidelined.CompareAndSwap(false, true) {
+			c.sideline(e)
+		}
+	}
+}
+
+// sideline keeps e out of the routing, but as a last resort, for the
+// cooldown. Afterwards e is probed and only restored once healthy.
+func (c *client) sideline(e *endpoint) {
+	cooldown := time.Duration(c.cfg.StickyFailoverCooldownSec) * time.Second
+	c.logger.Warn().Msgf("rpc %s failed %d calls in a row, sidelining it for %s", e.name, e.failures.Load(), cooldown)
+	c.goBackground(func() {
+		timer := time.NewTimer(cooldown)
+		defer timer.Stop()
+		for {
+			select {
+			case <-c.done:
+				return
+			case <-timer.C:
+				ctx, cancel := context.WithTimeout(context.Background(), verifyTimeout)
+				err := e.call(ctx, func(ec *ethclient.Client) error {
+					_, err := ec.BlockNumber(ctx)
+					return err
+				})
+				cancel()
+				if err == nil {
+					e.failures.Store(0)
+					e.sidelined.Store(false)
+					c.logger.Info().Msgf("rpc %s is healthy again, restoring it", e.name)
+					return
+				}
+				c.logger.Warn().Msgf("rpc %s is still failing, sidelining it for another %s: %s", e.name, cooldown, err)
+				timer.Reset(cooldown)
+			}
+		}
+	})
+}
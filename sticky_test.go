@@ -0,0 +1,48 @@
+package ethclient
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStickyFailover(t *testing.T) {
+	cfg := testConfig("http://127.0.0.1:1", newTestNodeWith(t, &testEthService{chainID: 1, head: 5}))
+	cfg.VerifyChainID = false
+	cfg.StickyFailoverThreshold = 3
+	cfg.StickyFailoverCooldownSec = 1
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl := c.(*client)
+	defer cl.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if routes, _ := cl.route("BlockNumber"); routes[0] != cl.m {
+			t.Fatalf("primary sidelined after %d failures", i)
+		}
+		if _, err := cl.BlockNumber(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if routes, _ := cl.route("BlockNumber"); routes[0] != cl.b {
+		t.Fatal("primary not sidelined after 3 failures")
+	}
+
+	// the primary is restored once the cooldown expired and it is healthy
+	if err := cl.RotateEndpointURL(ctx, "main", newTestNodeWith(t, &testEthService{chainID: 1, head: 5})); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for cl.m.sidelined.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("primary not restored")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if routes, _ := cl.route("BlockNumber"); routes[0] != cl.m {
+		t.Fatal("restored primary not routed first")
+	}
+}
@@ -0,0 +1,43 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrFailoverRequiresOptIn is returned in strict mode when a call to a
+// strict method would have to fail over but the caller didn't opt in, see
+// AllowFailover. It wraps the error of the primary endpoint, if any.
+var ErrFailoverRequiresOptIn = errors.New("failover requires an explicit opt-in")
+
+type allowFailoverKey struct{}
+
+// AllowFailover opts the calls made with the returned context into failing
+// over in strict mode, e.g. to broadcast a transaction through the other
+// provider.
+func AllowFailover(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allowFailoverKey{}, true)
+}
+
+// failoverForbidden reports whether the call to method in ctx must stay on
+// the primary endpoint.
+func (c *client) failoverForbidden(ctx context.Context, method string) bool {
+	if !c.cfg.StrictFailover {
+		return false
+	}
+	if allowed, _ := ctx.Value(allowFailoverKey{}).(bool); allowed {
+		return false
+	}
+	if _, steered := ctx.Value(endpointHintKey{}).(string); steered {
+		return false
+	}
+	if method == "SendTransaction" {
+		return true
+	}
+	for _, m := range c.cfg.StrictFailoverMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestStrictFailover(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), Gas: 21000}), types.LatestSignerForChainID(big.NewInt(1)), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backup := &testTxPool{}
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1}, &testTxPool{reject: true}),
+		newTestNodeWith(t, &testEthService{chainID: 1}, backup),
+	)
+	cfg.StrictFailover = true
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	err = c.SendTransaction(context.Background(), tx)
+	if !errors.Is(err, ErrFailoverRequiresOptIn) || backup.sent.Load() != 0 {
+		t.Fatalf("SendTransaction error = %v, sent to backup %d times, want ErrFailoverRequiresOptIn", err, backup.sent.Load())
+	}
+	if err := c.SendTransaction(AllowFailover(context.Background()), tx); err != nil || backup.sent.Load() != 1 {
+		t.Fatalf("opted in SendTransaction error = %v, sent to backup %d times", err, backup.sent.Load())
+	}
+
+	c.(*client).m.sidelined.Store(true)
+	if err := c.SendTransaction(context.Background(), tx); !errors.Is(err, ErrFailoverRequiresOptIn) {
+		t.Fatalf("SendTransaction with the primary sidelined error = %v, want ErrFailoverRequiresOptIn", err)
+	}
+}
+
+func TestFailoverForbidden(t *testing.T) {
+	c := &client{cfg: &Config{StrictFailoverMethods: []string{"EstimateGas"}}}
+	ctx := context.Background()
+	if c.failoverForbidden(ctx, "SendTransaction") {
+		t.Fatal("failover forbidden outside of strict mode")
+	}
+	c.cfg.StrictFailover = true
+	for method, want := range map[string]bool{"SendTransaction": true, "EstimateGas": true, "BlockNumber": false} {
+		if got := c.failoverForbidden(ctx, method); got != want {
+			t.Errorf("failoverForbidden(%s) = %v, want %v", method, got, want)
+		}
+		if c.failoverForbidden(AllowFailover(ctx), method) {
+			t.Errorf("failoverForbidden(%s) despite the opt-in", method)
+		}
+	}
+}
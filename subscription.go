@@ -0,0 +1,100 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+const (
+	defaultResubscribeAttempts = 5
+	defaultResubscribeDelay    = time.Second
+)
+
+// SubscribeNewHead subscribes to the new heads of an endpoint. When the
+// subscription fails, e.g. the endpoint goes down, it subscribes again,
+// possibly to the other endpoint, up to ResubscribeMaxAttempts times in a
+// row, so that Err only reports a failure once these are exhausted. Heads
+// mined while subscribing again aren't sent, see SubscribeCanonicalHeads
+// to get them.
+func (c *client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	subscribe := func(ctx context.Context) (ethereum.Subscription, error) {
+		return do(ctx, c, "SubscribeNewHead", func(ctx context.Context, ec *ethclient.Client) (ethereum.Subscription, error) {
+			sub, err := ec.SubscribeNewHead(ctx, ch)
+			if err != nil {
+				// a nil *rpc.ClientSubscription, not a nil interface
+				return nil, err
+			}
+			return c.trackSubscription(ctx, endpointFromContext(ctx), sub), nil
+		})
+	}
+	sub, err := subscribe(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// ctx only bounds the setup, see trackedSubscription
+	lctx := c.subscriptionContext(ctx)
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer func() { sub.Unsubscribe() }()
+		for {
+			select {
+			case <-quit:
+				return nil
+			case <-c.done:
+				return nil
+			case err, ok := <-sub.Err():
+				if !ok {
+					err = errors.New("subscription closed")
+				}
+				next, err := c.resubscribe(lctx, "SubscribeNewHead", err, quit, func() (ethereum.Subscription, error) {
+					return subscribe(lctx)
+				})
+				if next == nil {
+					return err
+				}
+				sub = next
+			}
+		}
+	}), nil
+}
+
+// resubscribe calls subscribe after a subscription to method failed with
+// err, until it succeeds or ResubscribeMaxAttempts attempts failed. It
+// returns a nil subscription along with the last error once exhausted, or
+// ctx's, and without error once quit or the client is done.
+func (c *client) resubscribe(ctx context.Context, method string, err error, quit <-chan struct{}, subscribe func() (ethereum.Subscription, error)) (ethereum.Subscription, error) {
+	attempts, delay := c.cfg.ResubscribeMaxAttempts, time.Duration(c.cfg.ResubscribeDelayMs)*time.Millisecond
+	if attempts == 0 {
+		attempts = defaultResubscribeAttempts
+	}
+	if delay == 0 {
+		delay = defaultResubscribeDelay
+	}
+	c.logger.Warn().Msgf("%s subscription failed, subscribing again: %s", method, err)
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(delay):
+			case <-quit:
+				return nil, nil
+			case <-c.done:
+				return nil, nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		sub, serr := subscribe()
+		if serr == nil {
+			return sub, nil
+		}
+		err = serr
+		c.logger.Debug().Msgf("failed to subscribe again to %s (attempt %d of %d): %s", method, attempt, attempts, err)
+	}
+	return nil, fmt.Errorf("%s subscription failed, %d attempts to subscribe again failed: %w", method, attempts, err)
+}
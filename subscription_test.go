@@ -0,0 +1,171 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newTestHeadFeedNode serves a head feed over websocket, its connections
+// are dropped by stopping the returned server.
+func newTestHeadFeedNode(t *testing.T) (string, *testHeadFeed, *rpc.Server) {
+	t.Helper()
+	feed := &testHeadFeed{heads: make(chan *types.Header), byHash: make(map[common.Hash]*types.Header)}
+	srv := rpc.NewServer()
+	for _, rcvr := range []interface{}{&testEthService{chainID: 1}, feed} {
+		if err := srv.RegisterName("eth", rcvr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hs := httptest.NewServer(srv.WebsocketHandler([]string{"*"}))
+	t.Cleanup(func() {
+		hs.Close()
+		srv.Stop()
+	})
+	return "ws" + strings.TrimPrefix(hs.URL, "http"), feed, srv
+}
+
+func TestSubscribeNewHeadResubscribes(t *testing.T) {
+	mainURL, _, mainSrv := newTestHeadFeedNode(t)
+	failoverURL, failoverFeed, failoverSrv := newTestHeadFeedNode(t)
+	cfg := testConfig(newTestNode(t, 1), newTestNode(t, 1))
+	cfg.WsUrl, cfg.FailoverWsUrl = mainURL, failoverURL
+	cfg.ResubscribeMaxAttempts, cfg.ResubscribeDelayMs = 2, 10
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ch := make(chan *types.Header)
+	sub, err := c.SubscribeNewHead(context.Background(), ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	// the main endpoint goes down, the subscription moves to the failover
+	mainSrv.Stop()
+	h := testBranch(nil, 1, 1, 0)[0]
+	select {
+	case failoverFeed.heads <- h:
+	case err := <-sub.Err():
+		t.Fatalf("subscription failed with the failover up: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("not subscribed to the failover")
+	}
+	select {
+	case got := <-ch:
+		if got.Hash() != h.Hash() {
+			t.Fatalf("got head %s, want %s", got.Hash(), h.Hash())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no head received")
+	}
+
+	// both are down: the error is reported once the attempts are exhausted
+	failoverSrv.Stop()
+	select {
+	case err := <-sub.Err():
+		if err == nil || !strings.Contains(err.Error(), "2 attempts") {
+			t.Fatalf("subscription error = %v, want the exhausted attempts", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription didn't fail")
+	}
+}
+
+func TestSubscribeNewHeadResubscribesAfterSetupContext(t *testing.T) {
+	mainURL, _, mainSrv := newTestHeadFeedNode(t)
+	failoverURL, failoverFeed, _ := newTestHeadFeedNode(t)
+	cfg := testConfig(newTestNode(t, 1), newTestNode(t, 1))
+	cfg.WsUrl, cfg.FailoverWsUrl = mainURL, failoverURL
+	cfg.ResubscribeMaxAttempts, cfg.ResubscribeDelayMs = 2, 10
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan *types.Header)
+	sub, err := c.SubscribeNewHead(ctx, ch)
+	cancel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	// the setup context is done, subscribing again isn't bound by it
+	mainSrv.Stop()
+	h := testBranch(nil, 1, 1, 0)[0]
+	select {
+	case failoverFeed.heads <- h:
+	case err := <-sub.Err():
+		t.Fatalf("subscription failed with the failover up: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("not subscribed to the failover")
+	}
+	select {
+	case got := <-ch:
+		if got.Hash() != h.Hash() {
+			t.Fatalf("got head %s, want %s", got.Hash(), h.Hash())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no head received")
+	}
+}
+
+func TestSubscriptionFollowsContextWithMethodTimeout(t *testing.T) {
+	mainURL, feed, _ := newTestHeadFeedNode(t)
+	cfg := testConfig(newTestNode(t, 1), newTestNode(t, 1))
+	cfg.WsUrl = mainURL
+	cfg.SubscriptionFollowsContext = true
+	cfg.MethodTimeoutMs = 1000
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := make(chan *types.Header)
+	sub, err := c.SubscribeNewHead(ctx, ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	// the subscription outlives its attempt
+	h := testBranch(nil, 1, 1, 0)[0]
+	select {
+	case feed.heads <- h:
+	case err := <-sub.Err():
+		t.Fatalf("subscription ended with the attempt: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("head not sent")
+	}
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no head received")
+	}
+
+	cancel()
+	select {
+	case err := <-sub.Err():
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("subscription error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription didn't follow the context")
+	}
+}
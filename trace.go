@@ -0,0 +1,83 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// TraceClient exposes the debug trace methods, the client returned by New
+// satisfies it. They only go to the endpoints offering them, see
+// Config.RpcTrace, and like geth methods endpoints answering "method not
+// found" are skipped for a while.
+//
+//	tc, ok := client.(ethclient.TraceClient)
+type TraceClient interface {
+	// TraceTransaction replays the transaction of hash (debug_traceTransaction)
+	// and returns the output of the tracer of config, the struct logger
+	// when nil.
+	TraceTransaction(ctx context.Context, hash common.Hash, config *TraceConfig) (json.RawMessage, error)
+	// TraceBlockByNumber replays the transactions of the block at
+	// blockNumber (debug_traceBlockByNumber), the latest block when nil.
+	TraceBlockByNumber(ctx context.Context, blockNumber *big.Int, config *TraceConfig) ([]TxTraceResult, error)
+}
+
+var _ TraceClient = (*client)(nil)
+
+// traceMethods are only offered by some endpoints, they are routed to the
+// endpoints offering them and subject to capability gating.
+var traceMethods = map[string]bool{
+	"TraceTransaction":   true,
+	"TraceBlockByNumber": true,
+}
+
+// TraceConfig selects and configures the tracer of a trace call, mirroring
+// geth's tracers.TraceConfig.
+type TraceConfig struct {
+	// options of the struct logger, used without Tracer
+	EnableMemory     bool `json:"enableMemory,omitempty"`
+	DisableStack     bool `json:"disableStack,omitempty"`
+	DisableStorage   bool `json:"disableStorage,omitempty"`
+	EnableReturnData bool `json:"enableReturnData,omitempty"`
+	// name of a built-in tracer (e.g. callTracer) or javascript code
+	Tracer       string          `json:"tracer,omitempty"`
+	TracerConfig json.RawMessage `json:"tracerConfig,omitempty"`
+	// e.g. 10s, the node's default (5s for geth) when empty
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// TxTraceResult is the trace of a transaction of a block, mirroring geth's
+// tracers.txTraceResult.
+type TxTraceResult struct {
+	TxHash common.Hash     `json:"txHash,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+func (c *client) TraceTransaction(ctx context.Context, hash common.Hash, config *TraceConfig) (json.RawMessage, error) {
+	return do(ctx, c, "TraceTransaction", func(ctx context.Context, _ *ethclient.Client) (json.RawMessage, error) {
+		var res json.RawMessage
+		if err := rpcClientFromContext(ctx).CallContext(ctx, &res, "debug_traceTransaction", hash, config); err != nil {
+			return nil, err
+		}
+		return res, nil
+	})
+}
+
+func (c *client) TraceBlockByNumber(ctx context.Context, blockNumber *big.Int, config *TraceConfig) ([]TxTraceResult, error) {
+	// tracing replays the block on the state of its parent
+	stateBlock := blockNumber
+	if blockNumber != nil && blockNumber.Sign() > 0 {
+		stateBlock = new(big.Int).Sub(blockNumber, common.Big1)
+	}
+	return do(c.withStateBlock(ctx, stateBlock), c, "TraceBlockByNumber", func(ctx context.Context, _ *ethclient.Client) ([]TxTraceResult, error) {
+		var res []TxTraceResult
+		if err := rpcClientFromContext(ctx).CallContext(ctx, &res, "debug_traceBlockByNumber", blockNumberArg(blockNumber), config); err != nil {
+			return nil, err
+		}
+		return res, nil
+	})
+}
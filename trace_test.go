@@ -0,0 +1,108 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testDebugService serves debug trace calls, counting them.
+type testDebugService struct {
+	calls  atomic.Int64
+	blocks chan string
+}
+
+func (s *testDebugService) TraceTransaction(hash common.Hash, config *TraceConfig) map[string]interface{} {
+	s.calls.Add(1)
+	if config == nil {
+		config = &TraceConfig{}
+	}
+	return map[string]interface{}{"tx": hash, "tracer": config.Tracer}
+}
+
+func (s *testDebugService) TraceBlockByNumber(number string, _ *TraceConfig) []TxTraceResult {
+	s.calls.Add(1)
+	s.blocks <- number
+	return []TxTraceResult{{TxHash: common.Hash{1}, Result: json.RawMessage(`{}`)}}
+}
+
+// newTestTraceNode serves the eth namespace and, with debug, the debug one.
+func newTestTraceNode(t *testing.T, debug *testDebugService) string {
+	t.Helper()
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", &testEthService{chainID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if debug != nil {
+		if err := srv.RegisterName("debug", debug); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hs := httptest.NewServer(srv)
+	t.Cleanup(func() {
+		hs.Close()
+		srv.Stop()
+	})
+	return hs.URL
+}
+
+func TestTraceRoutesToTraceEndpoints(t *testing.T) {
+	primary, failover := &testDebugService{}, &testDebugService{blocks: make(chan string, 1)}
+	cfg := testConfig(newTestTraceNode(t, primary), newTestTraceNode(t, failover))
+	cfg.FailoverRpcTrace = true
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	tc := c.(TraceClient)
+	r, err := tc.TraceTransaction(context.Background(), common.Hash{2}, &TraceConfig{Tracer: "callTracer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res struct {
+		Tx     common.Hash
+		Tracer string
+	}
+	if err := json.Unmarshal(r, &res); err != nil || res.Tx != (common.Hash{2}) || res.Tracer != "callTracer" {
+		t.Fatalf("TraceTransaction = %s, %v", r, err)
+	}
+	traces, err := tc.TraceBlockByNumber(context.Background(), big.NewInt(10), nil)
+	if err != nil || len(traces) != 1 || traces[0].TxHash != (common.Hash{1}) {
+		t.Fatalf("TraceBlockByNumber = %+v, %v", traces, err)
+	}
+	if block := <-failover.blocks; block != "0xa" {
+		t.Fatalf("traced block %s, want 0xa", block)
+	}
+	if n := primary.calls.Load(); n != 0 {
+		t.Fatalf("primary without trace support got %d trace calls", n)
+	}
+}
+
+func TestTraceSkipsEndpointsLackingIt(t *testing.T) {
+	failover := &testDebugService{}
+	c, err := New("test", "eth", testConfig(newTestTraceNode(t, nil), newTestTraceNode(t, failover)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.(TraceClient).TraceTransaction(context.Background(), common.Hash{2}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c.(*client).m.supports("TraceTransaction") {
+		t.Fatal("primary lacking debug_traceTransaction not remembered")
+	}
+	if n := failover.calls.Load(); n != 2 {
+		t.Fatalf("failover got %d trace calls, want 2", n)
+	}
+}
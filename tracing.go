@@ -0,0 +1,61 @@
+package ethclient
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	attrMethod   = attribute.Key("rpc.method")
+	attrEndpoint = attribute.Key("ethclient.endpoint")
+	attrAttempt  = attribute.Key("ethclient.attempt")
+	attrFailover = attribute.Key("ethclient.failover")
+	attrReason   = attribute.Key("ethclient.failover.reason")
+)
+
+// startSpan starts a client span, nested steps (e.g. failover decisions)
+// find it back with trace.SpanFromContext.
+func (c *client) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return c.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// endSpan records err, if any, as the outcome of span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// traceContextTransport sets the traceparent and tracestate headers of
+// requests from the span of their context. Baggage isn't propagated, it
+// may hold data not meant for providers.
+type traceContextTransport struct {
+	base http.RoundTripper
+}
+
+func (t *traceContextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if trace.SpanContextFromContext(req.Context()).IsValid() {
+		// a RoundTripper must not modify the request
+		req = req.Clone(req.Context())
+		propagation.TraceContext{}.Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+	}
+	return t.base.RoundTrip(req)
+}
+
+// withTraceContext returns a copy of hc propagating trace contexts.
+func withTraceContext(hc *http.Client) *http.Client {
+	cp := *hc
+	base := cp.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	cp.Transport = &traceContextTransport{base: base}
+	return &cp
+}
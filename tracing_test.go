@@ -0,0 +1,136 @@
+package ethclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordingTracer keeps the spans it started for inspection.
+type recordingTracer struct {
+	trace.Tracer
+	mu    sync.Mutex
+	spans []*recordingSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	ctx, noop := t.Tracer.Start(ctx, name, opts...)
+	cfg := trace.NewSpanStartConfig(opts...)
+	s := &recordingSpan{Span: noop, name: name, attrs: cfg.Attributes()}
+	t.mu.Lock()
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+	return trace.ContextWithSpan(ctx, s), s
+}
+
+type recordingSpan struct {
+	trace.Span
+	name   string
+	attrs  []attribute.KeyValue
+	events []string
+	status codes.Code
+	err    error
+	ended  bool
+}
+
+func (s *recordingSpan) AddEvent(name string, _ ...trace.EventOption) {
+	s.events = append(s.events, name)
+}
+func (s *recordingSpan) RecordError(err error, _ ...trace.EventOption) { s.err = err }
+func (s *recordingSpan) SetStatus(code codes.Code, _ string)           { s.status = code }
+func (s *recordingSpan) End(...trace.SpanEndOption)                    { s.ended = true }
+
+func (s *recordingSpan) attr(key attribute.Key) attribute.Value {
+	for _, kv := range s.attrs {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracingFailover(t *testing.T) {
+	tracer := &recordingTracer{Tracer: trace.NewNoopTracerProvider().Tracer("")}
+	cfg := testConfig("http://127.0.0.1:1", newTestNodeWith(t, &testEthService{chainID: 1, head: 42}))
+	cfg.VerifyChainID = false
+	cfg.Tracer = tracer
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.(*client).Close()
+
+	if n, err := c.(*client).BlockNumber(context.Background()); err != nil || n != 42 {
+		t.Fatalf("BlockNumber = %d, %v, want 42", n, err)
+	}
+
+	if len(tracer.spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(tracer.spans))
+	}
+	call, main, backup := tracer.spans[0], tracer.spans[1], tracer.spans[2]
+	if call.name != "ethclient.BlockNumber" || call.status != codes.Unset || len(call.events) != 1 || call.events[0] != "failover" {
+		t.Errorf("unexpected call span %+v", call)
+	}
+	if main.attr(attrEndpoint).AsString() != "main" || main.attr(attrFailover).AsBool() || main.status != codes.Error || main.err == nil {
+		t.Errorf("unexpected main attempt span %+v", main)
+	}
+	if backup.attr(attrEndpoint).AsString() != "failover" || !backup.attr(attrFailover).AsBool() || backup.status != codes.Unset {
+		t.Errorf("unexpected failover attempt span %+v", backup)
+	}
+	for _, s := range tracer.spans {
+		if !s.ended {
+			t.Errorf("span %s not ended", s.name)
+		}
+	}
+}
+
+func TestPropagateTraceContext(t *testing.T) {
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", &testEthService{chainID: 1, head: 7}); err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var traceparents []string
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		mu.Unlock()
+		srv.ServeHTTP(w, r)
+	}))
+	defer srv.Stop()
+	defer hs.Close()
+
+	cfg := testConfig(hs.URL, newTestNode(t, 1))
+	cfg.PropagateTraceContext = true
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1, 2, 3},
+		SpanID:     trace.SpanID{4, 5, 6},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), sc)
+	if _, err := c.(*client).BlockNumber(ctx); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	// the chain id verification of New has no span
+	if len(traceparents) != 2 || traceparents[0] != "" {
+		t.Fatalf("traceparent headers = %q", traceparents)
+	}
+	if want := "00-" + sc.TraceID().String() + "-" + sc.SpanID().String() + "-01"; traceparents[1] != want {
+		t.Fatalf("traceparent = %q, want %q", traceparents[1], want)
+	}
+}
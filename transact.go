@@ -0,0 +1,86 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// FillTransactOpts fills the unset nonce, fees and gas limit of opts for a
+// transaction from opts.From to to with input, so that contract bindings
+// don't query them through a plain backend:
+//   - the nonce is the pending nonce of opts.From,
+//   - the fees are an EIP-1559 tip cap and a fee cap of twice the base fee
+//     plus the tip, or a legacy gas price on chains without a base fee,
+//   - the gas limit is estimated and raised by Config.GasLimitMarginPercent.
+//
+// Fields already set are left untouched. to is nil for contract creations.
+func (c *client) FillTransactOpts(ctx context.Context, opts *bind.TransactOpts, to *common.Address, input []byte) error {
+	if opts.Context == nil {
+		opts.Context = ctx
+	}
+	if opts.Nonce == nil {
+		nonce, err := c.PendingNonceAt(ctx, opts.From)
+		if err != nil {
+			return fmt.Errorf("failed to get nonce: %w", err)
+		}
+		opts.Nonce = new(big.Int).SetUint64(nonce)
+	}
+	if opts.GasPrice == nil && (opts.GasFeeCap == nil || opts.GasTipCap == nil) {
+		if err := c.fillFees(ctx, opts); err != nil {
+			return err
+		}
+	}
+	if opts.GasLimit == 0 {
+		gas, err := c.EstimateGas(ctx, ethereum.CallMsg{
+			From:      opts.From,
+			To:        to,
+			GasPrice:  opts.GasPrice,
+			GasFeeCap: opts.GasFeeCap,
+			GasTipCap: opts.GasTipCap,
+			Value:     opts.Value,
+			Data:      input,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to estimate gas: %w", err)
+		}
+		opts.GasLimit = gas + gas*uint64(c.cfg.GasLimitMarginPercent)/100
+	}
+	return nil
+}
+
+func (c *client) fillFees(ctx context.Context, opts *bind.TransactOpts) error {
+	head, err := c.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get head: %w", err)
+	}
+	if head.BaseFee == nil {
+		if opts.GasFeeCap != nil || opts.GasTipCap != nil {
+			return fmt.Errorf("chain doesn't support EIP-1559 fees")
+		}
+		price, err := c.SuggestGasPrice(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to suggest gas price: %w", err)
+		}
+		opts.GasPrice = price
+		return nil
+	}
+	if opts.GasTipCap == nil {
+		tip, err := c.SuggestGasTipCap(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to suggest gas tip cap: %w", err)
+		}
+		opts.GasTipCap = tip
+	}
+	if opts.GasFeeCap == nil {
+		opts.GasFeeCap = new(big.Int).Add(opts.GasTipCap, new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+	}
+	if opts.GasFeeCap.Cmp(opts.GasTipCap) < 0 {
+		return fmt.Errorf("gas fee cap %s below gas tip cap %s", opts.GasFeeCap, opts.GasTipCap)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// testFeeService serves a head with baseFee, a suggested tip and a gas
+// estimate of 100000.
+type testFeeService struct {
+	baseFee *big.Int
+}
+
+func (s testFeeService) GetBlockByNumber(_ string, _ bool) *types.Header {
+	return &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(0), BaseFee: s.baseFee}
+}
+
+func (testFeeService) MaxPriorityFeePerGas() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(2))
+}
+
+func (testFeeService) GasPrice() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(30))
+}
+
+func (testFeeService) EstimateGas(_ map[string]interface{}) hexutil.Uint64 {
+	return 100000
+}
+
+func TestFillTransactOpts(t *testing.T) {
+	to := common.HexToAddress("0x01")
+	for _, tt := range []struct {
+		name    string
+		baseFee *big.Int
+		opts    bind.TransactOpts
+		want    bind.TransactOpts
+	}{
+		{
+			name:    "eip-1559",
+			baseFee: big.NewInt(10),
+			want:    bind.TransactOpts{Nonce: big.NewInt(0), GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(22), GasLimit: 120000},
+		},
+		{
+			name: "legacy",
+			want: bind.TransactOpts{Nonce: big.NewInt(0), GasPrice: big.NewInt(30), GasLimit: 120000},
+		},
+		{
+			name:    "preset",
+			baseFee: big.NewInt(10),
+			opts:    bind.TransactOpts{Nonce: big.NewInt(5), GasTipCap: big.NewInt(3), GasLimit: 50000},
+			want:    bind.TransactOpts{Nonce: big.NewInt(5), GasTipCap: big.NewInt(3), GasFeeCap: big.NewInt(23), GasLimit: 50000},
+		},
+	} {
+		svc := testFeeService{baseFee: tt.baseFee}
+		cfg := testConfig(
+			newTestNodeWith(t, &testEthService{chainID: 1}, svc, &testTxPool{}),
+			newTestNodeWith(t, &testEthService{chainID: 1}, svc, &testTxPool{}),
+		)
+		cfg.GasLimitMarginPercent = 20
+		c, err := New("test", "eth", cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts := tt.opts
+		if err := c.FillTransactOpts(context.Background(), &opts, &to, nil); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		c.Close()
+		if !bigEqual(opts.Nonce, tt.want.Nonce) || !bigEqual(opts.GasPrice, tt.want.GasPrice) ||
+			!bigEqual(opts.GasTipCap, tt.want.GasTipCap) || !bigEqual(opts.GasFeeCap, tt.want.GasFeeCap) ||
+			opts.GasLimit != tt.want.GasLimit {
+			t.Errorf("%s: nonce %v, gas price %v, tip cap %v, fee cap %v, gas limit %d, want %v, %v, %v, %v, %d", tt.name,
+				opts.Nonce, opts.GasPrice, opts.GasTipCap, opts.GasFeeCap, opts.GasLimit,
+				tt.want.Nonce, tt.want.GasPrice, tt.want.GasTipCap, tt.want.GasFeeCap, tt.want.GasLimit)
+		}
+	}
+}
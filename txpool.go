@@ -0,0 +1,51 @@
+package ethclient
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// TxPoolContent is the result of txpool_content: the transactions of the
+// pool of the endpoint, by sender and nonce, either executable (Pending) or
+// waiting for a nonce gap to be filled (Queued).
+type TxPoolContent struct {
+	Pending map[common.Address]map[uint64]*types.Transaction `json:"pending"`
+	Queued  map[common.Address]map[uint64]*types.Transaction `json:"queued"`
+}
+
+// TxPoolStatus is the result of txpool_status, the number of pending and
+// queued transactions in the pool of the endpoint.
+type TxPoolStatus struct {
+	Pending uint64
+	Queued  uint64
+}
+
+// TxPoolContent returns the transactions in the pool of the endpoint
+// serving the call. Pools differ between nodes, a transaction missing from
+// the backup's pool isn't necessarily dropped.
+func (c *client) TxPoolContent(ctx context.Context) (*TxPoolContent, error) {
+	return do(ctx, c, "TxPoolContent", func(ctx context.Context, _ *ethclient.Client) (*TxPoolContent, error) {
+		var content TxPoolContent
+		if err := rpcClientFromContext(ctx).CallContext(ctx, &content, "txpool_content"); err != nil {
+			return nil, err
+		}
+		return &content, nil
+	})
+}
+
+func (c *client) TxPoolStatus(ctx context.Context) (*TxPoolStatus, error) {
+	return do(ctx, c, "TxPoolStatus", func(ctx context.Context, _ *ethclient.Client) (*TxPoolStatus, error) {
+		var status struct {
+			Pending hexutil.Uint64 `json:"pending"`
+			Queued  hexutil.Uint64 `json:"queued"`
+		}
+		if err := rpcClientFromContext(ctx).CallContext(ctx, &status, "txpool_status"); err != nil {
+			return nil, err
+		}
+		return &TxPoolStatus{Pending: uint64(status.Pending), Queued: uint64(status.Queued)}, nil
+	})
+}
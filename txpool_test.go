@@ -0,0 +1,66 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testTxPoolService serves a pool holding tx, pending.
+type testTxPoolService struct {
+	tx *types.Transaction
+}
+
+func (p *testTxPoolService) Content() map[string]interface{} {
+	sender, _ := types.Sender(types.LatestSignerForChainID(p.tx.ChainId()), p.tx)
+	return map[string]interface{}{
+		"pending": map[string]interface{}{sender.Hex(): map[string]interface{}{"3": p.tx}},
+		"queued":  map[string]interface{}{},
+	}
+}
+
+func (p *testTxPoolService) Status() map[string]hexutil.Uint {
+	return map[string]hexutil.Uint{"pending": 1, "queued": 0}
+}
+
+func TestTxPool(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	tx := types.MustSignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 3, Gas: 21000, GasFeeCap: big.NewInt(10)})
+	srv := rpc.NewServer()
+	for name, rcvr := range map[string]interface{}{"eth": &testEthService{chainID: 1}, "txpool": &testTxPoolService{tx: tx}} {
+		if err := srv.RegisterName(name, rcvr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hs := httptest.NewServer(srv)
+	defer hs.Close()
+	// the primary lacks the txpool namespace
+	c, err := New("test", "eth", testConfig(newTestNode(t, 1), hs.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	gc := c.(GethClient)
+
+	status, err := gc.TxPoolStatus(context.Background())
+	if err != nil || status.Pending != 1 || status.Queued != 0 {
+		t.Fatalf("TxPoolStatus = %+v, %v", status, err)
+	}
+	content, err := gc.TxPoolContent(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	if got := content.Pending[sender][3]; got == nil || got.Hash() != tx.Hash() {
+		t.Fatalf("pending transactions = %v, want %s", content.Pending, tx.Hash())
+	}
+	if c.(*client).m.supports("TxPoolContent") {
+		t.Fatal("primary lacking txpool_content not remembered")
+	}
+}
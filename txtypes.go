@@ -0,0 +1,71 @@
+package ethclient
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Transaction types missing from the go-ethereum version in use.
+const (
+	BlobTxType    = 0x03 // EIP-4844
+	DepositTxType = 0x7e // OP stack L1 deposits, never sent through rpc
+)
+
+// ErrTxTypeNotSupported is returned by SendTransaction when the chain
+// doesn't accept the type of the transaction.
+var ErrTxTypeNotSupported = errors.New("transaction type not supported by chain")
+
+var txTypes = struct {
+	sync.RWMutex
+	byChain map[uint64]map[uint8]bool
+}{
+	byChain: map[uint64]map[uint8]bool{
+		1:        txTypeSet(types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType, BlobTxType), // ethereum
+		11155111: txTypeSet(types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType, BlobTxType), // sepolia
+		17000:    txTypeSet(types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType, BlobTxType), // holesky
+		10:       txTypeSet(types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType),             // optimism
+		8453:     txTypeSet(types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType),             // base
+		42161:    txTypeSet(types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType),             // arbitrum one
+		137:      txTypeSet(types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType),             // polygon
+		56:       txTypeSet(types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType),             // bsc
+		324:      txTypeSet(types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType),             // zksync era
+	},
+}
+
+func txTypeSet(accepted ...uint8) map[uint8]bool {
+	set := make(map[uint8]bool, len(accepted))
+	for _, t := range accepted {
+		set[t] = true
+	}
+	return set
+}
+
+// RegisterTxTypes sets the transaction types accepted by a chain, replacing
+// the built-in ones. Transactions to chains without registered types are
+// not checked.
+func RegisterTxTypes(chainID uint64, accepted ...uint8) {
+	set := txTypeSet(accepted...)
+	txTypes.Lock()
+	defer txTypes.Unlock()
+	txTypes.byChain[chainID] = set
+}
+
+// checkTxType returns ErrTxTypeNotSupported if chainID is known not to
+// accept tx. Signed transactions can't be converted to another type, the
+// caller has to build and sign a supported one instead.
+func checkTxType(chainID *big.Int, tx *types.Transaction) error {
+	if chainID == nil || !chainID.IsUint64() {
+		return nil
+	}
+	txTypes.RLock()
+	supported, ok := txTypes.byChain[chainID.Uint64()]
+	txTypes.RUnlock()
+	if !ok || supported[tx.Type()] {
+		return nil
+	}
+	return fmt.Errorf("%w: type %d on chain %s", ErrTxTypeNotSupported, tx.Type(), chainID)
+}
@@ -0,0 +1,34 @@
+package ethclient
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestCheckTxType(t *testing.T) {
+	legacy := types.NewTx(&types.LegacyTx{})
+	dynamic := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1)})
+	RegisterTxTypes(999999, types.LegacyTxType)
+
+	for _, tt := range []struct {
+		chainID *big.Int
+		tx      *types.Transaction
+		wantErr bool
+	}{
+		{nil, dynamic, false},
+		{big.NewInt(1), dynamic, false},
+		{big.NewInt(10), legacy, false},
+		{big.NewInt(999999), legacy, false},
+		{big.NewInt(999999), dynamic, true},
+		// unknown chains aren't checked
+		{big.NewInt(123456789), dynamic, false},
+	} {
+		err := checkTxType(tt.chainID, tt.tx)
+		if got := errors.Is(err, ErrTxTypeNotSupported); got != tt.wantErr {
+			t.Errorf("checkTxType(%v, type %d) = %v, wantErr %v", tt.chainID, tt.tx.Type(), err, tt.wantErr)
+		}
+	}
+}
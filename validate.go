@@ -0,0 +1,143 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrInvalidResponse is returned, with ValidateResponses, when a response
+// of an endpoint breaks an invariant of its method, e.g. a receipt of
+// another transaction. It fails over like any other error of the endpoint.
+var ErrInvalidResponse = errors.New("invalid rpc response")
+
+// validate checks the response of a successful call in ctx with check,
+// when enabled, and turns a violation into ErrInvalidResponse.
+func (c *client) validate(ctx context.Context, err error, check func() error) error {
+	if err != nil || !c.cfg.ValidateResponses {
+		return err
+	}
+	if err := check(); err != nil {
+		name := "unknown"
+		if e := endpointFromContext(ctx); e != nil {
+			name = e.name
+		}
+		c.logger.Warn().Msgf("rpc %s returned an invalid response: %s", name, err)
+		return fmt.Errorf("%w from rpc %s: %s", ErrInvalidResponse, name, err)
+	}
+	return nil
+}
+
+// checkReceipt checks that r is a receipt of the transaction hash with
+// consistent logs.
+func checkReceipt(r *types.Receipt, hash common.Hash) error {
+	if r.TxHash != hash {
+		return fmt.Errorf("receipt of %s instead of %s", r.TxHash, hash)
+	}
+	if r.Status != types.ReceiptStatusFailed && r.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("receipt of %s with status %d", hash, r.Status)
+	}
+	for i, l := range r.Logs {
+		if l.TxHash != hash || l.BlockHash != r.BlockHash || r.BlockNumber == nil || l.BlockNumber != r.BlockNumber.Uint64() {
+			return fmt.Errorf("receipt of %s with log %d of another transaction", hash, l.Index)
+		}
+		if i > 0 && l.Index <= r.Logs[i-1].Index {
+			return fmt.Errorf("receipt of %s with log indices out of order", hash)
+		}
+	}
+	return nil
+}
+
+// checkBlockReceipts checks that receipts are those of one block, the one
+// asked for with blockNrOrHash, ordered by transaction index.
+func checkBlockReceipts(receipts []*types.Receipt, blockNrOrHash rpc.BlockNumberOrHash) error {
+	for i, r := range receipts {
+		if hash, ok := blockNrOrHash.Hash(); ok && r.BlockHash != hash {
+			return fmt.Errorf("receipt of block %s instead of %s", r.BlockHash, hash)
+		}
+		if number, ok := blockNrOrHash.Number(); ok {
+			if err := checkNumber(r.BlockNumber, big.NewInt(number.Int64())); err != nil {
+				return fmt.Errorf("receipt of %s: %w", r.TxHash, err)
+			}
+		}
+		if r.BlockHash != receipts[0].BlockHash || r.TransactionIndex != uint(i) {
+			return fmt.Errorf("receipt of %s out of place in the block", r.TxHash)
+		}
+	}
+	return nil
+}
+
+// checkNumber checks that a block or header numbered got was asked for
+// with number, which may be a tag.
+func checkNumber(got *big.Int, number *big.Int) error {
+	if number == nil || number.Sign() < 0 {
+		return nil
+	}
+	if got == nil || got.Cmp(number) != 0 {
+		return fmt.Errorf("block %v instead of %s", got, number)
+	}
+	return nil
+}
+
+// checkLogs checks that logs match q and are ordered by block and index.
+func checkLogs(logs []types.Log, q ethereum.FilterQuery) error {
+	var from, to uint64
+	if q.BlockHash == nil {
+		if q.FromBlock != nil && q.FromBlock.Sign() >= 0 {
+			from = q.FromBlock.Uint64()
+		}
+		to = ^uint64(0)
+		if q.ToBlock != nil && q.ToBlock.Sign() >= 0 {
+			to = q.ToBlock.Uint64()
+		}
+	}
+	for i, l := range logs {
+		if q.BlockHash != nil && l.BlockHash != *q.BlockHash {
+			return fmt.Errorf("log of block %s instead of %s", l.BlockHash, q.BlockHash)
+		}
+		if l.BlockNumber < from || l.BlockNumber > to {
+			return fmt.Errorf("log of block %d out of the queried range", l.BlockNumber)
+		}
+		if !logMatches(l, q) {
+			return fmt.Errorf("log %d of block %d doesn't match the filter", l.Index, l.BlockNumber)
+		}
+		if i > 0 {
+			prev := logs[i-1]
+			if l.BlockNumber < prev.BlockNumber || l.BlockNumber == prev.BlockNumber && l.Index <= prev.Index {
+				return fmt.Errorf("logs out of order at log %d of block %d", l.Index, l.BlockNumber)
+			}
+		}
+	}
+	return nil
+}
+
+// logMatches reports whether l matches the addresses and topics of q.
+func logMatches(l types.Log, q ethereum.FilterQuery) bool {
+	if len(q.Addresses) > 0 && !contains(q.Addresses, l.Address) {
+		return false
+	}
+	for i, topics := range q.Topics {
+		if len(topics) == 0 {
+			continue
+		}
+		if i >= len(l.Topics) || !contains(topics, l.Topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains[T comparable](set []T, v T) bool {
+	for _, s := range set {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// testReceipts serves receipts of every transaction, with the given status
+// and the hash of another transaction if wrongHash is set.
+type testReceipts struct {
+	status    uint64
+	wrongHash bool
+}
+
+func (s testReceipts) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	if s.wrongHash {
+		hash = common.Hash{0xff}
+	}
+	return &types.Receipt{Status: s.status, TxHash: hash, BlockNumber: big.NewInt(3), Logs: []*types.Log{}}
+}
+
+func TestValidateResponses(t *testing.T) {
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1}, testReceipts{status: 2}),
+		newTestNodeWith(t, &testEthService{chainID: 1}, testReceipts{status: 1}),
+	)
+	cfg.ValidateResponses = true
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	r, err := c.TransactionReceipt(context.Background(), common.Hash{1})
+	if err != nil || r.Status != 1 {
+		t.Fatalf("TransactionReceipt = %v, %v, want the valid receipt of the failover", r, err)
+	}
+
+	cfg = testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1}, testReceipts{status: 1, wrongHash: true}),
+		newTestNodeWith(t, &testEthService{chainID: 1}, testReceipts{status: 1, wrongHash: true}),
+	)
+	cfg.ValidateResponses = true
+	c, err = New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if _, err := c.TransactionReceipt(context.Background(), common.Hash{1}); !errors.Is(err, ErrInvalidResponse) {
+		t.Fatalf("TransactionReceipt error = %v, want ErrInvalidResponse", err)
+	}
+}
+
+func TestCheckReceipt(t *testing.T) {
+	hash := common.Hash{1}
+	log := func(index uint, tx common.Hash) *types.Log {
+		return &types.Log{TxHash: tx, BlockNumber: 3, Index: index}
+	}
+	for _, tt := range []struct {
+		name string
+		r    *types.Receipt
+		ok   bool
+	}{
+		{"valid", &types.Receipt{Status: 1, TxHash: hash, BlockNumber: big.NewInt(3), Logs: []*types.Log{log(1, hash), log(2, hash)}}, true},
+		{"reverted", &types.Receipt{Status: 0, TxHash: hash, BlockNumber: big.NewInt(3)}, true},
+		{"other transaction", &types.Receipt{Status: 1, TxHash: common.Hash{2}, BlockNumber: big.NewInt(3)}, false},
+		{"invalid status", &types.Receipt{Status: 2, TxHash: hash, BlockNumber: big.NewInt(3)}, false},
+		{"log of another transaction", &types.Receipt{Status: 1, TxHash: hash, BlockNumber: big.NewInt(3), Logs: []*types.Log{log(1, common.Hash{2})}}, false},
+		{"log indices out of order", &types.Receipt{Status: 1, TxHash: hash, BlockNumber: big.NewInt(3), Logs: []*types.Log{log(2, hash), log(1, hash)}}, false},
+	} {
+		if err := checkReceipt(tt.r, hash); (err == nil) != tt.ok {
+			t.Errorf("%s: checkReceipt = %v", tt.name, err)
+		}
+	}
+}
+
+func TestCheckLogs(t *testing.T) {
+	address, topic := common.Address{1}, common.Hash{1}
+	q := ethereum.FilterQuery{FromBlock: big.NewInt(10), ToBlock: big.NewInt(20), Addresses: []common.Address{address}, Topics: [][]common.Hash{{topic}}}
+	log := func(block uint64, index uint) types.Log {
+		return types.Log{Address: address, Topics: []common.Hash{topic}, BlockNumber: block, Index: index}
+	}
+	other := log(12, 0)
+	other.Address = common.Address{2}
+	for _, tt := range []struct {
+		name string
+		logs []types.Log
+		ok   bool
+	}{
+		{"valid", []types.Log{log(10, 3), log(12, 0), log(12, 1), log(20, 0)}, true},
+		{"out of range", []types.Log{log(21, 0)}, false},
+		{"out of order", []types.Log{log(12, 1), log(12, 0)}, false},
+		{"other address", []types.Log{other}, false},
+		{"missing topic", []types.Log{{Address: address, BlockNumber: 12}}, false},
+	} {
+		if err := checkLogs(tt.logs, q); (err == nil) != tt.ok {
+			t.Errorf("%s: checkLogs = %v", tt.name, err)
+		}
+	}
+	if err := checkNumber(big.NewInt(5), big.NewInt(6)); err == nil {
+		t.Error("checkNumber accepted another block")
+	}
+	if err := checkNumber(big.NewInt(5), big.NewInt(-2)); err != nil {
+		t.Errorf("checkNumber of a tag = %v", err)
+	}
+}
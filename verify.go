@@ -0,0 +1,102 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ErrChainIDMismatch is returned when an endpoint serves a different chain
+// than the one the client was set up with.
+var ErrChainIDMismatch = errors.New("chain id mismatch")
+
+// VerifyEndpoints checks that every endpoint serves the same chain. The
+// chain id of the first verification is used as reference, endpoints that
+// disagree with it are excluded from routing until they agree again.
+// A mismatch is reported in preference to an unreachable endpoint.
+func (c *client) VerifyEndpoints(ctx context.Context) error {
+	var mismatchErr, firstErr error
+	for _, e := range c.endpoints() {
+		var id *big.Int
+		err := e.call(ctx, func(ec *ethclient.Client) (err error) {
+			id, err = ec.ChainID(ctx)
+			return err
+		})
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to get chain id from rpc %s: %w", e.name, err)
+			}
+			continue
+		}
+		c.chainID.CompareAndSwap(nil, id)
+		expected := c.chainID.Load()
+		mismatch := id.Cmp(expected) != 0
+		if e.chainMismatch.Swap(mismatch) != mismatch {
+			if mismatch {
+				c.logger.Error().Msgf("rpc %s serves chain %s instead of %s, excluding it", e.name, id, expected)
+			} else {
+				c.logger.Info().Msgf("rpc %s serves chain %s again, restoring it", e.name, id)
+			}
+		}
+		if !mismatch {
+			c.remember("ChainID", expected)
+		}
+		if mismatch && mismatchErr == nil {
+			mismatchErr = fmt.Errorf("%w: rpc %s serves chain %s, expected %s", ErrChainIDMismatch, e.name, id, expected)
+		}
+	}
+	if mismatchErr != nil {
+		return mismatchErr
+	}
+	return firstErr
+}
+
+// verifyUntilReachable runs VerifyEndpoints in the background after delay,
+// then every interval until every endpoint could be checked. It covers
+// endpoints that were unreachable, or not dialed yet, when the client was
+// created.
+func (c *client) verifyUntilReachable(delay, interval time.Duration) {
+	c.goBackground(func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		for {
+			select {
+			case <-c.done:
+				return
+			case <-timer.C:
+				ctx, cancel := context.WithTimeout(context.Background(), verifyTimeout)
+				err := c.VerifyEndpoints(ctx)
+				cancel()
+				if err == nil || errors.Is(err, ErrChainIDMismatch) {
+					return
+				}
+				c.logger.Debug().Msgf("failed to verify rpc endpoints: %s", err)
+				timer.Reset(interval)
+			}
+		}
+	})
+}
+
+// verifyPeriodically re-runs VerifyEndpoints in the background.
+func (c *client) verifyPeriodically(interval time.Duration) {
+	c.goBackground(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.done:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), interval)
+				if err := c.VerifyEndpoints(ctx); err != nil && !errors.Is(err, ErrChainIDMismatch) {
+					c.logger.Warn().Msgf("failed to verify rpc endpoints: %s", err)
+				}
+				cancel()
+			}
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testEthService serves the subset of the eth namespace used in tests.
+type testEthService struct {
+	chainID int64
+	head    uint64
+	balance int64
+	delay   time.Duration // of BlockNumber
+}
+
+func (s *testEthService) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(s.chainID))
+}
+
+func (s *testEthService) BlockNumber() hexutil.Uint64 {
+	time.Sleep(s.delay)
+	return hexutil.Uint64(s.head)
+}
+
+func (s *testEthService) GetBalance(_ common.Address, _ string) *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(s.balance))
+}
+
+func newTestNode(t *testing.T, chainID int64) string {
+	t.Helper()
+	return newTestNodeWith(t, &testEthService{chainID: chainID})
+}
+
+// newTestNodeWith serves svc, along with extra receivers whose methods are
+// added to the eth namespace.
+func newTestNodeWith(t *testing.T, svc *testEthService, extra ...interface{}) string {
+	t.Helper()
+	srv := rpc.NewServer()
+	for _, rcvr := range append([]interface{}{svc}, extra...) {
+		if err := srv.RegisterName("eth", rcvr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hs := httptest.NewServer(srv)
+	t.Cleanup(func() {
+		hs.Close()
+		srv.Stop()
+	})
+	return hs.URL
+}
+
+func testConfig(rpcURL, failoverURL string) *Config {
+	return &Config{
+		RpcUrl:                rpcURL,
+		RpcName:               "main",
+		FailoverRpcUrl:        failoverURL,
+		FailoverRpcName:       "failover",
+		VerifyChainID:         true,
+		RetryMaxAttempts:      1,
+		RetryInitialBackoffMs: 100,
+		RetryMaxBackoffMs:     2000,
+	}
+}
+
+func TestNewVerifiesChainID(t *testing.T) {
+	c, err := New("test", "eth", testConfig(newTestNode(t, 1), newTestNode(t, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.(*client).Close()
+
+	_, err = New("test", "eth", testConfig(newTestNode(t, 1), newTestNode(t, 5)))
+	if !errors.Is(err, ErrChainIDMismatch) {
+		t.Fatalf("New error = %v, want ErrChainIDMismatch", err)
+	}
+}
+
+func TestNewToleratesUnreachableEndpoint(t *testing.T) {
+	c, err := New("test", "eth", testConfig(newTestNode(t, 1), "http://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("New error = %v, want none", err)
+	}
+	defer c.(*client).Close()
+	if err := c.VerifyEndpoints(context.Background()); err == nil || errors.Is(err, ErrChainIDMismatch) {
+		t.Fatalf("VerifyEndpoints error = %v, want unreachable error", err)
+	}
+}
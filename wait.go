@@ -0,0 +1,158 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	defaultWaitPollInterval    = 2 * time.Second
+	defaultRebroadcastInterval = 30 * time.Second
+)
+
+// WaitOpts tunes SendTransactionAndWait, the zero value waits for the
+// inclusion of the transaction.
+type WaitOpts struct {
+	// blocks mined on top of the block including the transaction
+	Confirmations uint64
+	// between receipt polls, 2s when 0
+	PollInterval time.Duration
+	// minimum delay between broadcasts of a transaction no endpoint knows
+	// anymore, 30s when 0
+	RebroadcastInterval time.Duration
+}
+
+// SendTransactionAndWait sends tx and waits until it is included with
+// opts.Confirmations blocks on top, or ctx is done. Receipts are polled on
+// every endpoint, so an endpoint lagging behind or missing the transaction
+// doesn't delay it, and the transaction is broadcast again to every
+// endpoint while none of them knows it, e.g. after it was dropped from the
+// mempools. Once sent, Shutdown waits for the transaction. The receipt of
+// a reverted transaction is returned without error. The time to inclusion
+// is observed in rpc_transaction_inclusion_seconds.
+func (c *client) SendTransactionAndWait(ctx context.Context, tx *types.Transaction, opts *WaitOpts) (*types.Receipt, error) {
+	if opts == nil {
+		opts = &WaitOpts{}
+	}
+	poll, rebroadcast := opts.PollInterval, opts.RebroadcastInterval
+	if poll == 0 {
+		poll = defaultWaitPollInterval
+	}
+	if rebroadcast == 0 {
+		rebroadcast = defaultRebroadcastInterval
+	}
+
+	leave, err := c.enter(ctx, "SendTransactionAndWait")
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+
+	sentAt := time.Now()
+	if err := c.SendTransaction(ctx, tx); err != nil && !isKnownTxError(err) {
+		return nil, err
+	}
+	// learn the outcome of the transaction even if the client shuts down
+	ctx = Critical(ctx)
+	broadcastAt := sentAt
+	var included bool
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+		receipt := c.receiptAnywhere(ctx, tx)
+		if receipt == nil {
+			// not included, or reorged out since
+			if time.Since(broadcastAt) >= rebroadcast && !c.knownAnywhere(ctx, tx) {
+				c.broadcast(ctx, tx)
+				broadcastAt = time.Now()
+			}
+			continue
+		}
+		if !included {
+			included = true
+			c.metrics.ObserveInclusion(time.Since(sentAt))
+		}
+		if opts.Confirmations == 0 {
+			return receipt, nil
+		}
+		head, err := c.BlockNumber(ctx)
+		if err != nil {
+			c.logger.Debug().Msgf("failed to get head while waiting for %s: %s", tx.Hash(), err)
+			continue
+		}
+		if head >= receipt.BlockNumber.Uint64()+opts.Confirmations {
+			return receipt, nil
+		}
+	}
+}
+
+// availableEndpoints returns the endpoints in routing order, those not
+// receiving traffic left out.
+func (c *client) availableEndpoints() []*endpoint {
+	var endpoints []*endpoint
+	for _, e := range c.endpoints() {
+		if !e.unavailable() {
+			endpoints = append(endpoints, e)
+		}
+	}
+	return endpoints
+}
+
+// receiptAnywhere returns the receipt of tx from the first endpoint that
+// has it, or nil.
+func (c *client) receiptAnywhere(ctx context.Context, tx *types.Transaction) *types.Receipt {
+	for _, e := range c.availableEndpoints() {
+		var r *types.Receipt
+		err := c.try(ctx, "TransactionReceipt", e, false, func(ctx context.Context, ec *ethclient.Client) (err error) {
+			r, err = ec.TransactionReceipt(ctx, tx.Hash())
+			return c.validate(ctx, err, func() error { return checkReceipt(r, tx.Hash()) })
+		})
+		if err == nil {
+			return r
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			c.logger.Debug().Msgf("failed to get receipt of %s from rpc %s: %s", tx.Hash(), e.name, err)
+		}
+	}
+	return nil
+}
+
+// knownAnywhere reports whether an endpoint knows tx, pending or mined.
+// Endpoints failing to answer are assumed to know it.
+func (c *client) knownAnywhere(ctx context.Context, tx *types.Transaction) bool {
+	for _, e := range c.availableEndpoints() {
+		err := c.try(ctx, "TransactionByHash", e, false, func(ctx context.Context, ec *ethclient.Client) error {
+			_, _, err := ec.TransactionByHash(ctx, tx.Hash())
+			return err
+		})
+		if !errors.Is(err, ethereum.NotFound) {
+			return true
+		}
+	}
+	return false
+}
+
+// broadcast sends tx to every endpoint.
+func (c *client) broadcast(ctx context.Context, tx *types.Transaction) {
+	for _, e := range c.availableEndpoints() {
+		err := c.try(ctx, "SendTransaction", e, false, func(ctx context.Context, ec *ethclient.Client) error {
+			return ec.SendTransaction(ctx, tx)
+		})
+		if err != nil && !isKnownTxError(err) {
+			c.logger.Warn().Msgf("failed to broadcast %s again to rpc %s: %s", tx.Hash(), e.name, err)
+			continue
+		}
+		c.metrics.ObserveRebroadcast(e.name)
+		c.logger.Info().Msgf("broadcast %s again to rpc %s", tx.Hash(), e.name)
+	}
+}
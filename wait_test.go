@@ -0,0 +1,130 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testMempool keeps the transactions sent, unless it drops them, and
+// serves receipts of those it keeps as mined in block 3.
+type testMempool struct {
+	drop bool
+
+	mu   sync.Mutex
+	sent int
+	txs  map[common.Hash]*types.Transaction
+}
+
+func (p *testMempool) SendRawTransaction(data hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return common.Hash{}, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent++
+	if !p.drop {
+		if p.txs == nil {
+			p.txs = make(map[common.Hash]*types.Transaction)
+		}
+		p.txs[tx.Hash()] = tx
+	}
+	return tx.Hash(), nil
+}
+
+func (p *testMempool) GetTransactionByHash(hash common.Hash) *types.Transaction {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.txs[hash]
+}
+
+func (p *testMempool) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.txs[hash] == nil {
+		return nil
+	}
+	return &types.Receipt{Status: 1, TxHash: hash, BlockNumber: big.NewInt(3), Logs: []*types.Log{}}
+}
+
+func TestSendTransactionAndWait(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := common.Address{1}
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.LegacyTx{To: &to, Gas: 21000, GasPrice: big.NewInt(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// main accepts the transaction but drops it, the backup only gets it
+	// once broadcast again
+	main, backup := &testMempool{drop: true}, &testMempool{}
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 5}, main),
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 5}, backup),
+	)
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	receipt, err := c.SendTransactionAndWait(ctx, tx, &WaitOpts{
+		Confirmations:       2,
+		PollInterval:        10 * time.Millisecond,
+		RebroadcastInterval: 20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.TxHash != tx.Hash() {
+		t.Fatalf("receipt of %s, want %s", receipt.TxHash, tx.Hash())
+	}
+	main.mu.Lock()
+	backup.mu.Lock()
+	defer main.mu.Unlock()
+	defer backup.mu.Unlock()
+	if main.sent < 2 || backup.sent != 1 {
+		t.Fatalf("sent %d times to main and %d to the backup, want a broadcast to both", main.sent, backup.sent)
+	}
+}
+
+func TestSendTransactionAndWaitConfirmations(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := common.Address{1}
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.LegacyTx{To: &to, Gas: 21000, GasPrice: big.NewInt(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := testConfig(
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 5}, &testMempool{}),
+		newTestNodeWith(t, &testEthService{chainID: 1, head: 5}, &testMempool{}),
+	)
+	c, err := New("test", "eth", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// included in block 3, the head is 5
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := c.SendTransactionAndWait(ctx, tx, &WaitOpts{Confirmations: 3, PollInterval: 10 * time.Millisecond}); err != context.DeadlineExceeded {
+		t.Fatalf("SendTransactionAndWait = %v, want a deadline exceeded", err)
+	}
+}